@@ -0,0 +1,339 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package cmdutils provides flags and argument parsing shared by several
+// subcommands.
+package cmdutils
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/score"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// StringArray is a flag type that collects all values of a flag that can be
+// provided multiple times, such as -hyp.
+type StringArray []string
+
+func (i *StringArray) String() string {
+	return strings.Join(*i, " ")
+}
+
+func (i *StringArray) Set(value string) error {
+	*i = append(*i, value)
+	return nil
+}
+
+// CommaList is a flag type that collects comma separated values, from one or
+// more occurrences of the flag, such as -reports.
+type CommaList []string
+
+func (l *CommaList) String() string {
+	if l == nil {
+		return ""
+	}
+
+	return strings.Join(*l, ",")
+}
+
+func (l *CommaList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// HypUsage is the usage string for the -hyp flag.
+const HypUsage = `(Required) Path hypothesis file to score. It can either simply be the filepath
+to the hypothesis file, or it can be in the form <name>,<filepath> where
+<name> is identifies the system that generated the hypothesis. The <name> will
+be used in the generated reports. If no name is provided, the name will be set
+automatically. This argument may be provided multiple times to point to score
+multiple hypotheses at once. The filepath may also point to a directory with one
+transcript file per utterance; see --id-pattern. Files compressed with gzip, bzip2 or
+xz are decompressed while being read. A filepath of "-" reads from the standard input.
+`
+
+// ParseHypArgs parses the values of the -hyp flag into hypotheses. Each value
+// is either a file path, or in the form <name>,<filepath>. Names must be valid
+// and unique once sanitized; see score.ValidateSystemNames.
+func ParseHypArgs(hypArgs StringArray) ([]sctk.Hypothesis, error) {
+	var hypPath, hypName string
+	i := 0
+
+	hypFiles := make([]sctk.Hypothesis, 0, len(hypArgs))
+
+	for _, val := range hypArgs {
+
+		switch parts := strings.Split(val, ","); len(parts) {
+		case 0:
+			return nil, fmt.Errorf("hypothesis file not specified after -hyp flag")
+		case 1:
+			i++
+			hypName, hypPath = fmt.Sprintf("hyp%d", i), parts[0]
+		case 2:
+			hypName, hypPath = parts[0], parts[1]
+		default:
+			return nil, fmt.Errorf(
+				"expected at most 2 comma delimited fields in -hyp flag value, got %d", len(parts),
+			)
+		}
+
+		hypFiles = append(
+			hypFiles,
+			sctk.Hypothesis{SystemName: hypName, FilePath: hypPath},
+		)
+	}
+
+	// The files of each system are named after it in the output directory, so
+	// names must not clash with each other or the reference.
+	names := make([]string, 0, len(hypFiles))
+	for _, hyp := range hypFiles {
+		names = append(names, hyp.SystemName)
+	}
+
+	if err := score.ValidateSystemNames(names); err != nil {
+		return nil, fmt.Errorf("invalid -hyp flag value: %w", err)
+	}
+
+	return hypFiles, nil
+}
+
+// CheckInputs checks that the given reference and hypothesis files exist. The
+// reference file is not checked if empty. At most one of the files may be "-",
+// which refers to the standard input.
+func CheckInputs(refFile string, hypFiles []sctk.Hypothesis) error {
+	numStdin := 0
+
+	checkExists := func(filePath, kind string) error {
+		if filePath == fileutils.Stdin {
+			numStdin++
+			return nil
+		}
+
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			return fmt.Errorf("specified %s file does not exist: %q", kind, filePath)
+		}
+
+		return nil
+	}
+
+	if refFile != "" {
+		if err := checkExists(refFile, "reference"); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range hypFiles {
+		if err := checkExists(f.FilePath, "hypothesis"); err != nil {
+			return err
+		}
+	}
+
+	if numStdin > 1 {
+		return fmt.Errorf("only one of the input files can be read from the standard input")
+	}
+
+	return nil
+}
+
+// FileFormatFlags holds the values of the flags describing the format of
+// reference and hypothesis files.
+type FileFormatFlags struct {
+	format    score.FileFormat
+	delimiter string
+}
+
+// RegisterFileFormatFlags registers flags describing the format of reference
+// and hypothesis files on the given flag set.
+func RegisterFileFormatFlags(fs *flag.FlagSet) *FileFormatFlags {
+	f := &FileFormatFlags{}
+
+	fs.StringVar(&f.delimiter, "delimiter", ",",
+		`The delimiter used in reference and hypotheses files. By default, the program expects
+comma delimited files (.csv) The program needs at least two columns per row, containing
+<utteranceID> and <transcript>. By default, the first and second columns are assumed
+to contain <utteranceID> and <transcript> respectively. This can be changed by using
+--id-col and --trn-col arguments.
+`)
+
+	fs.IntVar(&f.format.ColID, "col-id", 0,
+		"The column index (zero based, positive only) containing <utteranceID>.\n")
+
+	fs.IntVar(&f.format.ColTrn, "col-trn", 1,
+		"The column index (zero based, positive only) containing <transcript>.\n")
+
+	fs.BoolVar(&f.format.IgnoreFirstRow, "ignore-first", false,
+		"If true, will ignore the first row in the provided files, assuming it is the header row.\n")
+
+	fs.StringVar(&f.format.RefFormat, "ref-format", score.FormatAuto,
+		`Format of the reference file; one of auto, delimited or stm. Segment time marked (stm)
+references contain speaker, channel and time boundaries, and can only be scored against
+ctm hypotheses. If auto, files ending with .stm are read as stm, all others as delimited.
+`)
+
+	fs.StringVar(&f.format.HypFormat, "hyp-format", score.FormatAuto,
+		`Format of the hypothesis files; one of auto, delimited, ctm, srt or vtt. Time marked (ctm)
+hypotheses contain one word per line with its start time, duration and optionally
+confidence score. When scored against delimited references, the words of each file are
+joined into one utterance with the file name as utterance ID. SubRip (srt) and WebVTT
+(vtt) subtitles are scored as described for --subtitle-mode. If auto, the format is
+detected from the .ctm, .srt and .vtt extensions; all other files are read as delimited.
+`)
+
+	fs.BoolVar(&f.format.Strict, "strict", false,
+		`If true, problems found when validating the input files, such as duplicate utterance
+IDs, empty transcripts, encoding problems or reference utterances missing from the
+hypotheses, are treated as errors instead of warnings.
+`)
+
+	fs.StringVar(&f.format.IDPattern, "id-pattern", "",
+		`Regular expression used to derive utterance IDs from file paths, when the reference or
+hypotheses are directories with one transcript file per utterance. It is matched against
+the path of each file relative to the directory; the groups it captures are joined with
+dashes to form the ID, or the whole match is used if it has no groups. Files that do not
+match are skipped. By default, the ID is the relative path without its extension, e.g.
+spk1/utt1.txt becomes spk1-utt1, so files are matched by relative path.
+`)
+
+	fs.StringVar(&f.format.SubtitleMode, "subtitle-mode", "",
+		`How subtitle hypotheses are scored; one of concat or overlap. In concat mode, all cues
+of a subtitle file are joined into one utterance, with the file name (without extension)
+as the utterance ID. In overlap mode, the words of each cue are mapped onto the segments
+of a stm reference by time overlap; the file name (without extension) must match the
+file column of the reference. If not set, overlap is used for stm references and concat
+otherwise. Markup such as formatting tags, speaker labels and sound effects in brackets
+is always stripped from subtitles.
+`)
+
+	return f
+}
+
+// RegisterNBestFlags registers flags for scoring N-best lists on the given flag
+// set.
+func (f *FileFormatFlags) RegisterNBestFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&f.format.NBest, "nbest", false,
+		`If true, hypotheses are N-best lists with several transcripts per utterance, best first.
+The 1-best transcripts are scored under the name of the hypothesis, and the oracle
+transcripts, those closest to the reference, as <name>_oracle. The 1-best and oracle error
+rates and the ranks of the oracle transcripts are summarized in <name>.nbest.json. In
+delimited files, utterance IDs repeat once per transcript, ranked by --col-rank or by
+their order. Files ending with .jsonl hold one utterance per line, such as
+{"id": "utt1", "nbest": ["best transcript", "second best"]}.
+`)
+
+	fs.IntVar(&f.format.ColRank, "col-rank", -1,
+		`The column index (zero based) containing the rank of each transcript in N-best lists,
+lower being better. If < 0, transcripts are ranked by the order they appear in.
+`)
+}
+
+// RegisterSessionFlags registers flags for scoring sessions with several
+// speakers on the given flag set.
+func (f *FileFormatFlags) RegisterSessionFlags(fs *flag.FlagSet) {
+	fs.IntVar(&f.format.ColSpeaker, "col-speaker", -1,
+		`The column index (zero based) containing the speaker of each row of delimited files
+when scoring sessions, in which case --col-id contains the session. Required to score
+delimited files as sessions; stm and ctm files hold speakers in their speaker and channel
+columns respectively.
+`)
+}
+
+// FileFormat returns the file format configured by the parsed flags, after
+// validating it.
+func (f *FileFormatFlags) FileFormat() (score.FileFormat, error) {
+	if len([]rune(f.delimiter)) != 1 {
+		return f.format, fmt.Errorf("demiliter  must be a single rune")
+	}
+
+	f.format.Delimiter = []rune(f.delimiter)[0]
+
+	if err := f.format.Validate(); err != nil {
+		return f.format, err
+	}
+
+	return f.format, nil
+}
+
+// RegisterReportFlags registers flags choosing the reports generated by sclite
+// and the formats alignments are written in on the given flag set.
+func RegisterReportFlags(fs *flag.FlagSet, cfg *sctk.ScliteCfg) {
+	fs.Var((*CommaList)(&cfg.Reports), "reports",
+		`Comma separated sclite reports to generate: sum, rsum, pralign, all, sgml, stdout, lur,
+snt, spk, dtl, prf, wws, nl.sgml or none. By default, sum,rsum,dtl,sgml. The sgml report
+is always generated when alignments are written; see --alignment-formats.
+`)
+
+	fs.Var((*CommaList)(&cfg.AlignmentFormats), "alignment-formats",
+		`Comma separated formats in which alignments are written, as <hyp>.pra.<format> files:
+md, html, csv, txt or json; or none. By default, md,html,csv,json. The json alignments are
+needed by the diff subcommand and quality gates.
+`)
+
+	fs.Var((*CommaList)(&cfg.UtteranceFormats), "utterance-formats",
+		`Comma separated formats in which the metrics of each utterance of all hypotheses are
+written, one row per utterance per hypothesis, as utterances.<format> files: csv or tsv;
+or none. By default, csv.
+`)
+}
+
+// RegisterNormalizeFlags registers flags configuring how transcripts are
+// normalized on the given flag set.
+func RegisterNormalizeFlags(fs *flag.FlagSet, cfg *score.NormalizeConfig) {
+	fs.BoolVar(&cfg.CaseSensitive, "case-sensitive", false,
+		"If true, scoring will be case sensitive.\n")
+
+	fs.BoolVar(&cfg.NormalizeUnicode, "normalize-unicode", false,
+		"If true, unicode normalization wil be applied reference and hypothesis text before scoring.\n")
+
+	fs.BoolVar(&cfg.StripSubtitleMarkup, "strip-subtitle-markup", false,
+		`If true, subtitle markup such as formatting tags, speaker labels and sound effects in
+brackets will be stripped from reference and hypothesis text before scoring. It is always
+stripped from subtitle hypotheses.
+`)
+
+	fs.BoolVar(&cfg.StripPunctuation, "strip-punctuation", false,
+		`If true, punctuation at the start and end of words, and words made of punctuation only,
+will be removed from reference and hypothesis text before scoring. Punctuation within
+words, such as the apostrophe in "don't", is kept.
+`)
+
+	fs.StringVar(&cfg.Language, "language", "",
+		`Language profile selecting language specific normalization rules; one of ar (Arabic) or
+ur (Urdu). Both unify alef variants carrying hamza with the bare alef and remove tatweel.
+Arabic also unifies alef madda with the bare alef and removes tanween. Urdu keeps alef
+madda, and replaces the Arabic forms of yeh, kaf and heh with their Urdu forms. If not
+set, no language specific rules are applied.
+`)
+
+	fs.BoolVar(&cfg.TaMarbutaToHa, "ta-marbuta-to-ha", false,
+		"If true, ta marbuta is replaced with heh (heh goal in Urdu). Needs --language.\n")
+
+	fs.BoolVar(&cfg.AlefMaqsuraToYa, "alef-maqsura-to-ya", false,
+		"If true, alef maqsura is replaced with yeh. Always done in Urdu. Needs --language.\n")
+
+	fs.BoolVar(&cfg.StripDiacritics, "strip-diacritics", false,
+		`If true, Arabic script diacritics (harakat), such as short vowels, shadda and sukun, are
+removed. Needs --language.
+`)
+}
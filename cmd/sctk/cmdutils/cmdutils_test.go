@@ -0,0 +1,83 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package cmdutils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestParseHypArgs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		args    StringArray
+		want    []sctk.Hypothesis
+		wantErr bool
+	}{
+		{
+			name: "named",
+			args: StringArray{"a.csv", "sys b,b.csv"},
+			want: []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "a.csv"}, {SystemName: "sys b", FilePath: "b.csv"}},
+		},
+		{
+			name:    "duplicateSanitized",
+			args:    StringArray{"Sys B,a.csv", "sys  b,b.csv"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicateDefault",
+			args:    StringArray{"a.csv", "hyp1,b.csv"},
+			wantErr: true,
+		},
+		{
+			name:    "reference",
+			args:    StringArray{"Ref,a.csv"},
+			wantErr: true,
+		},
+		{
+			name:    "parentDir",
+			args:    StringArray{"..,a.csv"},
+			wantErr: true,
+		},
+		{
+			name:    "pathSeparator",
+			args:    StringArray{"out/sys,a.csv"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got, err := ParseHypArgs(tc.args)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected hypotheses, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package cmdutils
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/peterbourgon/ff/v3"
+	"github.com/peterbourgon/ff/v3/fftoml"
+	"github.com/peterbourgon/ff/v3/ffyaml"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// EnvVarPrefix is the prefix of environment variables setting flags of
+// commands that support them, e.g. SCTK_NORMALIZE_UNICODE for
+// --normalize-unicode.
+const EnvVarPrefix = "SCTK"
+
+// Names of the flags selecting a config file and preset, which can not be set
+// by presets.
+const (
+	flagConfig = "config"
+	flagPreset = "preset"
+)
+
+// BuiltinPresets are the presets that can be selected with --preset without a
+// config file. Each maps flag names to values.
+var BuiltinPresets = map[string]map[string]string{
+	// Common Voice Bengali: tab separated files with a header row, the clip path
+	// as utterance ID and the sentence as transcript. Bengali text mixes
+	// composed and decomposed forms of some vowel signs, so unicode
+	// normalization is enabled.
+	"bn-commonvoice": {
+		"delimiter":         "\t",
+		"col-id":            "1",
+		"col-trn":           "2",
+		"ignore-first":      "true",
+		"case-sensitive":    "false",
+		"normalize-unicode": "true",
+		"cer":               "false",
+	},
+	// Common Voice Arabic: laid out like Common Voice Bengali. Sentences are
+	// only partially vocalized, so diacritics are stripped along with the
+	// Arabic normalization rules.
+	"ar-commonvoice": {
+		"delimiter":         "\t",
+		"col-id":            "1",
+		"col-trn":           "2",
+		"ignore-first":      "true",
+		"case-sensitive":    "false",
+		"normalize-unicode": "true",
+		"language":          "ar",
+		"strip-diacritics":  "true",
+		"cer":               "false",
+	},
+	// Common Voice Urdu: laid out like Common Voice Bengali, with the Urdu
+	// normalization rules.
+	"ur-commonvoice": {
+		"delimiter":         "\t",
+		"col-id":            "1",
+		"col-trn":           "2",
+		"ignore-first":      "true",
+		"case-sensitive":    "false",
+		"normalize-unicode": "true",
+		"language":          "ur",
+		"strip-diacritics":  "true",
+		"cer":               "false",
+	},
+	// LibriSpeech style English: comma separated ID and transcript, scored
+	// without regard to case since references are all upper case.
+	"en-librispeech": {
+		"delimiter":         ",",
+		"col-id":            "0",
+		"col-trn":           "1",
+		"ignore-first":      "false",
+		"case-sensitive":    "false",
+		"normalize-unicode": "true",
+		"cer":               "false",
+	},
+}
+
+// ConfigFlags are the flags selecting a config file and preset.
+type ConfigFlags struct {
+	configFile string
+	preset     string
+}
+
+// RegisterConfigFlags registers the --config and --preset flags on the given
+// flag set.
+func RegisterConfigFlags(fs *flag.FlagSet) *ConfigFlags {
+	c := &ConfigFlags{}
+
+	fs.StringVar(&c.configFile, flagConfig, "",
+		`Path to a YAML, JSON or TOML config file, chosen by extension, setting flags of this
+command by name, e.g. "delimiter" or "normalize-unicode". Flags given on the command line
+or in SCTK_* environment variables take precedence. A preset can be selected with the
+"preset" key.
+`)
+
+	fs.StringVar(&c.preset, flagPreset, "",
+		fmt.Sprintf(`Name of a built in preset setting the file format, normalization and scoring flags,
+one of %s, or path to a YAML, JSON or TOML preset file setting flags by name.
+Flags set on the command line, in environment variables or in the config file take
+precedence.
+`, strings.Join(textutils.SortedKeys(BuiltinPresets), ", ")))
+
+	return c
+}
+
+// Options returns the options with which ff parses the flags of the command,
+// reading flags not set on the command line from environment variables, then
+// from the config file given with --config.
+func (c *ConfigFlags) Options() []ff.Option {
+	return []ff.Option{
+		ff.WithEnvVarPrefix(EnvVarPrefix),
+		ff.WithConfigFileFlag(flagConfig),
+		ff.WithConfigFileParser(func(r io.Reader, set func(name, value string) error) error {
+			parse, err := configFileParser(c.configFile)
+			if err != nil {
+				return err
+			}
+
+			return parse(r, set)
+		}),
+	}
+}
+
+// Apply sets the flags of the selected preset that were not already set on the
+// command line, from environment variables or from the config file. It must be
+// called after the flags have been parsed with Options.
+func (c *ConfigFlags) Apply(fs *flag.FlagSet) error {
+	if c.preset == "" {
+		return nil
+	}
+
+	preset, err := lookupPreset(c.preset)
+	if err != nil {
+		return err
+	}
+
+	isSet := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { isSet[f.Name] = true })
+
+	for _, key := range textutils.SortedKeys(preset) {
+		if key == flagConfig || key == flagPreset {
+			return fmt.Errorf("%q can not be set in presets", key)
+		}
+
+		if fs.Lookup(key) == nil {
+			return fmt.Errorf("unknown flag %q set in preset %q", key, c.preset)
+		}
+
+		if isSet[key] {
+			continue
+		}
+
+		for _, v := range preset[key] {
+			if err := fs.Set(key, v); err != nil {
+				return fmt.Errorf("invalid value %q for %q in preset %q: %w", v, key, c.preset, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// lookupPreset returns the flag values of the built in preset with the given
+// name, or of the preset file at the given path. Flags that may be provided
+// multiple times, such as --hyp, can be given lists in preset files.
+func lookupPreset(name string) (map[string][]string, error) {
+	if builtin, ok := BuiltinPresets[name]; ok {
+		preset := make(map[string][]string, len(builtin))
+		for k, v := range builtin {
+			preset[k] = []string{v}
+		}
+
+		return preset, nil
+	}
+
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf(
+			"unknown preset %q, expected a preset file or one of %s",
+			name, strings.Join(textutils.SortedKeys(BuiltinPresets), "|"),
+		)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open preset file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	parse, err := configFileParser(name)
+	if err != nil {
+		return nil, err
+	}
+
+	preset := make(map[string][]string)
+	if err := parse(f, func(key, value string) error {
+		preset[key] = append(preset[key], value)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read preset file: %w", err)
+	}
+
+	return preset, nil
+}
+
+// configFileParser returns the ff parser of YAML, JSON or TOML config files,
+// chosen by the extension of the given file.
+func configFileParser(filePath string) (ff.ConfigFileParser, error) {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); ext {
+	case ".yaml", ".yml":
+		return ffyaml.Parser, nil
+	case ".json":
+		return ff.JSONParser, nil
+	case ".toml":
+		return fftoml.Parser, nil
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, supported .yaml|.yml|.json|.toml", ext)
+	}
+}
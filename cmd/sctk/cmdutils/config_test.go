@@ -0,0 +1,184 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package cmdutils
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/peterbourgon/ff/v3"
+)
+
+// testFlags are the flags set by the configs and presets of the tests.
+type testFlags struct {
+	delimiter     string
+	colID         int
+	caseSensitive bool
+	hyps          StringArray
+}
+
+//nolint: funlen // table tests can be long.
+func TestConfigFlags(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		filePath := filepath.Join(dir, name)
+		if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+
+		return filePath
+	}
+
+	yamlConfig := writeFile("config.yaml", "delimiter: \";\"\ncol-id: 3\nhyp:\n  - a.csv\n  - b.csv\n")
+	jsonConfig := writeFile("config.json", `{"col-id": 4, "preset": "en-librispeech"}`)
+	tomlConfig := writeFile("config.toml", "col-id = 5\ncase-sensitive = true\n")
+	unknownConfig := writeFile("unknown.yaml", "delimiter: \";\"\nmax-wr: 10\n")
+	badExtConfig := writeFile("config.ini", "delimiter=;\n")
+	presetFile := writeFile("preset.yaml", "delimiter: \"|\"\ncol-id: 7\ncase-sensitive: true\n")
+	unknownPreset := writeFile("unknown-preset.yaml", "col-idx: 1\n")
+	recursivePreset := writeFile("recursive-preset.yaml", "preset: bn-commonvoice\n")
+
+	testCases := []struct {
+		name    string
+		args    []string
+		want    testFlags
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: testFlags{delimiter: ",", colID: 0},
+		},
+		{
+			name: "yamlConfig",
+			args: []string{"--config", yamlConfig},
+			want: testFlags{delimiter: ";", colID: 3, hyps: StringArray{"a.csv", "b.csv"}},
+		},
+		{
+			// Command line flags take precedence over the config file, which
+			// takes precedence over defaults.
+			name: "flagOverConfig",
+			args: []string{"--config", yamlConfig, "--col-id", "9", "--hyp", "c.csv"},
+			want: testFlags{delimiter: ";", colID: 9, hyps: StringArray{"c.csv"}},
+		},
+		{
+			// The preset selected in the config file fills in the flags not
+			// set in it.
+			name: "jsonConfigWithPreset",
+			args: []string{"--config", jsonConfig},
+			want: testFlags{delimiter: ",", colID: 4, caseSensitive: false},
+		},
+		{
+			name: "tomlConfig",
+			args: []string{"--config", tomlConfig},
+			want: testFlags{delimiter: ",", colID: 5, caseSensitive: true},
+		},
+		{
+			name: "builtinPreset",
+			args: []string{"--preset", "bn-commonvoice"},
+			want: testFlags{delimiter: "\t", colID: 1},
+		},
+		{
+			// Command line flags and the config file take precedence over the
+			// preset.
+			name: "configOverPreset",
+			args: []string{"--config", tomlConfig, "--preset", "bn-commonvoice", "--delimiter", ";"},
+			want: testFlags{delimiter: ";", colID: 5, caseSensitive: true},
+		},
+		{
+			name: "presetFile",
+			args: []string{"--preset", presetFile, "--col-id", "2"},
+			want: testFlags{delimiter: "|", colID: 2, caseSensitive: true},
+		},
+		{
+			name:    "unknownConfigKey",
+			args:    []string{"--config", unknownConfig},
+			wantErr: true,
+		},
+		{
+			name:    "unsupportedConfigExtension",
+			args:    []string{"--config", badExtConfig},
+			wantErr: true,
+		},
+		{
+			name:    "missingConfig",
+			args:    []string{"--config", filepath.Join(dir, "missing.yaml")},
+			wantErr: true,
+		},
+		{
+			name:    "unknownPreset",
+			args:    []string{"--preset", "xx-unknown"},
+			wantErr: true,
+		},
+		{
+			name:    "unknownPresetKey",
+			args:    []string{"--preset", unknownPreset},
+			wantErr: true,
+		},
+		{
+			name:    "presetSelectingPreset",
+			args:    []string{"--preset", recursivePreset},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			var got testFlags
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.StringVar(&got.delimiter, "delimiter", ",", "")
+			fs.IntVar(&got.colID, "col-id", 0, "")
+			fs.IntVar(new(int), "col-trn", 1, "")
+			fs.BoolVar(new(bool), "ignore-first", false, "")
+			fs.BoolVar(&got.caseSensitive, "case-sensitive", false, "")
+			fs.BoolVar(new(bool), "normalize-unicode", false, "")
+			fs.BoolVar(new(bool), "cer", false, "")
+			fs.Var(&got.hyps, "hyp", "")
+
+			configFlags := RegisterConfigFlags(fs)
+
+			err := ff.Parse(fs, tc.args, configFlags.Options()...)
+			if err == nil {
+				err = configFlags.Apply(fs)
+			}
+
+			if tc.wantErr {
+				if err == nil {
+					subT.Fatalf("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(testFlags{})); diff != "" {
+				subT.Errorf("unexpected flags, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
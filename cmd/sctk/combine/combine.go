@@ -0,0 +1,187 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package combine implements a subcommand to combine the hypotheses of several
+// ASR systems into a single hypothesis using ROVER.
+package combine
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/cmd/sctk/cmdutils"
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/score"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Config for the combine subcommand.
+type Config struct {
+	outDir     string
+	refFile    string
+	name       string
+	hypFiles   []sctk.Hypothesis
+	fileFormat score.FileFormat
+	normCfg    score.NormalizeConfig
+	roverCfg   sctk.RoverCfg
+	scliteCfg  sctk.ScliteCfg
+}
+
+// Cmd creates and returns a pointer to the ffcli.Command for the combine
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := Config{}
+	fs := flag.NewFlagSet("sctk combine", flag.ExitOnError)
+
+	// Will parse these into config field with the correct type later.
+	var hypArgs cmdutils.StringArray
+
+	fs.StringVar(&cfg.outDir, "out", "",
+		"(Required) Path to output directory where the combined hypothesis will be written.\n")
+
+	fs.Var(&hypArgs, "hyp",
+		`(Required) Path hypothesis file to combine. It can either simply be the filepath
+to the hypothesis file, or it can be in the form <name>,<filepath> where
+<name> is identifies the system that generated the hypothesis. This argument must
+be provided at least twice.
+`)
+
+	fs.StringVar(&cfg.name, "name", "rover",
+		"Name of the combined system. The combined hypothesis is written to <out>/<name>.<ext>.\n")
+
+	fs.StringVar(&cfg.refFile, "ref", "",
+		`Path to file containing reference text. If provided, the combined hypothesis is
+scored right away, along with the hypotheses that were combined.
+`)
+
+	fileFormatFlags := cmdutils.RegisterFileFormatFlags(fs)
+
+	fs.StringVar(&cfg.roverCfg.Method, "method", sctk.RoverFrequency,
+		`The voting scheme used to combine hypotheses. One of:
+  frequency - the most frequent word at each position wins.
+  avgconf   - word frequency weighed with the average confidence score.
+  maxconf   - word frequency weighed with the maximum confidence score.
+`)
+
+	fs.Float64Var(&cfg.roverCfg.Alpha, "alpha", 0.5,
+		`Trade-off between word frequency and confidence scores for the avgconf and maxconf
+methods. An alpha of 1 only uses word frequency.
+`)
+
+	fs.Float64Var(&cfg.roverCfg.NullConf, "null-conf", 0.5,
+		"Confidence score assigned to null words for the avgconf and maxconf methods.\n")
+
+	fs.BoolVar(&cfg.scliteCfg.CER, "cer", false,
+		"If true, will evaluate character error rate instead of word error rate when scoring.\n")
+
+	cmdutils.RegisterReportFlags(fs, &cfg.scliteCfg)
+	cmdutils.RegisterNormalizeFlags(fs, &cfg.normCfg)
+
+	shortUsage := `
+sctk combine \
+  --method=frequency --name=rover \
+  --out=./combined --hyp=sys1,output1.csv --hyp=sys2,output2.csv --hyp=sys3,output3.csv \
+  --ref=truth.csv
+`
+
+	return &ffcli.Command{
+		Name:       "combine",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Combine hypothesis transcripts from several systems using ROVER.",
+		Exec: func(ctx context.Context, args []string) (err error) {
+			if cfg.hypFiles, err = cmdutils.ParseHypArgs(hypArgs); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			if cfg.fileFormat, err = fileFormatFlags.FileFormat(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			if err := cfg.checkArgs(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			return cfg.runCombine(ctx)
+		},
+	}
+}
+
+func (cfg *Config) checkArgs() error {
+	if err := cfg.normCfg.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.outDir == "" {
+		return fmt.Errorf("output directory must be specified")
+	}
+
+	if len(cfg.hypFiles) < 2 {
+		return fmt.Errorf("at least two hypothesis files must be specified")
+	}
+
+	if err := cfg.roverCfg.Validate(); err != nil {
+		return err
+	}
+
+	cfg.scliteCfg.LineWidth = 1000
+	cfg.scliteCfg.Encoding = "utf-8"
+
+	if err := cfg.scliteCfg.Validate(); err != nil {
+		return err
+	}
+
+	// Hypotheses are read again when scoring, which is not possible for the
+	// standard input.
+	if cfg.refFile != "" {
+		for _, f := range cfg.hypFiles {
+			if f.FilePath == fileutils.Stdin {
+				return fmt.Errorf("hypotheses can not be read from the standard input when --ref is provided")
+			}
+		}
+	}
+
+	return cmdutils.CheckInputs(cfg.refFile, cfg.hypFiles)
+}
+
+// runCombine executes rover on the specified hypothesis files, and optionally
+// scores the combined hypothesis against the reference.
+func (cfg *Config) runCombine(ctx context.Context) error {
+	combined, err := score.Combine(
+		ctx, cfg.fileFormat, cfg.normCfg, cfg.roverCfg,
+		cfg.outDir, cfg.name, cfg.hypFiles,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"path": combined.FilePath}).Info("wrote combined hypothesis")
+
+	if cfg.refFile == "" {
+		return nil
+	}
+
+	return score.Score(
+		ctx, cfg.fileFormat, cfg.normCfg, cfg.scliteCfg,
+		cfg.outDir, cfg.refFile, append(cfg.hypFiles, combined),
+	)
+}
@@ -0,0 +1,144 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package diff implements the diff subcommand, which compares the alignments of
+// two scoring runs.
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+)
+
+const (
+	// Names of the files written to the output directory.
+	markdownFile = "diff.md"
+	jsonFile     = "diff.json"
+
+	filePerm = 0777
+)
+
+// Config for the diff subcommand.
+type Config struct {
+	runA, runB string
+	outDir     string
+	maxRows    int
+	jsonOutput bool
+}
+
+// Cmd creates and returns a pointer to the ffcli.Command for the diff
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := Config{}
+	fs := flag.NewFlagSet("sctk diff", flag.ExitOnError)
+
+	fs.StringVar(&cfg.outDir, "out", "",
+		`Path to output directory where the comparison will be written, as diff.md and
+diff.json. If empty, nothing is written to disk.
+`)
+
+	fs.IntVar(&cfg.maxRows, "max-rows", 20, //nolint: gomnd // default value.
+		`Maximum number of utterances and confusion pairs listed in each table of the
+Markdown summary. If <= 0, all are listed. The JSON output always lists all.
+`)
+
+	fs.BoolVar(&cfg.jsonOutput, "json", false,
+		"If true, the comparison is printed in the JSON format instead of as Markdown.\n")
+
+	shortUsage := `
+sctk diff [--out=./wer-diff] ./wer-baseline ./wer-new
+`
+
+	return &ffcli.Command{
+		Name:       "diff",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Compare the alignments of two scoring runs.",
+		LongHelp: `Compare the alignments of two scoring runs, given their output directories, A
+being the baseline. Reports changes in error rate overall and per utterance,
+utterances that newly broke or got fixed and changes in confusion pairs. The
+significance of the change is tested with a sign test over utterances.`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 2 { //nolint: gomnd // two runs.
+				fs.Usage()
+				return fmt.Errorf("expected paths to two run directories, got %d arguments", len(args))
+			}
+
+			cfg.runA, cfg.runB = args[0], args[1]
+
+			return cfg.runDiff()
+		},
+	}
+}
+
+// runDiff compares the two runs and prints the comparison, writing it to the
+// output directory if set.
+func (cfg *Config) runDiff() error {
+	d, err := compare.CompareRuns(cfg.runA, cfg.runB)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode comparison: %w", err)
+	}
+
+	if cfg.outDir != "" {
+		if err := cfg.write(&d, jsonData); err != nil {
+			return err
+		}
+	}
+
+	if cfg.jsonOutput {
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	return d.WriteMarkdown(os.Stdout, cfg.maxRows)
+}
+
+// write writes the comparison to the output directory as Markdown and JSON.
+func (cfg *Config) write(d *compare.RunDiff, jsonData []byte) error {
+	if err := os.MkdirAll(cfg.outDir, filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(cfg.outDir, jsonFile), jsonData, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+
+	f, err := os.Create(filepath.Join(cfg.outDir, markdownFile))
+	if err != nil {
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	if err := d.WriteMarkdown(f, cfg.maxRows); err != nil {
+		return fmt.Errorf("failed to write comparison: %w", err)
+	}
+
+	return nil
+}
@@ -17,18 +17,34 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/peterbourgon/ff/v3/ffcli"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/shahruk10/go-sctk/cmd/sctk/combine"
+	"github.com/shahruk10/go-sctk/cmd/sctk/diff"
+	"github.com/shahruk10/go-sctk/cmd/sctk/report"
+	"github.com/shahruk10/go-sctk/cmd/sctk/rerun"
 	"github.com/shahruk10/go-sctk/cmd/sctk/score"
+	"github.com/shahruk10/go-sctk/cmd/sctk/serve"
+	"github.com/shahruk10/go-sctk/cmd/sctk/validate"
+	"github.com/shahruk10/go-sctk/internal/gate"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
 )
 
 func main() {
 	var (
 		rootFlagSet = flag.NewFlagSet("sctk", flag.ExitOnError)
+		binDir      = rootFlagSet.String("sctk-bin-dir", os.Getenv(embedded.EnvBinDir),
+			`Path to a directory containing system installed SCTK tools (sclite, sc_stats, ...)
+to use instead of the tools embedded in this binary. Can also be set with the
+SCTK_BIN_DIR environment variable.
+`)
 	)
 
 	// Setting logger format.
@@ -48,16 +64,42 @@ func main() {
 	// subcommands
 	root.Subcommands = []*ffcli.Command{
 		score.Cmd(),
+		combine.Cmd(),
+		validate.Cmd(),
+		rerun.Cmd(),
+		diff.Cmd(),
+		serve.Cmd(),
+		report.Cmd(),
 	}
 
 	if err := root.Parse(os.Args[1:]); err != nil {
+		// Errors in config files are only reported when parsing.
+		if !errors.Is(err, flag.ErrHelp) {
+			log.WithFields(log.Fields{"error": err}).Error("failed to parse arguments")
+		}
+
 		root.FlagSet.Usage()
 		os.Exit(1)
 	}
 
+	embedded.SetBinDir(*binDir)
+
+	// Cancelling running commands on interrupt, or when terminated by a service
+	// manager.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// running command
-	if err := root.Run(context.Background()); err != nil {
+	if err := root.Run(ctx); err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("failed to run command")
+
+		// Failing quality gates exit with their own code. Other errors, including
+		// those of SCTK tools that exited with their own codes, exit with 1.
+		var gateErr *gate.FailedError
+		if errors.As(err, &gateErr) {
+			os.Exit(gateErr.ExitCode())
+		}
+
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,168 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package report implements the report subcommand, which renders reports from
+// the alignments of earlier scoring runs.
+package report
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/shahruk10/go-sctk/cmd/sctk/cmdutils"
+	"github.com/shahruk10/go-sctk/internal/report"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Config for the report subcommand.
+type Config struct {
+	inputs    []string
+	outDir    string
+	reportCfg report.Config
+}
+
+// Cmd creates and returns a pointer to the ffcli.Command for the report
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := Config{}
+	fs := flag.NewFlagSet("sctk report", flag.ExitOnError)
+
+	fs.StringVar(&cfg.outDir, "out", "",
+		`Path to output directory where reports will be written, named after the inputs. If
+empty, the alignments are printed in the single format given by --alignment-formats.
+`)
+
+	fs.Var((*cmdutils.CommaList)(&cfg.reportCfg.Sclite.Reports), "reports",
+		`Comma separated sclite reports to generate from sgml inputs: sum, rsum, pralign, all,
+sgml, stdout, lur, snt, spk, dtl, prf, wws or nl.sgml. By default, none. Requires --out.
+`)
+
+	fs.Var((*cmdutils.CommaList)(&cfg.reportCfg.AlignmentFormats), "alignment-formats",
+		`Comma separated formats in which alignments are written, as <name>.pra.<format> files:
+md, html, csv, txt or json; or none. By default, md.
+`)
+
+	fs.IntVar(&cfg.reportCfg.Sclite.LineWidth, "line-width", 1000, //nolint: gomnd // default value.
+		"Line width of sclite reports.\n")
+
+	fs.StringVar(&cfg.reportCfg.Sclite.Encoding, "encoding", "utf-8",
+		"What text encoding to use for interpreting text in sclite reports.\n")
+
+	fs.BoolVar(&cfg.reportCfg.Filter.ErrorsOnly, "errors-only", false,
+		"If true, only sentences with at least one error are reported.\n")
+
+	fs.Var((*cmdutils.CommaList)(&cfg.reportCfg.Filter.Speakers), "speaker",
+		"Comma separated IDs of the speakers whose sentences are reported. By default, all.\n")
+
+	fs.IntVar(&cfg.reportCfg.Filter.Worst, "worst", 0,
+		`If > 0, only the given number of sentences with the most errors are reported, ties
+broken by error rate. Combined with the other filters, the worst are picked among the
+sentences that pass them.
+`)
+
+	shortUsage := `
+sctk report --out=./worst --worst=20 --reports=pralign ./wer-results
+sctk report --errors-only --speaker=spk1 ./wer-results/hyp1.trn.pra.json
+`
+
+	return &ffcli.Command{
+		Name:       "report",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Render reports from the alignments of earlier scoring runs.",
+		LongHelp: `Render reports from the alignments of earlier scoring runs, given .sgml or
+.pra.json files, or output directories of the score subcommand. Alignments can be
+rendered in any of the supported formats. sclite reports can be generated from
+sgml files. Reports can be restricted to sentences with errors, to some speakers
+or to the worst sentences.`,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) == 0 {
+				fs.Usage()
+				return fmt.Errorf("expected paths to .sgml or .pra.json files, or run directories")
+			}
+
+			cfg.inputs = args
+
+			if err := cfg.checkArgs(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			return cfg.runReport(ctx)
+		},
+	}
+}
+
+// checkArgs checks the flags, and sets the defaults of unset ones.
+func (cfg *Config) checkArgs() error {
+	c := &cfg.reportCfg
+
+	if len(c.AlignmentFormats) == 0 {
+		c.AlignmentFormats = []string{sctk.AlignmentFormatMarkdown}
+	}
+
+	// Validating the reports and formats as sclite would when scoring.
+	scliteCfg := c.Sclite
+	scliteCfg.AlignmentFormats = c.AlignmentFormats
+
+	if err := scliteCfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if c.AlignmentFormats[0] == sctk.AlignmentFormatNone {
+		c.AlignmentFormats = nil
+	}
+
+	if len(c.Sclite.Reports) == 1 && c.Sclite.Reports[0] == "none" {
+		c.Sclite.Reports = nil
+	}
+
+	if c.Filter.Worst < 0 {
+		return fmt.Errorf("--worst must be >= 0, got %d", c.Filter.Worst)
+	}
+
+	if cfg.outDir != "" {
+		return nil
+	}
+
+	if len(c.Sclite.Reports) != 0 {
+		return fmt.Errorf("--out is required to generate sclite reports")
+	}
+
+	if len(c.AlignmentFormats) != 1 {
+		return fmt.Errorf("--out is required to write more than one alignment format")
+	}
+
+	return nil
+}
+
+// runReport loads the inputs and renders the reports, or prints the alignments
+// if no output directory is given.
+func (cfg *Config) runReport(ctx context.Context) error {
+	inputs, err := report.LoadInputs(cfg.inputs)
+	if err != nil {
+		return err
+	}
+
+	if cfg.outDir == "" {
+		return report.Print(os.Stdout, cfg.reportCfg.AlignmentFormats[0], cfg.reportCfg.Filter, inputs)
+	}
+
+	return report.Render(ctx, cfg.reportCfg, inputs, cfg.outDir)
+}
@@ -0,0 +1,83 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package rerun implements the rerun subcommand, which reproduces a scoring run
+// from its run.json manifest.
+package rerun
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/shahruk10/go-sctk/internal/score"
+)
+
+// Config for the rerun subcommand.
+type Config struct {
+	outDir       string
+	manifestFile string
+}
+
+// Cmd creates and returns a pointer to the ffcli.Command for the rerun
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := Config{}
+	fs := flag.NewFlagSet("sctk rerun", flag.ExitOnError)
+
+	fs.StringVar(&cfg.outDir, "out", "",
+		`(Required) Path to output directory where scores and reports will be written. It must
+differ from the output directory of the original run.
+`)
+
+	shortUsage := `
+sctk rerun --out=./wer-rerun ./wer/run.json
+`
+
+	return &ffcli.Command{
+		Name:       "rerun",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Reproduce a scoring run from the run.json manifest in its output directory.",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				fs.Usage()
+				return fmt.Errorf("expected path to a single run manifest, got %d arguments", len(args))
+			}
+
+			if cfg.outDir == "" {
+				fs.Usage()
+				return fmt.Errorf("output directory must be specified")
+			}
+
+			cfg.manifestFile = args[0]
+
+			return cfg.runRerun(ctx)
+		},
+	}
+}
+
+// runRerun reads the run manifest and scores its inputs again with the same
+// configuration.
+func (cfg *Config) runRerun(ctx context.Context) error {
+	manifest, err := score.ReadManifest(cfg.manifestFile)
+	if err != nil {
+		return err
+	}
+
+	return score.Rerun(ctx, manifest, cfg.outDir)
+}
@@ -23,22 +23,28 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/peterbourgon/ff/v3/ffcli"
 
+	"github.com/shahruk10/go-sctk/cmd/sctk/cmdutils"
+	"github.com/shahruk10/go-sctk/internal/gate"
 	"github.com/shahruk10/go-sctk/internal/score"
 	"github.com/shahruk10/go-sctk/internal/sctk"
 )
 
 // Config for the score subcommand.
 type Config struct {
+	mode       string
 	outDir     string
 	refFile    string
 	hypFiles   []sctk.Hypothesis
 	fileFormat score.FileFormat
 	normCfg    score.NormalizeConfig
 	scliteCfg  sctk.ScliteCfg
+	gateCfg    gate.Config
+	junitFile  string
+	termList   string
+	weightFile string
 }
 
 // Cmd creates and returns a pointer to the ffcli.Command for the score
@@ -48,42 +54,44 @@ func Cmd() *ffcli.Command {
 	fs := flag.NewFlagSet("sctk score", flag.ExitOnError)
 
 	// Will parse these into config field with the correct type later.
-	var (
-		hypArgs   stringArray
-		delimiter string
-	)
+	var hypArgs, sliceArgs cmdutils.StringArray
+	var maxWER, maxCER float64
 
 	fs.StringVar(&cfg.outDir, "out", "",
 		"(Required) Path to output directory where scores and reports will be written.\n")
 
 	fs.StringVar(&cfg.refFile, "ref", "",
-		"(Required) Path to file containing reference text.\n")
-
-	fs.Var(&hypArgs, "hyp",
-		`(Required) Path hypothesis file to score. It can either simply be the filepath
-to the hypothesis file, or it can be in the form <name>,<filepath> where
-<name> is identifies the system that generated the hypothesis. The <name> will
-be used in the generated reports. If no name is provided, the name will be set
-automatically. This argument may be provided multiple times to point to score
-multiple hypotheses at once.
+		`(Required) Path to file containing reference text, or to a directory with one transcript
+file per utterance; see --id-pattern. Files compressed with gzip, bzip2 or xz are
+decompressed while being read. If "-", the reference is read from the standard input.
 `)
 
-	fs.StringVar(&delimiter, "delimiter", ",",
-		`The delimiter used in reference and hypotheses files. By default, the program expects
-comma delimited files (.csv) The program needs at least two columns per row, containing
-<utteranceID> and <transcript>. By default, the first and second columns are assumed
-to contain <utteranceID> and <transcript> respectively. This can be changed by using
---id-col and --trn-col arguments.
+	fs.Var(&hypArgs, "hyp", cmdutils.HypUsage)
+
+	fileFormatFlags := cmdutils.RegisterFileFormatFlags(fs)
+	fileFormatFlags.RegisterNBestFlags(fs)
+	fileFormatFlags.RegisterSessionFlags(fs)
+
+	fs.StringVar(&cfg.mode, "mode", score.ModeUtterance,
+		`How hypotheses are scored; one of utterance, overlap or session. In utterance mode,
+hypotheses are aligned against the reference utterance by utterance with sclite. In
+overlap mode, ctm hypotheses are aligned against stm references with asclite, which
+considers overlapping segments of several reference speakers at once, such as in far-field
+meeting recordings; see --overlap-limit. The alignments and reports are the same as in
+utterance mode, but character error rates and word weights are not supported. In
+session mode, inputs hold sessions with several speakers, such as meetings, whose
+hypothesis speaker labels need not match those of the reference; see --col-speaker. The concatenated
+minimum-permutation error rate (cpWER), with the best mapping between reference and
+hypothesis speakers, and the optimal reference combination error rate (ORC-WER), which
+ignores hypothesis speaker labels, are written per session with totals to sessions.json
+in the output directory. Quality gates, --terms, --weight-table and --score-formatting
+are not supported in session mode.
 `)
 
-	fs.IntVar(&cfg.fileFormat.ColID, "col-id", 0,
-		"The column index (zero based, positive only) containing <utteranceID>.\n")
-
-	fs.IntVar(&cfg.fileFormat.ColTrn, "col-trn", 1,
-		"The column index (zero based, positive only) containing <transcript>.\n")
-
-	fs.BoolVar(&cfg.fileFormat.IgnoreFirstRow, "ignore-first", false,
-		"If true, will ignore the first row in the provided files, assuming it is the header row.\n")
+	fs.IntVar(&cfg.scliteCfg.OverlapLimit, "overlap-limit", 0,
+		`Maximum number of overlapping reference speakers considered by asclite in overlap mode.
+Higher limits need more memory and time. If 0, asclite's default is used.
+`)
 
 	fs.BoolVar(&cfg.scliteCfg.CER, "cer", false,
 		"If true, will evaluate character error rate instead of word error rate.\n")
@@ -96,11 +104,82 @@ when they reach this many characters
 	fs.StringVar(&cfg.scliteCfg.Encoding, "encoding", "utf-8",
 		"What text encoding to use for interpreting text.\n")
 
-	fs.BoolVar(&cfg.normCfg.CaseSensitive, "case-sensitive", false,
-		"If true, scoring will be case sensitive.\n")
+	fs.IntVar(&cfg.scliteCfg.Jobs, "jobs", 1,
+		`Number of sclite processes to run in parallel. If greater than 1, utterances in each
+hypothesis are split into shards that are aligned in parallel, and merged back before
+generating reports. The reports are identical to those generated with a single job.
+`)
+
+	cmdutils.RegisterReportFlags(fs, &cfg.scliteCfg)
+	cmdutils.RegisterNormalizeFlags(fs, &cfg.normCfg)
+
+	fs.BoolVar(&cfg.normCfg.ScoreFormatting, "score-formatting", false,
+		`If true, the case and punctuation errors hidden by --case-sensitive=false and
+--strip-punctuation are scored in the same run, by projecting the transcripts before case
+folding and stripping punctuation onto the alignments. The fraction of correctly aligned
+words with the wrong case, and the recall, precision and F1 of each punctuation mark, are
+written to formatting.json in the output directory. Only supported when scoring words
+against delimited references.
+`)
 
-	fs.BoolVar(&cfg.normCfg.NormalizeUnicode, "normalize-unicode", false,
-		"If true, unicode normalization wil be applied reference and hypothesis text before scoring.\n")
+	fs.StringVar(&cfg.termList, "terms", "",
+		`Path to a file listing keywords or multi-word phrases, such as product names, to score
+separately; one per line, optionally followed by a tab and a category. The recall,
+precision and F1 of the terms in each hypothesis, overall, per category and per term, are
+written to terms.json and terms.html in the output directory. Misses are attributed to
+substitutions or deletions using the alignments.
+`)
+
+	fs.StringVar(&cfg.weightFile, "weight-table", "",
+		`Path to a file assigning costs to words, to compute a weighted error rate alongside the
+error rate of each hypothesis, written to weighted.json in the output directory. Each line
+holds a word, or a regular expression enclosed in slashes such as /^[0-9]+$/, followed by
+either one cost or the costs of substitutions, deletions and insertions. Words not listed
+cost 1. Substitutions and deletions cost as much as the reference word, insertions as the
+hypothesis word, and the total is a percentage of the deletion costs of reference words.
+`)
+
+	fs.StringVar(&cfg.scliteCfg.WordWeights, "word-weights", "",
+		`Path to a word weight list (WWL) file with which sclite performs word weight mediated
+alignments, or "unity" to weigh all words equally. The weights of aligned words are
+included in the json alignments, and the wws report can be generated. Note that sclite
+gives words not listed in the file a weight of 0.
+`)
+
+	fs.Float64Var(&maxWER, "max-wer", 0,
+		`Maximum word error rate, in percent, of each hypothesis. If exceeded, the command
+exits with code 3 after writing reports. If not set, not checked; 0 allows no errors.
+`)
+
+	fs.Float64Var(&maxCER, "max-cer", 0,
+		`Maximum character error rate, in percent, of each hypothesis when --cer=true. If
+exceeded, the command exits with code 3 after writing reports. If not set, not checked;
+0 allows no errors.
+`)
+
+	fs.StringVar(&cfg.gateCfg.BaselineDir, "max-regression-vs", "",
+		`Path to the output directory of a baseline run. If set, the error rate of each
+hypothesis may not be higher than that of the baseline by more than --max-regression
+percentage points; otherwise, the command exits with code 3 after writing reports.
+`)
+
+	fs.Float64Var(&cfg.gateCfg.MaxRegression, "max-regression", 0,
+		"Maximum increase in error rate, in percentage points, allowed by --max-regression-vs.\n")
+
+	fs.Var(&sliceArgs, "max-slice",
+		`Maximum error rate, in percent, of a slice of utterances, in the form <pattern>:<max>,
+where <pattern> is a regular expression matched against utterance IDs, for example
+"^spk1-:20". If exceeded, the command exits with code 3 after writing reports. This
+argument may be provided multiple times.
+`)
+
+	configFlags := cmdutils.RegisterConfigFlags(fs)
+
+	fs.StringVar(&cfg.junitFile, "junit", "",
+		`Path to a file where the results of the quality gates (--max-wer, --max-cer,
+--max-regression-vs, --max-slice) are written as a JUnit XML report, with one test case
+per gate and hypothesis.
+`)
 
 	shortUsage := `
 sctk score \
@@ -114,97 +193,143 @@ sctk score \
 		FlagSet:    fs,
 		ShortUsage: shortUsage,
 		ShortHelp:  "Score hypothesis transcripts against provided reference transcripts.",
-		Exec: func(_ context.Context, args []string) (err error) {
-			if err := cfg.parseHypArgs(hypArgs); err != nil {
+		Options:    configFlags.Options(),
+		Exec: func(ctx context.Context, args []string) (err error) {
+			if err := configFlags.Apply(fs); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			if cfg.hypFiles, err = cmdutils.ParseHypArgs(hypArgs); err != nil {
 				fs.Usage()
 				return err
 			}
 
-			if len(delimiter) != 1 {
-				return fmt.Errorf("demiliter  must be a single rune")
+			if cfg.fileFormat, err = fileFormatFlags.FileFormat(); err != nil {
+				fs.Usage()
+				return err
 			}
 
-			cfg.fileFormat.Delimiter = []rune(delimiter)[0]
+			// Error rate gates are only checked if set, so that a maximum of 0
+			// can be enforced.
+			fs.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "max-wer":
+					cfg.gateCfg.MaxWER = &maxWER
+				case "max-cer":
+					cfg.gateCfg.MaxCER = &maxCER
+				}
+			})
+
+			for _, arg := range sliceArgs {
+				slice, err := gate.ParseSlice(arg)
+				if err != nil {
+					fs.Usage()
+					return err
+				}
+
+				cfg.gateCfg.Slices = append(cfg.gateCfg.Slices, slice)
+			}
 
 			if err := cfg.checkArgs(); err != nil {
 				fs.Usage()
 				return err
 			}
 
-			return cfg.runScore(context.Background())
+			return cfg.runScore(ctx)
 		},
 	}
 }
 
-// Defining a stringArray type so that we can parse multiple instances of the
-// -hyp flag.
-type stringArray []string
-
-func (i *stringArray) String() string {
-	return strings.Join(*i, " ")
-}
+func (cfg *Config) checkArgs() error {
+	if err := cfg.normCfg.Validate(); err != nil {
+		return err
+	}
 
-func (i *stringArray) Set(value string) error {
-	*i = append(*i, value)
-	return nil
-}
+	switch cfg.mode {
+	case score.ModeUtterance:
+	case score.ModeOverlap:
+		cfg.scliteCfg.Overlap = true
+	case score.ModeSession:
+		cfg.fileFormat.Sessions = true
+		if err := cfg.fileFormat.Validate(); err != nil {
+			return err
+		}
 
-func (cfg *Config) parseHypArgs(hypArgs stringArray) error {
-	var hypPath, hypName string
-	i := 0
-
-	for _, val := range hypArgs {
-
-		switch parts := strings.Split(val, ","); len(parts) {
-		case 0:
-			return fmt.Errorf("hypothesis file not specified after -hyp flag")
-		case 1:
-			i++
-			hypName, hypPath = fmt.Sprintf("hyp%d", i), parts[0]
-		case 2:
-			hypName, hypPath = parts[0], parts[1]
-		default:
+		if cfg.gateCfg.Enabled() || cfg.termList != "" || cfg.weightFile != "" || cfg.normCfg.ScoreFormatting {
 			return fmt.Errorf(
-				"expected at most 2 comma delimited fields in -hyp flag value, got %d", len(parts),
+				"quality gates, scoring terms, weighted error rates and formatting errors are not supported in %s mode",
+				score.ModeSession,
 			)
 		}
 
-		cfg.hypFiles = append(
-			cfg.hypFiles,
-			sctk.Hypothesis{SystemName: hypName, FilePath: hypPath},
+		return cmdutils.CheckInputs(cfg.refFile, cfg.hypFiles)
+	default:
+		return fmt.Errorf(
+			"unsupported mode %q, supported %s|%s|%s",
+			cfg.mode, score.ModeUtterance, score.ModeOverlap, score.ModeSession,
 		)
 	}
 
-	return nil
-}
-
-func (cfg *Config) checkArgs() error {
-	if err := cfg.fileFormat.Validate(); err != nil {
+	if err := cfg.scliteCfg.Validate(); err != nil {
 		return err
 	}
 
-	if err := cfg.scliteCfg.Validate(); err != nil {
+	if err := cfg.gateCfg.Validate(cfg.scliteCfg.CER); err != nil {
 		return err
 	}
 
-	if _, err := os.Stat(cfg.refFile); os.IsNotExist(err) {
-		return fmt.Errorf("specified reference file does not exist: %q", cfg.refFile)
+	if cfg.gateCfg.Enabled() && !cfg.scliteCfg.WritesAlignmentFormat(sctk.AlignmentFormatJSON) {
+		return fmt.Errorf("quality gates need alignments in the %s format, see --alignment-formats", sctk.AlignmentFormatJSON)
 	}
 
-	for _, f := range cfg.hypFiles {
-		if _, err := os.Stat(f.FilePath); os.IsNotExist(err) {
-			return fmt.Errorf("specified hypothesis file does not exist: %q", f.FilePath)
+	for _, f := range []struct{ path, kind string }{
+		{cfg.termList, "scoring terms"},
+		{cfg.weightFile, "weighted error rates"},
+	} {
+		if f.path == "" {
+			continue
+		}
+
+		if !cfg.scliteCfg.WritesAlignmentFormat(sctk.AlignmentFormatJSON) {
+			return fmt.Errorf("%s needs alignments in the %s format, see --alignment-formats", f.kind, sctk.AlignmentFormatJSON)
+		}
+
+		if _, err := os.Stat(f.path); os.IsNotExist(err) {
+			return fmt.Errorf("specified file for %s does not exist: %q", f.kind, f.path)
 		}
 	}
 
-	return nil
+	return cmdutils.CheckInputs(cfg.refFile, cfg.hypFiles)
 }
 
 // runScore executes sclite and sc_stat on specified reference and hypothesis
-// files to generate error analysis reports.
+// files to generate error analysis reports, scores the terms in the term list
+// and computes weighted error rates, if requested, and then checks the quality
+// gates, if any. In session mode, the speaker attributed error rates of the
+// sessions are computed instead.
 func (cfg *Config) runScore(ctx context.Context) error {
-	return score.Score(
-		ctx, cfg.fileFormat, cfg.normCfg, cfg.scliteCfg,
+	if cfg.mode == score.ModeSession {
+		_, err := score.ScoreSessions(
+			ctx, cfg.fileFormat, cfg.normCfg, cfg.scliteCfg.CER,
+			cfg.outDir, cfg.refFile, cfg.hypFiles,
+		)
+
+		return err
+	}
+
+	opts := score.Options{TermList: cfg.termList, WeightTable: cfg.weightFile, Gates: cfg.gateCfg}
+
+	results, err := score.Run(
+		ctx, cfg.fileFormat, cfg.normCfg, cfg.scliteCfg, opts,
 		cfg.outDir, cfg.refFile, cfg.hypFiles,
 	)
+
+	if cfg.junitFile != "" && results != nil {
+		if err := gate.WriteJUnit(cfg.junitFile, results); err != nil {
+			return err
+		}
+	}
+
+	return err
 }
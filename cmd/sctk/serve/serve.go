@@ -0,0 +1,74 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package serve implements the serve subcommand, which exposes scoring over an
+// HTTP API.
+package serve
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/shahruk10/go-sctk/internal/server"
+)
+
+// Cmd creates and returns a pointer to the ffcli.Command for the serve
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := server.Config{}
+	fs := flag.NewFlagSet("sctk serve", flag.ExitOnError)
+
+	fs.StringVar(&cfg.Addr, "addr", ":8080", "TCP address to listen on.\n")
+
+	fs.Int64Var(&cfg.MaxRequestBytes, "max-request-bytes", 32<<20, //nolint: gomnd // 32 MiB.
+		"Maximum size of request bodies in bytes. Larger requests are rejected.\n")
+
+	fs.IntVar(&cfg.MaxConcurrent, "max-concurrent", runtime.NumCPU(),
+		`Maximum number of requests scored at the same time. Requests beyond that are rejected
+with status 503, and may be retried later.
+`)
+
+	fs.DurationVar(&cfg.Timeout, "timeout", 0,
+		"Maximum time spent scoring a request, e.g. 2m. If 0, there is no limit.\n")
+
+	shortUsage := `
+sctk serve --addr=:8080 --max-concurrent=4
+`
+
+	return &ffcli.Command{
+		Name:       "serve",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Serve an HTTP API for scoring transcripts.",
+		LongHelp: fmt.Sprintf(`Serve an HTTP API for scoring transcripts.
+
+POST %s scores the reference and hypotheses in the JSON request body, and
+returns the metrics and alignments of each hypothesis. GET %s reports whether
+the server is able to score requests. See the README for the request format.`,
+			server.PathScore, server.PathHealth),
+		Exec: func(ctx context.Context, args []string) error {
+			if err := cfg.Validate(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			return server.New(cfg).ListenAndServe(ctx)
+		},
+	}
+}
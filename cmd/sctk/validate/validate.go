@@ -0,0 +1,164 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package validate implements the validate subcommand, which checks reference
+// and hypothesis files for problems without scoring them.
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/peterbourgon/ff/v3/ffcli"
+
+	"github.com/shahruk10/go-sctk/cmd/sctk/cmdutils"
+	"github.com/shahruk10/go-sctk/internal/score"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Config for the validate subcommand.
+type Config struct {
+	refFile    string
+	hypFiles   []sctk.Hypothesis
+	fileFormat score.FileFormat
+	normCfg    score.NormalizeConfig
+	jsonOutput bool
+}
+
+// Cmd creates and returns a pointer to the ffcli.Command for the validate
+// subcommand
+func Cmd() *ffcli.Command {
+	cfg := Config{}
+	fs := flag.NewFlagSet("sctk validate", flag.ExitOnError)
+
+	// Will parse these into config field with the correct type later.
+	var hypArgs cmdutils.StringArray
+
+	fs.StringVar(&cfg.refFile, "ref", "",
+		"(Required) Path to file containing reference text.\n")
+
+	fs.Var(&hypArgs, "hyp",
+		`Path hypothesis file to validate, in the same form as for the score subcommand.
+This argument may be provided multiple times.
+`)
+
+	fileFormatFlags := cmdutils.RegisterFileFormatFlags(fs)
+	cmdutils.RegisterNormalizeFlags(fs, &cfg.normCfg)
+
+	fs.BoolVar(&cfg.jsonOutput, "json", false,
+		"If true, the report is printed in the JSON format instead of as text.\n")
+
+	shortUsage := `
+sctk validate \
+  --ignore-first=true --delimiter="," --col-id=1 --col-trn=2 \
+  --strict=true --ref=truth.csv --hyp=output1.csv
+`
+
+	return &ffcli.Command{
+		Name:       "validate",
+		FlagSet:    fs,
+		ShortUsage: shortUsage,
+		ShortHelp:  "Check reference and hypothesis files for problems before scoring.",
+		Exec: func(ctx context.Context, args []string) (err error) {
+			if cfg.hypFiles, err = cmdutils.ParseHypArgs(hypArgs); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			if cfg.fileFormat, err = fileFormatFlags.FileFormat(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			if err := cfg.checkArgs(); err != nil {
+				fs.Usage()
+				return err
+			}
+
+			return cfg.runValidate(ctx)
+		},
+	}
+}
+
+func (cfg *Config) checkArgs() error {
+	if err := cfg.normCfg.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.refFile == "" {
+		return fmt.Errorf("reference file must be specified")
+	}
+
+	return cmdutils.CheckInputs(cfg.refFile, cfg.hypFiles)
+}
+
+// runValidate validates the specified reference and hypothesis files and prints
+// the report to the standard output. In strict mode, an error is returned if any
+// problems were found.
+func (cfg *Config) runValidate(ctx context.Context) error {
+	report, err := score.Validate(ctx, cfg.fileFormat, cfg.normCfg, cfg.refFile, cfg.hypFiles)
+	if err != nil {
+		return err
+	}
+
+	if cfg.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	} else {
+		printReport(os.Stdout, report)
+	}
+
+	if cfg.fileFormat.Strict {
+		return report.Err()
+	}
+
+	return nil
+}
+
+// printReport writes the given report as text to w.
+func printReport(w io.Writer, report score.ValidationReport) {
+	for _, f := range report.Files {
+		fmt.Fprintf(w, "%s: %d utterances", f.Path, f.NumUtts)
+
+		if c := f.Coverage; c != nil {
+			fmt.Fprintf(w, ", %d of %d reference utterances (%.1f%%), %d without reference",
+				c.NumMatched, c.NumRef, 100*c.Ratio, c.NumExtra) //nolint: gomnd // percentage.
+		}
+
+		fmt.Fprintf(w, ", %d problems\n", len(f.Issues))
+
+		for _, issue := range f.Issues {
+			fmt.Fprintf(w, "  ")
+
+			if issue.Line > 0 {
+				fmt.Fprintf(w, "line %d: ", issue.Line)
+			}
+
+			if issue.ID != "" {
+				fmt.Fprintf(w, "%s: ", issue.ID)
+			}
+
+			fmt.Fprintf(w, "[%s] %s\n", issue.Kind, issue.Message)
+		}
+	}
+}
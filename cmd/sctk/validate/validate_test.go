@@ -0,0 +1,60 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package validate
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCmd(t *testing.T) {
+	t.Parallel()
+
+	const testdata = "../../../internal/score/testdata/validate/"
+
+	testCases := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "good1_stm",
+			args: []string{"--strict=true", "--ref=" + testdata + "good1_ref.stm", "--hyp=" + testdata + "good1_hyp.ctm"},
+		},
+		{
+			name: "bad1_stm",
+			args: []string{"--ref=" + testdata + "bad1_ref.stm", "--hyp=" + testdata + "bad1_hyp.ctm"},
+		},
+		{
+			name:    "bad1_stm_strict",
+			args:    []string{"--strict=true", "--ref=" + testdata + "bad1_ref.stm", "--hyp=" + testdata + "bad1_hyp.ctm"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			err := Cmd().ParseAndRun(context.Background(), tc.args)
+			if (err != nil) != tc.wantErr {
+				subT.Errorf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+		})
+	}
+}
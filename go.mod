@@ -5,12 +5,15 @@ go 1.18
 require (
 	github.com/peterbourgon/ff/v3 v3.1.2
 	github.com/sirupsen/logrus v1.8.1
+	github.com/ulikunitz/xz v0.5.15
 	golang.org/x/text v0.3.7
 )
 
 require (
 	github.com/mattn/go-runewidth v0.0.13 // indirect
+	github.com/pelletier/go-toml v1.9.5 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
+	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
 
 require (
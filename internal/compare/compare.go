@@ -0,0 +1,496 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package compare compares the alignments of two scoring runs to find out what
+// changed between them, such as utterances that got better or worse, changes in
+// confusion pairs and the overall error rate.
+package compare
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+const (
+	// alignmentExt is the extension of the JSON alignment files written to the
+	// output directory of each scoring run.
+	alignmentExt = ".pra.json"
+
+	// significanceLevel is the p-value below which differences in error rate
+	// are considered significant.
+	significanceLevel = 0.05
+)
+
+// Status of an utterance between two runs.
+const (
+	StatusBroken    = "broken"    // No errors in run A, some errors in run B.
+	StatusFixed     = "fixed"     // Some errors in run A, no errors in run B.
+	StatusWorse     = "worse"     // More errors in run B than in run A.
+	StatusBetter    = "better"    // Fewer errors in run B than in run A.
+	StatusUnchanged = "unchanged" // Same number of errors in both runs.
+)
+
+// Metrics counts the reference words and errors of a set of aligned sentences.
+type Metrics struct {
+	NumSentences int     `json:"num_sentences"`
+	RefWords     int     `json:"ref_words"`
+	Sub          int     `json:"sub"`
+	Del          int     `json:"del"`
+	Ins          int     `json:"ins"`
+	Errors       int     `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"`
+}
+
+// add adds the counts of the given sentence to the metrics.
+func (m *Metrics) add(s *sctk.AlignedSentence) {
+	st := s.Stats()
+
+	m.NumSentences++
+	m.RefWords += st.RefWords
+	m.Sub += st.Sub
+	m.Del += st.Del
+	m.Ins += st.Ins
+	m.Errors = m.Sub + m.Del + m.Ins
+	m.ErrorRate = sctk.ErrorRate(m.Errors, m.RefWords)
+}
+
+// SentenceMetrics returns the metrics of the given sentence.
+func SentenceMetrics(s *sctk.AlignedSentence) Metrics {
+	var m Metrics
+	m.add(s)
+
+	return m
+}
+
+// SystemMetrics returns the metrics of the sentences of the given hypothesis
+// for which keep returns true, or of all sentences if keep is nil.
+func SystemMetrics(a *sctk.AlignedHypothesis, keep func(*sctk.AlignedSentence) bool) Metrics {
+	var m Metrics
+
+	for _, s := range sentencesByID(a) {
+		if keep == nil || keep(s) {
+			m.add(s)
+		}
+	}
+
+	return m
+}
+
+// An UttDiff compares one utterance between two runs.
+type UttDiff struct {
+	SpeakerID  string  `json:"speaker_id"`
+	SentenceID string  `json:"sentence_id"`
+	Status     string  `json:"status"`
+	A          Metrics `json:"a"`
+	B          Metrics `json:"b"`
+	DeltaRate  float64 `json:"delta_error_rate"`
+
+	// Ref is the reference of the utterance, and HypA and HypB its hypotheses
+	// in each run.
+	Ref  string `json:"ref"`
+	HypA string `json:"hyp_a"`
+	HypB string `json:"hyp_b"`
+}
+
+// A PairDiff is the change in the number of occurrences of a confusion pair,
+// a reference word substituted by a hypothesis word, between two runs.
+type PairDiff struct {
+	Ref    string `json:"ref"`
+	Hyp    string `json:"hyp"`
+	CountA int    `json:"count_a"`
+	CountB int    `json:"count_b"`
+	Delta  int    `json:"delta"`
+}
+
+// Significance is the result of a two-sided sign test on the number of errors
+// per utterance, testing whether utterances are as likely to get better as to
+// get worse between two runs.
+type Significance struct {
+	NumBetter   int     `json:"num_better"`
+	NumWorse    int     `json:"num_worse"`
+	NumTied     int     `json:"num_tied"`
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"`
+}
+
+// A SystemDiff compares the alignments of one system between two runs.
+type SystemDiff struct {
+	SystemA string `json:"system_a"`
+	SystemB string `json:"system_b"`
+
+	// A and B are the metrics of all utterances in each run, and DeltaRate the
+	// change in error rate from run A to run B.
+	A         Metrics `json:"a"`
+	B         Metrics `json:"b"`
+	DeltaRate float64 `json:"delta_error_rate"`
+
+	// Utterances lists the utterances found in both runs whose number of errors
+	// changed, the ones that changed most first. OnlyInA and OnlyInB list the
+	// IDs of utterances found in one run only.
+	Utterances []UttDiff `json:"utterances"`
+	OnlyInA    []string  `json:"only_in_a"`
+	OnlyInB    []string  `json:"only_in_b"`
+
+	// ConfusionPairs lists the confusion pairs whose number of occurrences
+	// changed, the ones that changed most first.
+	ConfusionPairs []PairDiff `json:"confusion_pairs"`
+
+	Significance Significance `json:"significance"`
+}
+
+// Broken returns the utterances with no errors in run A, and some in run B.
+func (d *SystemDiff) Broken() []UttDiff {
+	return d.withStatus(StatusBroken)
+}
+
+// Fixed returns the utterances with some errors in run A, and none in run B.
+func (d *SystemDiff) Fixed() []UttDiff {
+	return d.withStatus(StatusFixed)
+}
+
+func (d *SystemDiff) withStatus(status string) []UttDiff {
+	utts := make([]UttDiff, 0)
+
+	for _, u := range d.Utterances {
+		if u.Status == status {
+			utts = append(utts, u)
+		}
+	}
+
+	return utts
+}
+
+// A RunDiff compares all systems scored in two runs. Systems are matched by
+// name; if each run has a single system, they are compared regardless of name.
+type RunDiff struct {
+	RunA    string       `json:"run_a"`
+	RunB    string       `json:"run_b"`
+	Systems []SystemDiff `json:"systems"`
+	OnlyInA []string     `json:"systems_only_in_a"`
+	OnlyInB []string     `json:"systems_only_in_b"`
+}
+
+// LoadRun reads the alignments of all systems scored in a run, from the
+// .pra.json files in the given output directory. The path may also point to a
+// single .pra.json file. The alignments are returned indexed by system name.
+func LoadRun(runPath string) (map[string]*sctk.AlignedHypothesis, error) {
+	files := []string{runPath}
+
+	if info, err := os.Stat(runPath); err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	} else if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(runPath, "*"+alignmentExt)); err != nil {
+			return nil, fmt.Errorf("failed to list alignment files: %w", err)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s alignment files found in %q", alignmentExt, runPath)
+	}
+
+	return LoadAlignments(files)
+}
+
+// LoadAlignments reads the alignments of the systems in the given .pra.json
+// files, indexed by system name.
+func LoadAlignments(files []string) (map[string]*sctk.AlignedHypothesis, error) {
+	systems := make(map[string]*sctk.AlignedHypothesis)
+
+	for _, f := range files {
+		aligned, err := sctk.ReadAlignmentJSON(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if aligned.SystemName == "" {
+			aligned.SystemName = strings.TrimSuffix(filepath.Base(f), alignmentExt)
+		}
+
+		systems[aligned.SystemName] = aligned
+	}
+
+	return systems, nil
+}
+
+// CompareRuns loads the alignments of the runs in the given output directories
+// and compares them.
+func CompareRuns(runA, runB string) (RunDiff, error) {
+	d := RunDiff{RunA: runA, RunB: runB}
+
+	systemsA, err := LoadRun(runA)
+	if err != nil {
+		return d, err
+	}
+
+	systemsB, err := LoadRun(runB)
+	if err != nil {
+		return d, err
+	}
+
+	return CompareSystems(runA, runB, systemsA, systemsB)
+}
+
+// CompareSystems compares the alignments of the systems of run A and run B,
+// labelled runA and runB, indexed by system name. Systems are matched by name,
+// or compared directly if each run has a single system.
+func CompareSystems(runA, runB string, systemsA, systemsB map[string]*sctk.AlignedHypothesis) (RunDiff, error) {
+	d := RunDiff{RunA: runA, RunB: runB}
+
+	if len(systemsA) == 1 && len(systemsB) == 1 {
+		for _, a := range systemsA {
+			for _, b := range systemsB {
+				d.Systems = append(d.Systems, Compare(a, b))
+			}
+		}
+
+		return d, nil
+	}
+
+	for _, name := range textutils.SortedKeys(systemsA) {
+		if b, ok := systemsB[name]; ok {
+			d.Systems = append(d.Systems, Compare(systemsA[name], b))
+		} else {
+			d.OnlyInA = append(d.OnlyInA, name)
+		}
+	}
+
+	for _, name := range textutils.SortedKeys(systemsB) {
+		if _, ok := systemsA[name]; !ok {
+			d.OnlyInB = append(d.OnlyInB, name)
+		}
+	}
+
+	if len(d.Systems) == 0 {
+		return d, fmt.Errorf("no systems in common between %q and %q", runA, runB)
+	}
+
+	return d, nil
+}
+
+// Compare compares the alignments of a system between run A and run B.
+func Compare(a, b *sctk.AlignedHypothesis) SystemDiff {
+	d := SystemDiff{
+		SystemA:    a.SystemName,
+		SystemB:    b.SystemName,
+		OnlyInA:    make([]string, 0),
+		OnlyInB:    make([]string, 0),
+		Utterances: make([]UttDiff, 0),
+	}
+
+	sentsA, sentsB := sentencesByID(a), sentencesByID(b)
+	pairsA, pairsB := make(map[[2]string]int), make(map[[2]string]int)
+
+	for _, id := range textutils.SortedKeys(sentsA) {
+		sa := sentsA[id]
+		d.A.add(sa)
+		countPairs(sa, pairsA)
+
+		sb, ok := sentsB[id]
+		if !ok {
+			d.OnlyInA = append(d.OnlyInA, id)
+			continue
+		}
+
+		u := compareSentences(sa, sb)
+
+		switch u.Status {
+		case StatusUnchanged:
+			d.Significance.NumTied++
+		case StatusBetter, StatusFixed:
+			d.Significance.NumBetter++
+		default:
+			d.Significance.NumWorse++
+		}
+
+		if u.Status != StatusUnchanged {
+			d.Utterances = append(d.Utterances, u)
+		}
+	}
+
+	for _, id := range textutils.SortedKeys(sentsB) {
+		d.B.add(sentsB[id])
+		countPairs(sentsB[id], pairsB)
+
+		if _, ok := sentsA[id]; !ok {
+			d.OnlyInB = append(d.OnlyInB, id)
+		}
+	}
+
+	d.DeltaRate = d.B.ErrorRate - d.A.ErrorRate
+
+	// Utterances that changed the most first; ties in the order of their IDs.
+	sort.SliceStable(d.Utterances, func(i, j int) bool {
+		return math.Abs(d.Utterances[i].DeltaRate) > math.Abs(d.Utterances[j].DeltaRate)
+	})
+
+	d.ConfusionPairs = diffPairs(pairsA, pairsB)
+	d.Significance.PValue = signTest(d.Significance.NumBetter, d.Significance.NumWorse)
+	d.Significance.Significant = d.Significance.PValue < significanceLevel
+
+	return d
+}
+
+// compareSentences compares the same sentence between two runs.
+func compareSentences(a, b *sctk.AlignedSentence) UttDiff {
+	u := UttDiff{
+		SpeakerID:  a.SpeakerID,
+		SentenceID: a.SentenceID,
+		Ref:        joinWords(a, func(w sctk.AlignedWord) string { return w.Ref }),
+		HypA:       joinWords(a, func(w sctk.AlignedWord) string { return w.Hyp }),
+		HypB:       joinWords(b, func(w sctk.AlignedWord) string { return w.Hyp }),
+	}
+
+	u.A.add(a)
+	u.B.add(b)
+	u.DeltaRate = u.B.ErrorRate - u.A.ErrorRate
+
+	switch {
+	case u.A.Errors == u.B.Errors:
+		u.Status = StatusUnchanged
+	case u.A.Errors == 0:
+		u.Status = StatusBroken
+	case u.B.Errors == 0:
+		u.Status = StatusFixed
+	case u.B.Errors > u.A.Errors:
+		u.Status = StatusWorse
+	default:
+		u.Status = StatusBetter
+	}
+
+	return u
+}
+
+// sentencesByID returns all sentences of the given hypothesis indexed by
+// sentence ID, which are unique across speakers.
+func sentencesByID(a *sctk.AlignedHypothesis) map[string]*sctk.AlignedSentence {
+	sents := make(map[string]*sctk.AlignedSentence)
+
+	for spk, spkSents := range a.Speakers {
+		for id, s := range spkSents {
+			if s.SpeakerID == "" {
+				s.SpeakerID = spk
+			}
+
+			if s.SentenceID == "" {
+				s.SentenceID = id
+			}
+
+			sents[id] = s
+		}
+	}
+
+	return sents
+}
+
+// joinWords joins the words selected from each aligned word of the sentence,
+// skipping empty ones.
+func joinWords(s *sctk.AlignedSentence, word func(sctk.AlignedWord) string) string {
+	words := make([]string, 0, len(s.Words))
+
+	for _, w := range s.Words {
+		if v := word(w); v != "" {
+			words = append(words, v)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// countPairs counts the confusion pairs in the given sentence.
+func countPairs(s *sctk.AlignedSentence, pairs map[[2]string]int) {
+	for _, w := range s.Words {
+		if w.Label == "S" {
+			pairs[[2]string{w.Ref, w.Hyp}]++
+		}
+	}
+}
+
+// diffPairs returns the confusion pairs whose counts differ between the runs,
+// the ones whose count changed most first.
+func diffPairs(pairsA, pairsB map[[2]string]int) []PairDiff {
+	diffs := make([]PairDiff, 0)
+
+	for p, countA := range pairsA {
+		if countB := pairsB[p]; countB != countA {
+			diffs = append(diffs, PairDiff{p[0], p[1], countA, countB, countB - countA})
+		}
+	}
+
+	for p, countB := range pairsB {
+		if _, ok := pairsA[p]; !ok {
+			diffs = append(diffs, PairDiff{p[0], p[1], 0, countB, countB})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		di, dj := diffs[i], diffs[j]
+
+		if absI, absJ := abs(di.Delta), abs(dj.Delta); absI != absJ {
+			return absI > absJ
+		}
+
+		if di.Ref != dj.Ref {
+			return di.Ref < dj.Ref
+		}
+
+		return di.Hyp < dj.Hyp
+	})
+
+	return diffs
+}
+
+// signTest returns the two-sided p-value of the exact sign test, for the given
+// number of utterances that got better and worse. Under the null hypothesis,
+// both are equally likely.
+func signTest(numBetter, numWorse int) float64 {
+	n := numBetter + numWorse
+	if n == 0 {
+		return 1
+	}
+
+	k := numBetter
+	if numWorse < k {
+		k = numWorse
+	}
+
+	// P(X <= k) for X ~ Binomial(n, 0.5), summed in log space to avoid
+	// overflowing for large n.
+	lgN, _ := math.Lgamma(float64(n + 1))
+	logHalfN := float64(n) * math.Log(0.5) //nolint: gomnd // probability of either outcome.
+	p := 0.0
+
+	for i := 0; i <= k; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lgN - lgI - lgNI + logHalfN)
+	}
+
+	return math.Min(1, 2*p) //nolint: gomnd // two-sided.
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
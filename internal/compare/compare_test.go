@@ -0,0 +1,166 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package compare
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestCompareRuns(t *testing.T) {
+	t.Parallel()
+
+	d, err := CompareRuns("testdata/a", "testdata/b")
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	if len(d.Systems) != 1 {
+		t.Fatalf("expected 1 system to be compared, got %d", len(d.Systems))
+	}
+
+	s := d.Systems[0]
+
+	wantA := Metrics{NumSentences: 4, RefWords: 18, Sub: 2, Del: 1, Errors: 3, ErrorRate: 100 * 3.0 / 18}
+	if diff := cmp.Diff(wantA, s.A); diff != "" {
+		t.Errorf("unexpected metrics of run A, (-want, +got):\n%s", diff)
+	}
+
+	wantStatus := map[string]string{
+		"(spk1-u1)": StatusBroken,
+		"(spk1-u2)": StatusFixed,
+		"(spk2-u3)": StatusBroken,
+		"(spk2-u4)": StatusWorse,
+	}
+
+	gotStatus := make(map[string]string)
+	for _, u := range s.Utterances {
+		gotStatus[u.SentenceID] = u.Status
+	}
+
+	if diff := cmp.Diff(wantStatus, gotStatus); diff != "" {
+		t.Errorf("unexpected utterance status, (-want, +got):\n%s", diff)
+	}
+
+	wantPairs := []PairDiff{
+		{Ref: "cat", Hyp: "bat", CountA: 1, CountB: 0, Delta: -1},
+		{Ref: "three", Hyp: "tree", CountA: 0, CountB: 1, Delta: 1},
+		{Ref: "to", Hyp: "two", CountA: 0, CountB: 1, Delta: 1},
+		{Ref: "world", Hyp: "word", CountA: 0, CountB: 1, Delta: 1},
+	}
+
+	if diff := cmp.Diff(wantPairs, s.ConfusionPairs); diff != "" {
+		t.Errorf("unexpected confusion pairs, (-want, +got):\n%s", diff)
+	}
+
+	wantSig := Significance{NumBetter: 1, NumWorse: 3, PValue: 0.625}
+	if diff := cmp.Diff(wantSig, s.Significance, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("unexpected significance, (-want, +got):\n%s", diff)
+	}
+
+	var md strings.Builder
+	if err := d.WriteMarkdown(&md, 1); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	for _, want := range []string{"### Newly broken (2)", "### Fixed (1)", "_1 more not shown._"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("expected markdown summary to contain %q, got:\n%s", want, md.String())
+		}
+	}
+}
+
+func TestSignTest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		numBetter, numWorse int
+		want                float64
+	}{
+		{0, 0, 1},
+		{1, 1, 1},
+		{0, 5, 0.0625},
+		{10, 0, 0.001953125},
+		{2, 8, 0.109375},
+		{500, 600, 0.0028195450},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(fmt.Sprintf("%d_%d", tc.numBetter, tc.numWorse), func(subT *testing.T) {
+			subT.Parallel()
+
+			got := signTest(tc.numBetter, tc.numWorse)
+			if math.Abs(got-tc.want) > 1e-9 {
+				subT.Errorf("unexpected p-value, want=%v, got=%v", tc.want, got)
+			}
+		})
+	}
+}
+
+// TestSentenceMetrics checks that the error rates of sentences agree with the
+// statistics of the aligned sentences, including those without reference words.
+func TestSentenceMetrics(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		words []sctk.AlignedWord
+		want  Metrics
+	}{
+		{
+			name: "errors",
+			words: []sctk.AlignedWord{
+				{Label: "C", Ref: "a", Hyp: "a"},
+				{Label: "S", Ref: "b", Hyp: "c"},
+				{Label: "I", Hyp: "d"},
+			},
+			want: Metrics{NumSentences: 1, RefWords: 2, Sub: 1, Ins: 1, Errors: 2, ErrorRate: 100},
+		},
+		{
+			name:  "insertionsWithoutReference",
+			words: []sctk.AlignedWord{{Label: "I", Hyp: "a"}, {Label: "I", Hyp: "b"}},
+			want:  Metrics{NumSentences: 1, Ins: 2, Errors: 2, ErrorRate: 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			s := &sctk.AlignedSentence{Words: tc.words}
+
+			got := SentenceMetrics(s)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected metrics, (-want, +got):\n%s", diff)
+			}
+
+			if wer := s.Stats().WER; got.ErrorRate != wer {
+				subT.Errorf("error rate differs from sentence statistics, want=%v, got=%v", wer, got.ErrorRate)
+			}
+		})
+	}
+}
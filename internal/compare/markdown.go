@@ -0,0 +1,150 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package compare
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes a summary of the differences between the runs as
+// Markdown, suitable for pasting into a pull request. At most maxRows
+// utterances and confusion pairs are listed per table; all are listed if
+// maxRows is <= 0.
+func (d *RunDiff) WriteMarkdown(w io.Writer, maxRows int) error {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "# Comparison of `%s` and `%s`\n", d.RunA, d.RunB)
+
+	if len(d.OnlyInA) > 0 {
+		fmt.Fprintf(b, "\nSystems only in A: %s\n", codeList(d.OnlyInA))
+	}
+
+	if len(d.OnlyInB) > 0 {
+		fmt.Fprintf(b, "\nSystems only in B: %s\n", codeList(d.OnlyInB))
+	}
+
+	for i := range d.Systems {
+		d.Systems[i].writeMarkdown(b, maxRows)
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func (d *SystemDiff) writeMarkdown(b *strings.Builder, maxRows int) {
+	name := d.SystemA
+	if d.SystemB != d.SystemA {
+		name = fmt.Sprintf("%s → %s", d.SystemA, d.SystemB)
+	}
+
+	fmt.Fprintf(b, "\n## %s\n\n", name)
+
+	b.WriteString("| Metric | A | B | Δ |\n")
+	b.WriteString("|:-------|--:|--:|--:|\n")
+	fmt.Fprintf(b, "| Error rate (%%) | %.2f | %.2f | %+.2f |\n", d.A.ErrorRate, d.B.ErrorRate, d.DeltaRate)
+
+	for _, row := range []struct {
+		name string
+		a, b int
+	}{
+		{"Substitutions", d.A.Sub, d.B.Sub},
+		{"Deletions", d.A.Del, d.B.Del},
+		{"Insertions", d.A.Ins, d.B.Ins},
+		{"Errors", d.A.Errors, d.B.Errors},
+		{"Reference words", d.A.RefWords, d.B.RefWords},
+		{"Utterances", d.A.NumSentences, d.B.NumSentences},
+	} {
+		fmt.Fprintf(b, "| %s | %d | %d | %+d |\n", row.name, row.a, row.b, row.b-row.a)
+	}
+
+	s := d.Significance
+	verdict := "not significant"
+
+	if s.Significant {
+		verdict = "significant"
+	}
+
+	fmt.Fprintf(b,
+		"\n%d utterances got better, %d got worse and %d are unchanged; "+
+			"the difference is **%s** (sign test, p = %.4f).\n",
+		s.NumBetter, s.NumWorse, s.NumTied, verdict, s.PValue)
+
+	if len(d.OnlyInA) > 0 || len(d.OnlyInB) > 0 {
+		fmt.Fprintf(b, "\n%d utterances are only in A and %d only in B.\n", len(d.OnlyInA), len(d.OnlyInB))
+	}
+
+	writeUttTable(b, "Newly broken", d.Broken(), maxRows)
+	writeUttTable(b, "Fixed", d.Fixed(), maxRows)
+	writeUttTable(b, "Largest changes", d.Utterances, maxRows)
+
+	if len(d.ConfusionPairs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### Confusion pairs (%d changed)\n\n", len(d.ConfusionPairs))
+	b.WriteString("| Ref | Hyp | A | B | Δ |\n")
+	b.WriteString("|:----|:----|--:|--:|--:|\n")
+
+	for i, p := range d.ConfusionPairs {
+		if maxRows > 0 && i == maxRows {
+			break
+		}
+
+		fmt.Fprintf(b, "| %s | %s | %d | %d | %+d |\n",
+			escapeCell(p.Ref), escapeCell(p.Hyp), p.CountA, p.CountB, p.Delta)
+	}
+}
+
+func writeUttTable(b *strings.Builder, title string, utts []UttDiff, maxRows int) {
+	if len(utts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s (%d)\n\n", title, len(utts))
+	b.WriteString("| Utterance | Errors A | Errors B | Δ Error rate (%) | Ref | Hyp A | Hyp B |\n")
+	b.WriteString("|:----------|---------:|---------:|-----------------:|:----|:------|:------|\n")
+
+	for i, u := range utts {
+		if maxRows > 0 && i == maxRows {
+			break
+		}
+
+		fmt.Fprintf(b, "| %s | %d | %d | %+.2f | %s | %s | %s |\n",
+			escapeCell(u.SentenceID), u.A.Errors, u.B.Errors, u.DeltaRate,
+			escapeCell(u.Ref), escapeCell(u.HypA), escapeCell(u.HypB))
+	}
+
+	if maxRows > 0 && len(utts) > maxRows {
+		fmt.Fprintf(b, "\n_%d more not shown._\n", len(utts)-maxRows)
+	}
+}
+
+// escapeCell escapes characters that would break a Markdown table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func codeList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, "`"+item+"`")
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,176 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package fileutils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/ulikunitz/xz"
+)
+
+// Stdin is the path that refers to the standard input in Open.
+const Stdin = "-"
+
+// Compression formats detected by Open.
+const (
+	compressionNone  = ""
+	compressionGzip  = "gzip"
+	compressionBzip2 = "bzip2"
+	compressionXz    = "xz"
+	compressionZstd  = "zstd"
+)
+
+// compressedExts maps the extensions of compressed files to their format.
+var compressedExts = map[string]string{
+	".gz":   compressionGzip,
+	".bz2":  compressionBzip2,
+	".xz":   compressionXz,
+	".zst":  compressionZstd,
+	".zstd": compressionZstd,
+}
+
+// compressedMagic maps the magic bytes at the start of compressed files to their
+// format.
+var compressedMagic = []struct {
+	magic  []byte
+	format string
+}{
+	{[]byte{0x1f, 0x8b}, compressionGzip},
+	{[]byte("BZh"), compressionBzip2},
+	{[]byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, compressionXz},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, compressionZstd},
+}
+
+// readCloser reads from a (possibly decompressing) reader, and closes the
+// underlying file when closed.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var err error
+
+	for _, c := range r.closers {
+		if errClose := c.Close(); errClose != nil && err == nil {
+			err = errClose
+		}
+	}
+
+	return err
+}
+
+// Open opens the file at the given path for reading, or the standard input if
+// the path is Stdin. Files compressed with gzip, bzip2 or xz are decompressed
+// transparently while being read. The compression format is detected from the
+// magic bytes at the start of the file, or else from the file extension. Files
+// compressed with zstd are detected and rejected, rather than read as text.
+func Open(filePath string) (io.ReadCloser, error) {
+	const (
+		peekLen = 6
+	)
+
+	var f *os.File
+
+	if filePath == Stdin {
+		// The standard input is left open for others to use.
+		f = os.Stdin
+	} else {
+		var err error
+		if f, err = os.Open(filePath); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &readCloser{}
+	if f != os.Stdin {
+		r.closers = append(r.closers, f)
+	}
+
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(peekLen)
+
+	format := compressedExts[strings.ToLower(path.Ext(filePath))]
+	for _, m := range compressedMagic {
+		if bytes.HasPrefix(head, m.magic) {
+			format = m.format
+			break
+		}
+	}
+
+	switch format {
+	case compressionNone:
+		r.Reader = br
+	case compressionGzip:
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			CloseOrLog(r, filePath)
+			return nil, fmt.Errorf("failed to read gzip header: %w", err)
+		}
+
+		r.Reader, r.closers = gr, append(r.closers, gr)
+	case compressionBzip2:
+		r.Reader = bzip2.NewReader(br)
+	case compressionXz:
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			CloseOrLog(r, filePath)
+			return nil, fmt.Errorf("failed to read xz header: %w", err)
+		}
+
+		r.Reader = xr
+	default:
+		CloseOrLog(r, filePath)
+		return nil, fmt.Errorf(
+			"%s compressed files are not supported, decompress and pipe the file into the standard input instead",
+			format,
+		)
+	}
+
+	return r, nil
+}
+
+// TrimCompressedExt removes the extension of a compression format supported by
+// Open from the given file path, if it has one, e.g. hyp.ctm.gz becomes
+// hyp.ctm.
+func TrimCompressedExt(filePath string) string {
+	ext := path.Ext(filePath)
+	if _, ok := compressedExts[strings.ToLower(ext)]; ok {
+		return strings.TrimSuffix(filePath, ext)
+	}
+
+	return filePath
+}
+
+// CloseOrLog tries to close the given reader opened from the given path. If it
+// fails to do so, the error is logged.
+func CloseOrLog(r io.Closer, filePath string) {
+	if errClose := r.Close(); errClose != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": errClose,
+			"path":  filePath,
+		}).Error("failed to close file")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package fileutils
+
+import (
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOpen(t *testing.T) {
+	t.Parallel()
+
+	const want = "spk1-utt1,hello world\nspk1-utt2,good morning\n"
+
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "plain", input: "testdata/hyp.csv"},
+		{name: "gzip", input: "testdata/hyp.csv.gz"},
+		{name: "bzip2", input: "testdata/hyp.csv.bz2"},
+		{name: "xz", input: "testdata/hyp.csv.xz"},
+		{name: "gzip_magic_bytes", input: "testdata/hyp_gzip.bin"},
+		{name: "zstd_unsupported", input: "testdata/hyp.csv.zst", wantErr: true},
+		{name: "zstd_magic_bytes_unsupported", input: "testdata/hyp_zstd.bin", wantErr: true},
+		{name: "missing", input: "testdata/missing.csv", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			r, err := Open(tc.input)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			defer CloseOrLog(r, tc.input)
+
+			got, err := io.ReadAll(r)
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			if diff := cmp.Diff(want, string(got)); diff != "" {
+				subT.Errorf("unexpected content, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package gate checks the results of a scoring run against quality gates, such
+// as a maximum error rate, so that scoring can be used to gate releases in CI.
+package gate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// ExitCodeFailed is the exit code of commands when a quality gate fails,
+// distinct from the exit code of other errors.
+const ExitCodeFailed = 3
+
+// Names of the quality gates.
+const (
+	GateMaxWER        = "max-wer"
+	GateMaxCER        = "max-cer"
+	GateMaxRegression = "max-regression"
+	GateMaxSlice      = "max-slice"
+)
+
+// Config configures the quality gates checked after scoring. Error rates and
+// thresholds are percentages; gates with a threshold of zero are not checked,
+// unless noted otherwise.
+type Config struct {
+	// MaxWER and MaxCER are the maximum error rates of each system; not checked
+	// if nil. A maximum of zero allows no errors.
+	MaxWER *float64 `json:"max_wer,omitempty"`
+	MaxCER *float64 `json:"max_cer,omitempty"`
+
+	// BaselineDir is the output directory of a baseline run. If set, the error
+	// rate of each system may not be higher than that of the baseline by more
+	// than MaxRegression percentage points, which may be zero.
+	BaselineDir   string  `json:"baseline_dir,omitempty"`
+	MaxRegression float64 `json:"max_regression,omitempty"`
+
+	// Slices are thresholds for the error rate of subsets of utterances.
+	Slices []Slice `json:"slices,omitempty"`
+}
+
+// A Slice is a threshold for the error rate of the utterances whose IDs match
+// Pattern. The error rate is the WER, or CER when scoring characters.
+type Slice struct {
+	Pattern string  `json:"pattern"`
+	Max     float64 `json:"max"`
+}
+
+// ParseSlice parses a slice threshold given in the form <pattern>:<max>, where
+// pattern is a regular expression matched against utterance IDs.
+func ParseSlice(s string) (Slice, error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return Slice{}, fmt.Errorf("expected slice threshold in the form <pattern>:<max>, got %q", s)
+	}
+
+	max, err := strconv.ParseFloat(s[i+1:], 64) //nolint: gomnd // bit size.
+	if err != nil {
+		return Slice{}, fmt.Errorf("invalid maximum error rate in slice threshold %q: %w", s, err)
+	}
+
+	return Slice{Pattern: s[:i], Max: max}, nil
+}
+
+// Enabled returns true if any quality gate is configured.
+func (c *Config) Enabled() bool {
+	return c.MaxWER != nil || c.MaxCER != nil || c.BaselineDir != "" || len(c.Slices) > 0
+}
+
+// Validate checks whether the configured gates are valid for a run that scores
+// characters if cer is true, and words otherwise.
+func (c *Config) Validate(cer bool) error {
+	for _, max := range []*float64{c.MaxWER, c.MaxCER, &c.MaxRegression} {
+		if max != nil && *max < 0 {
+			return fmt.Errorf("error rate thresholds must be >= 0")
+		}
+	}
+
+	if cer && c.MaxWER != nil {
+		return fmt.Errorf("maximum WER can not be checked when scoring characters, set a maximum CER instead")
+	}
+
+	if !cer && c.MaxCER != nil {
+		return fmt.Errorf("maximum CER can only be checked when scoring characters")
+	}
+
+	for _, s := range c.Slices {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("invalid slice pattern %q: %w", s.Pattern, err)
+		}
+
+		if s.Max < 0 {
+			return fmt.Errorf("error rate thresholds must be >= 0")
+		}
+	}
+
+	return nil
+}
+
+// A Result is the outcome of checking one quality gate for one system.
+type Result struct {
+	Gate   string `json:"gate"`
+	System string `json:"system"`
+
+	// Slice is the pattern of the slice checked, for slice gates.
+	Slice string `json:"slice,omitempty"`
+
+	// Value is the error rate, or the change in error rate for regression
+	// gates, checked against Threshold.
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Passed    bool    `json:"passed"`
+	Message   string  `json:"message"`
+}
+
+// A FailedError is returned when one or more quality gates fail.
+type FailedError struct {
+	Failed []Result
+}
+
+func (e *FailedError) Error() string {
+	msgs := make([]string, 0, len(e.Failed))
+	for _, r := range e.Failed {
+		msgs = append(msgs, r.Message)
+	}
+
+	return fmt.Sprintf("%d quality gates failed: %s", len(e.Failed), strings.Join(msgs, "; "))
+}
+
+// ExitCode returns the exit code for commands failing because of this error.
+func (e *FailedError) ExitCode() int {
+	return ExitCodeFailed
+}
+
+// Check checks the configured quality gates against the alignments of the
+// systems scored in a run, given as the .pra.json files written for each,
+// logging the result of each gate. If any gate fails, the results are returned
+// along with a *FailedError.
+func Check(cfg Config, cer bool, alignmentFiles []string) ([]Result, error) {
+	systems, err := compare.LoadAlignments(alignmentFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0)
+
+	for _, name := range textutils.SortedKeys(systems) {
+		results = append(results, checkSystem(cfg, cer, systems[name])...)
+	}
+
+	if cfg.BaselineDir != "" {
+		baseline, err := compare.LoadRun(cfg.BaselineDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read baseline: %w", err)
+		}
+
+		d, err := compare.CompareSystems(cfg.BaselineDir, "current run", baseline, systems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare with baseline: %w", err)
+		}
+
+		for _, s := range d.Systems {
+			results = append(results, newResult(
+				GateMaxRegression, s.SystemB, "", s.DeltaRate, cfg.MaxRegression,
+				"error rate changed by %+.2f points from %.2f%% in baseline to %.2f%%",
+				s.DeltaRate, s.A.ErrorRate, s.B.ErrorRate,
+			))
+		}
+
+		for _, name := range d.OnlyInB {
+			results = append(results, Result{
+				Gate: GateMaxRegression, System: name, Threshold: cfg.MaxRegression,
+				Message: fmt.Sprintf("%s: system %q not found in baseline", GateMaxRegression, name),
+			})
+		}
+	}
+
+	failed := make([]Result, 0)
+
+	for _, r := range results {
+		fields := logrus.Fields{"gate": r.Gate, "system": r.System}
+		if r.Slice != "" {
+			fields["slice"] = r.Slice
+		}
+
+		if r.Passed {
+			logrus.WithFields(fields).Info(r.Message)
+		} else {
+			logrus.WithFields(fields).Error(r.Message)
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, &FailedError{Failed: failed}
+	}
+
+	return results, nil
+}
+
+// checkSystem checks the error rate gates for one system.
+func checkSystem(cfg Config, cer bool, aligned *sctk.AlignedHypothesis) []Result {
+	results := make([]Result, 0)
+	m := compare.SystemMetrics(aligned, nil)
+
+	gate, max, metric := GateMaxWER, cfg.MaxWER, "WER"
+	if cer {
+		gate, max, metric = GateMaxCER, cfg.MaxCER, "CER"
+	}
+
+	if max != nil {
+		results = append(results, newResult(
+			gate, aligned.SystemName, "", m.ErrorRate, *max, "%s is %.2f%%", metric, m.ErrorRate,
+		))
+	}
+
+	for _, s := range cfg.Slices {
+		re := regexp.MustCompile(s.Pattern)
+
+		sm := compare.SystemMetrics(aligned, func(sent *sctk.AlignedSentence) bool {
+			return re.MatchString(strings.Trim(sent.SentenceID, "()"))
+		})
+
+		r := newResult(
+			GateMaxSlice, aligned.SystemName, s.Pattern, sm.ErrorRate, s.Max,
+			"%s of %d utterances matching %q is %.2f%%", metric, sm.NumSentences, s.Pattern, sm.ErrorRate,
+		)
+
+		if sm.NumSentences == 0 {
+			r.Passed = false
+			r.Message = fmt.Sprintf("%s: no utterances match slice %q", GateMaxSlice, s.Pattern)
+		}
+
+		results = append(results, r)
+	}
+
+	return results
+}
+
+// newResult returns the result of a gate passing if value is at most
+// threshold. The message describes the value checked, and is extended with
+// the threshold and by how much it was exceeded.
+func newResult(
+	gate, system, slice string, value, threshold float64, format string, args ...interface{},
+) Result {
+	r := Result{
+		Gate:      gate,
+		System:    system,
+		Slice:     slice,
+		Value:     value,
+		Threshold: threshold,
+		Passed:    value <= threshold,
+	}
+
+	msg := fmt.Sprintf(format, args...)
+
+	if r.Passed {
+		r.Message = fmt.Sprintf("%s: %s, within threshold of %.2f", gate, msg, threshold)
+	} else {
+		r.Message = fmt.Sprintf(
+			"%s: %s, exceeding threshold of %.2f by %.2f", gate, msg, threshold, value-threshold,
+		)
+	}
+
+	return r
+}
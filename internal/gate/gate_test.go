@@ -0,0 +1,172 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package gate
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	floatPtr := func(v float64) *float64 { return &v }
+
+	// The run has a WER of 22.22%, up from 16.67% in the baseline. Utterances
+	// of spk1 have a WER of 9.09% and those of spk2 42.86%.
+	testCases := []struct {
+		name       string
+		cfg        Config
+		wantPassed []bool
+	}{
+		{
+			name:       "MaxWERPassed",
+			cfg:        Config{MaxWER: floatPtr(25)},
+			wantPassed: []bool{true},
+		},
+		{
+			name:       "MaxWERFailed",
+			cfg:        Config{MaxWER: floatPtr(20)},
+			wantPassed: []bool{false},
+		},
+		{
+			// A maximum of zero is checked, allowing no errors.
+			name:       "MaxWERZero",
+			cfg:        Config{MaxWER: floatPtr(0)},
+			wantPassed: []bool{false},
+		},
+		{
+			name:       "NoGates",
+			cfg:        Config{},
+			wantPassed: []bool{},
+		},
+		{
+			name: "Slices",
+			cfg: Config{Slices: []Slice{
+				{Pattern: "^spk1-", Max: 10},
+				{Pattern: "^spk2-", Max: 40},
+				{Pattern: "^spk3-", Max: 100},
+			}},
+			wantPassed: []bool{true, false, false},
+		},
+		{
+			name:       "RegressionPassed",
+			cfg:        Config{BaselineDir: "testdata/baseline", MaxRegression: 6},
+			wantPassed: []bool{true},
+		},
+		{
+			name:       "RegressionFailed",
+			cfg:        Config{BaselineDir: "testdata/baseline", MaxRegression: 5},
+			wantPassed: []bool{false},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			results, err := Check(tc.cfg, false, []string{"testdata/run/hyp1.trn.pra.json"})
+
+			gotPassed := make([]bool, 0, len(results))
+			allPassed := true
+
+			for _, r := range results {
+				gotPassed = append(gotPassed, r.Passed)
+				allPassed = allPassed && r.Passed
+			}
+
+			if diff := cmp.Diff(tc.wantPassed, gotPassed); diff != "" {
+				subT.Errorf("unexpected gate results, (-want, +got):\n%s", diff)
+			}
+
+			var failedErr *FailedError
+			if allPassed && err != nil {
+				subT.Errorf("got unexpected error, want=nil, got=%v", err)
+			} else if !allPassed && !errors.As(err, &failedErr) {
+				subT.Errorf("expected *FailedError, got=%v", err)
+			}
+		})
+	}
+}
+
+func TestParseSlice(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		arg     string
+		want    Slice
+		wantErr bool
+	}{
+		{arg: "^spk1-:20", want: Slice{Pattern: "^spk1-", Max: 20}},
+		{arg: "(?i:spk):12.5", want: Slice{Pattern: "(?i:spk)", Max: 12.5}},
+		{arg: "spk1", wantErr: true},
+		{arg: ":20", wantErr: true},
+		{arg: "spk1:high", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.arg, func(subT *testing.T) {
+			subT.Parallel()
+
+			got, err := ParseSlice(tc.arg)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected slice, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	results := []Result{
+		{Gate: GateMaxWER, System: "hyp1", Passed: true, Message: "max-wer: WER is 10.00%"},
+		{Gate: GateMaxSlice, System: "hyp1", Slice: "^spk1-", Message: "max-slice: WER is 30.00%"},
+	}
+
+	filePath := path.Join(t.TempDir(), "gates.xml")
+	if err := WriteJUnit(filePath, results); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("failed to read JUnit report: %v", err)
+	}
+
+	for _, want := range []string{
+		`<testsuite name="sctk-gates" tests="2" failures="1">`,
+		`<testcase classname="sctk-gates.hyp1" name="max-slice[^spk1-]">`,
+		`<failure message="max-slice: WER is 30.00%" type="max-slice"></failure>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected JUnit report to contain %q, got:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package gate
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// junitSuiteName is the name of the test suite in JUnit XML reports.
+const junitSuiteName = "sctk-gates"
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+}
+
+// WriteJUnit writes the given results to filePath as a JUnit XML report, with
+// one test case per gate and system, so that CI dashboards can show them.
+func WriteJUnit(filePath string, results []Result) error {
+	suite := junitTestSuite{Name: junitSuiteName, Tests: len(results)}
+
+	for _, r := range results {
+		name := r.Gate
+		if r.Slice != "" {
+			name = fmt.Sprintf("%s[%s]", r.Gate, r.Slice)
+		}
+
+		tc := junitTestCase{ClassName: junitSuiteName + "." + r.System, Name: name}
+
+		if r.Passed {
+			tc.SystemOut = r.Message
+		} else {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: r.Message, Type: r.Gate}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	data, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+
+	data = append([]byte(xml.Header), data...)
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	return nil
+}
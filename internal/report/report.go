@@ -0,0 +1,421 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package report renders reports from the alignments of earlier scoring runs,
+// optionally restricted to a subset of sentences.
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Extensions of the alignment files that can be read.
+const (
+	sgmlExt = ".sgml"
+	jsonExt = ".pra.json"
+
+	filePerm = 0777
+)
+
+// Filter selects the sentences included in reports. Filters are combined, e.g.
+// the worst sentences are picked among those of the selected speakers.
+type Filter struct {
+	// ErrorsOnly keeps only sentences with at least one error.
+	ErrorsOnly bool
+
+	// Speakers keeps only sentences of the given speakers, if not empty.
+	Speakers []string
+
+	// Worst keeps only the given number of sentences with the most errors, ties
+	// broken by error rate, if > 0.
+	Worst int
+}
+
+// Select returns the IDs of the sentences of the given hypothesis that pass
+// the filter.
+func (f *Filter) Select(a *sctk.AlignedHypothesis) map[string]bool {
+	speakers := make(map[string]bool, len(f.Speakers))
+	for _, spk := range f.Speakers {
+		speakers[spk] = true
+	}
+
+	type candidate struct {
+		id      string
+		metrics compare.Metrics
+	}
+
+	candidates := make([]candidate, 0)
+
+	for spk, sents := range a.Speakers {
+		if len(speakers) != 0 && !speakers[spk] {
+			continue
+		}
+
+		for id, s := range sents {
+			m := compare.SentenceMetrics(s)
+			if f.ErrorsOnly && m.Errors == 0 {
+				continue
+			}
+
+			candidates = append(candidates, candidate{id: id, metrics: m})
+		}
+	}
+
+	if f.Worst > 0 && len(candidates) > f.Worst {
+		sort.Slice(candidates, func(i, j int) bool {
+			mi, mj := candidates[i].metrics, candidates[j].metrics
+			if mi.Errors != mj.Errors {
+				return mi.Errors > mj.Errors
+			}
+
+			if mi.ErrorRate != mj.ErrorRate {
+				return mi.ErrorRate > mj.ErrorRate
+			}
+
+			return candidates[i].id < candidates[j].id
+		})
+
+		candidates = candidates[:f.Worst]
+	}
+
+	selected := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		selected[c.id] = true
+	}
+
+	return selected
+}
+
+// Apply returns a copy of the given hypothesis holding only the sentences that
+// pass the filter. Speakers left without sentences are dropped.
+func (f *Filter) Apply(a *sctk.AlignedHypothesis) *sctk.AlignedHypothesis {
+	selected := f.Select(a)
+	filtered := &sctk.AlignedHypothesis{
+		SystemName: a.SystemName,
+		Speakers:   make(map[string]sctk.SpeakerSentences),
+	}
+
+	for spk, sents := range a.Speakers {
+		for id, s := range sents {
+			if !selected[id] {
+				continue
+			}
+
+			if _, ok := filtered.Speakers[spk]; !ok {
+				filtered.Speakers[spk] = make(sctk.SpeakerSentences)
+			}
+
+			filtered.Speakers[spk][id] = s
+		}
+	}
+
+	return filtered
+}
+
+// Input holds the alignments of one system read from an sgml or .pra.json
+// file.
+type Input struct {
+	// Name is the name of the file without the extension, naming the reports
+	// generated from it.
+	Name string
+
+	// FilePath is the path to the file the alignments were read from.
+	FilePath string
+
+	// SgmlFile is the path to the sgml file, or empty if the alignments were read
+	// from a .pra.json file. Only sgml files can be used to generate sclite
+	// reports.
+	SgmlFile string
+
+	Aligned *sctk.AlignedHypothesis
+}
+
+// LoadInputs reads the alignments in the given sgml or .pra.json files. Paths
+// to directories, such as the output directory of the score subcommand, are
+// replaced by the sgml files within, or the .pra.json files if there are none.
+func LoadInputs(paths []string) ([]Input, error) {
+	files := make([]string, 0, len(paths))
+
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		dirFiles, err := findAlignmentFiles(p)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, dirFiles...)
+	}
+
+	inputs := make([]Input, 0, len(files))
+	names := make(map[string]string, len(files))
+
+	for _, f := range files {
+		in, err := loadInput(f)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := names[in.Name]; ok {
+			return nil, fmt.Errorf("inputs %q and %q would write reports with the same name", other, f)
+		}
+
+		names[in.Name] = f
+		inputs = append(inputs, in)
+	}
+
+	return inputs, nil
+}
+
+// findAlignmentFiles returns the sgml files in the given directory, or the
+// .pra.json files if there are none.
+func findAlignmentFiles(dir string) ([]string, error) {
+	for _, ext := range []string{sgmlExt, jsonExt} {
+		files, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alignment files: %w", err)
+		}
+
+		if len(files) != 0 {
+			return files, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %s or %s alignment files found in %q", sgmlExt, jsonExt, dir)
+}
+
+// loadInput reads the alignments in the given sgml or .pra.json file.
+func loadInput(filePath string) (Input, error) {
+	base := filepath.Base(filePath)
+
+	switch {
+	case strings.HasSuffix(base, sgmlExt):
+		aligned, err := sctk.ReadAlignmentSgml(filePath)
+		if err != nil {
+			return Input{}, fmt.Errorf("failed to read alignment file %q: %w", filePath, err)
+		}
+
+		return Input{
+			Name:     strings.TrimSuffix(base, sgmlExt),
+			FilePath: filePath,
+			SgmlFile: filePath,
+			Aligned:  aligned,
+		}, nil
+
+	case strings.HasSuffix(base, jsonExt):
+		aligned, err := sctk.ReadAlignmentJSON(filePath)
+		if err != nil {
+			return Input{}, err
+		}
+
+		return Input{
+			Name:     strings.TrimSuffix(base, jsonExt),
+			FilePath: filePath,
+			Aligned:  aligned,
+		}, nil
+
+	default:
+		return Input{}, fmt.Errorf("unsupported alignment file %q, expected %s or %s", filePath, sgmlExt, jsonExt)
+	}
+}
+
+// Config for rendering reports.
+type Config struct {
+	// Sclite configures the sclite reports, generated from sgml inputs, and the
+	// line width and encoding used for them. The alignment formats are ignored.
+	Sclite sctk.ScliteCfg
+
+	// AlignmentFormats are the formats the alignments are written in, one of
+	// the sctk.AlignmentFormat* values other than none.
+	AlignmentFormats []string
+
+	Filter Filter
+}
+
+// Render writes the configured reports for each input to outDir, named after
+// the input. The alignments are written as <name>.pra.<format>, and the sclite
+// reports as <name>.<report>.
+func Render(ctx context.Context, cfg Config, inputs []Input, outDir string) error {
+	if len(cfg.Sclite.Reports) != 0 {
+		for _, in := range inputs {
+			if in.SgmlFile == "" {
+				return fmt.Errorf("sclite reports can only be generated from sgml files, got %q", in.FilePath)
+			}
+		}
+	}
+
+	if err := checkOutDir(inputs, outDir); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, in := range inputs {
+		filtered := cfg.Filter.Apply(in.Aligned)
+
+		log.WithFields(log.Fields{
+			"input":     in.FilePath,
+			"sentences": numSentences(filtered),
+		}).Info("rendering reports")
+
+		if err := sctk.WriteAlignments(filepath.Join(outDir, in.Name), filtered, cfg.AlignmentFormats); err != nil {
+			return fmt.Errorf("failed to write alignments: %w", err)
+		}
+
+		if len(cfg.Sclite.Reports) != 0 {
+			if err := renderScliteReports(ctx, cfg, in, outDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Print writes the alignments of each input to w in the given format.
+func Print(w io.Writer, format string, filter Filter, inputs []Input) error {
+	for _, in := range inputs {
+		filtered := filter.Apply(in.Aligned)
+
+		if format == sctk.AlignmentFormatJSON {
+			data, err := json.MarshalIndent(filtered, "", " ")
+			if err != nil {
+				return fmt.Errorf("failed to encode alignments: %w", err)
+			}
+
+			if _, err := fmt.Fprintln(w, string(data)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tableFormat, ok := tableFormats[format]
+		if !ok {
+			return fmt.Errorf("unsupported alignment format %q", format)
+		}
+
+		if _, err := io.WriteString(w, filtered.ToTable(tableFormat)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tableFormats maps alignment formats to the table formats rendering them.
+var tableFormats = map[string]sctk.TableFormat{
+	sctk.AlignmentFormatMarkdown: sctk.TableFormatMarkdown,
+	sctk.AlignmentFormatHTML:     sctk.TableFormatHTML,
+	sctk.AlignmentFormatCSV:      sctk.TableFormatCSV,
+	sctk.AlignmentFormatTxt:      sctk.TableFormatTxt,
+}
+
+// renderScliteReports writes the sentences of the input that pass the filter
+// to a temporary sgml file, and pipes it through sclite to generate the
+// configured reports.
+func renderScliteReports(ctx context.Context, cfg Config, in Input, outDir string) error {
+	tmpDir, err := os.MkdirTemp("", "sctk-report-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	selected := cfg.Filter.Select(in.Aligned)
+	sgmlFile := filepath.Join(tmpDir, in.Name+sgmlExt)
+
+	if _, err := sctk.FilterSgml(in.SgmlFile, sgmlFile, func(_, sentenceID string) bool {
+		return selected[sentenceID]
+	}); err != nil {
+		return err
+	}
+
+	if err := sctk.GenerateReports(ctx, cfg.Sclite, outDir, sgmlFile); err != nil {
+		return fmt.Errorf("failed to generate sclite reports: %w", err)
+	}
+
+	for _, r := range cfg.Sclite.Reports {
+		if r != "sgml" {
+			continue
+		}
+
+		data, err := os.ReadFile(sgmlFile)
+		if err != nil {
+			return fmt.Errorf("failed to read filtered sgml file: %w", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(outDir, in.Name+sgmlExt), data, 0644); err != nil { //nolint: gomnd // file permissions.
+			return fmt.Errorf("failed to write filtered sgml file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkOutDir returns an error if outDir is the directory of any of the
+// inputs, where reports would overwrite the files of the run they were read
+// from.
+func checkOutDir(inputs []Input, outDir string) error {
+	outInfo, err := os.Stat(outDir)
+	if err != nil {
+		// The output directory does not exist yet.
+		return nil //nolint: nilerr // nothing to overwrite.
+	}
+
+	for _, in := range inputs {
+		inInfo, err := os.Stat(filepath.Dir(in.FilePath))
+		if err != nil {
+			return fmt.Errorf("failed to read input directory: %w", err)
+		}
+
+		if os.SameFile(inInfo, outInfo) {
+			return fmt.Errorf("reports would overwrite files next to input %q, choose a different output directory", in.FilePath)
+		}
+	}
+
+	return nil
+}
+
+func numSentences(a *sctk.AlignedHypothesis) int {
+	n := 0
+	for _, sents := range a.Speakers {
+		n += len(sents)
+	}
+
+	return n
+}
@@ -0,0 +1,131 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package report
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestFilterSelect(t *testing.T) {
+	t.Parallel()
+
+	inputs, err := LoadInputs([]string{"testdata"})
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	if len(inputs) != 1 || inputs[0].Name != "hyp1.trn" {
+		t.Fatalf("expected input hyp1.trn to be loaded, got %+v", inputs)
+	}
+
+	testCases := []struct {
+		name   string
+		filter Filter
+		want   map[string]bool
+	}{
+		{
+			name:   "All",
+			filter: Filter{},
+			want:   map[string]bool{"(spk1-u1)": true, "(spk1-u2)": true, "(spk2-u3)": true, "(spk2-u4)": true},
+		},
+		{
+			name:   "ErrorsOnly",
+			filter: Filter{ErrorsOnly: true},
+			want:   map[string]bool{"(spk1-u2)": true, "(spk2-u4)": true},
+		},
+		{
+			name:   "Speaker",
+			filter: Filter{Speakers: []string{"spk2"}},
+			want:   map[string]bool{"(spk2-u3)": true, "(spk2-u4)": true},
+		},
+		{
+			name:   "Worst",
+			filter: Filter{Worst: 1},
+			want:   map[string]bool{"(spk1-u2)": true},
+		},
+		{
+			name:   "WorstOfSpeaker",
+			filter: Filter{Speakers: []string{"spk2"}, Worst: 1},
+			want:   map[string]bool{"(spk2-u4)": true},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got := tc.filter.Select(inputs[0].Aligned)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected sentences selected, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	inputs, err := LoadInputs([]string{"testdata/hyp1.trn.sgml"})
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	outDir := t.TempDir()
+	cfg := Config{
+		Sclite: sctk.ScliteCfg{
+			LineWidth: 1000, Encoding: "utf-8", Reports: []string{"sgml"},
+		},
+		AlignmentFormats: []string{sctk.AlignmentFormatJSON},
+		Filter:           Filter{ErrorsOnly: true},
+	}
+
+	if err := Render(context.Background(), cfg, inputs, outDir); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	// The filtered sgml and json alignments should hold the same sentences.
+	want := map[string][]string{"spk1": {"(spk1-u2)"}, "spk2": {"(spk2-u4)"}}
+
+	for _, f := range []string{"hyp1.trn.sgml", "hyp1.trn.pra.json"} {
+		reloaded, err := LoadInputs([]string{filepath.Join(outDir, f)})
+		if err != nil {
+			t.Fatalf("failed to read rendered %s: %v", f, err)
+		}
+
+		got := make(map[string][]string)
+		for spk, sents := range reloaded[0].Aligned.Speakers {
+			for id := range sents {
+				got[spk] = append(got[spk], id)
+			}
+		}
+
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected sentences in rendered %s, (-want, +got):\n%s", f, diff)
+		}
+	}
+
+	if err := Render(context.Background(), cfg, inputs, "testdata"); err == nil {
+		t.Errorf("expected error when rendering next to the inputs, got nil")
+	}
+}
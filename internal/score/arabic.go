@@ -0,0 +1,144 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"strings"
+)
+
+// Language profiles selecting language specific normalization rules.
+const (
+	// LanguageArabic normalizes Arabic text: alef variants carrying hamza or
+	// madda are unified with the bare alef, and tatweel and tanween are removed.
+	LanguageArabic = "ar"
+	// LanguageUrdu normalizes Urdu text written in the Arabic script: alef
+	// variants carrying hamza are unified with the bare alef, keeping alef madda
+	// which is a letter of its own in Urdu, tatweel is removed, and the Arabic
+	// forms of yeh, kaf and heh that are often typed in place of their Urdu
+	// forms are replaced by them.
+	LanguageUrdu = "ur"
+)
+
+var (
+	// arabicAlefReplacer unifies alef variants carrying hamza or madda, precomposed
+	// or followed by combining marks, and alef wasla with the bare alef.
+	arabicAlefReplacer = strings.NewReplacer(
+		"\u0627\u0653", "\u0627", // Alef + combining madda.
+		"\u0627\u0654", "\u0627", // Alef + combining hamza above.
+		"\u0627\u0655", "\u0627", // Alef + combining hamza below.
+		"\u0622", "\u0627", // Alef with madda above.
+		"\u0623", "\u0627", // Alef with hamza above.
+		"\u0625", "\u0627", // Alef with hamza below.
+		"\u0671", "\u0627", // Alef wasla.
+	)
+
+	// urduAlefReplacer is like arabicAlefReplacer, but keeps alef madda.
+	urduAlefReplacer = strings.NewReplacer(
+		"\u0627\u0654", "\u0627", // Alef + combining hamza above.
+		"\u0627\u0655", "\u0627", // Alef + combining hamza below.
+		"\u0623", "\u0627", // Alef with hamza above.
+		"\u0625", "\u0627", // Alef with hamza below.
+		"\u0671", "\u0627", // Alef wasla.
+	)
+
+	// urduLetterReplacer replaces the Arabic forms of yeh, kaf and heh with their
+	// Urdu forms.
+	urduLetterReplacer = strings.NewReplacer(
+		"\u064A", "\u06CC", // Arabic yeh to farsi yeh.
+		"\u0649", "\u06CC", // Alef maqsura to farsi yeh.
+		"\u0643", "\u06A9", // Arabic kaf to keheh.
+		"\u0647", "\u06C1", // Arabic heh to heh goal.
+	)
+)
+
+const (
+	tatweel     = '\u0640'
+	taMarbuta   = '\u0629'
+	alefMaqsura = '\u0649'
+	arabicHeh   = '\u0647'
+	arabicYeh   = '\u064A'
+	urduHehGoal = '\u06C1'
+)
+
+// isTanween returns true if the given rune is one of the tanween marks: fathatan,
+// dammatan or kasratan.
+func isTanween(r rune) bool {
+	return r >= '\u064B' && r <= '\u064D'
+}
+
+// isHaraka returns true if the given rune is an Arabic diacritic: the harakat
+// and tanween, shadda, sukun, superscript alef, and the Quranic annotation
+// marks.
+func isHaraka(r rune) bool {
+	switch {
+	case r >= '\u0610' && r <= '\u061A',
+		r >= '\u064B' && r <= '\u065F',
+		r == '\u0670',
+		r >= '\u06D6' && r <= '\u06DC',
+		r >= '\u06DF' && r <= '\u06E4',
+		r >= '\u06E7' && r <= '\u06E8',
+		r >= '\u06EA' && r <= '\u06ED':
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeArabicScript applies the normalization rules of the given language
+// profile, LanguageArabic or LanguageUrdu, to the given text, followed by the
+// optional rules enabled in cfg:
+//
+//   - TaMarbutaToHa replaces ta marbuta (ة) with heh (ه), or heh goal (ہ) in
+//     Urdu, since the two are often confused at the end of words.
+//   - AlefMaqsuraToYa replaces alef maqsura (ى) with yeh (ي). In Urdu, both are
+//     always replaced with farsi yeh (ی).
+//   - StripDiacritics removes all diacritics (harakat), which are rarely
+//     written consistently. Tanween is always removed in Arabic.
+//
+// Text in other scripts is left as is.
+func normalizeArabicScript(s string, cfg NormalizeConfig) string {
+	switch cfg.Language {
+	case LanguageArabic:
+		s = arabicAlefReplacer.Replace(s)
+	case LanguageUrdu:
+		s = urduAlefReplacer.Replace(s)
+		s = urduLetterReplacer.Replace(s)
+	default:
+		return s
+	}
+
+	heh := arabicHeh
+	if cfg.Language == LanguageUrdu {
+		heh = urduHehGoal
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == tatweel:
+			return -1
+		case cfg.StripDiacritics && isHaraka(r):
+			return -1
+		case cfg.Language == LanguageArabic && isTanween(r):
+			return -1
+		case cfg.TaMarbutaToHa && r == taMarbuta:
+			return heh
+		case cfg.AlefMaqsuraToYa && r == alefMaqsura:
+			return arabicYeh
+		default:
+			return r
+		}
+	}, s)
+}
@@ -0,0 +1,152 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"testing"
+)
+
+func TestNormalizeArabicScript(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		text string
+		cfg  NormalizeConfig
+		want string
+	}{
+		{
+			name: "noLanguage",
+			text: "أحمد إلى آخر",
+			cfg:  NormalizeConfig{CaseSensitive: true},
+			want: "أحمد إلى آخر",
+		},
+		{
+			name: "arabicAlef",
+			text: "أحمد إلى آخر ٱلكتاب",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageArabic},
+			want: "احمد الى اخر الكتاب",
+		},
+		{
+			// Alef followed by combining hamza above and below, and madda.
+			name: "arabicDecomposedAlef",
+			text: "\u0627\u0654\u062D\u0645\u062F \u0627\u0655\u0644\u0649 \u0627\u0653\u062E\u0631",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageArabic},
+			want: "احمد الى اخر",
+		},
+		{
+			name: "arabicDecomposedAlefNFC",
+			text: "\u0627\u0654\u062D\u0645\u062F",
+			cfg:  NormalizeConfig{NormalizeUnicode: true, Language: LanguageArabic},
+			want: "احمد",
+		},
+		{
+			name: "arabicTatweelTanween",
+			text: "جمـــيل شكراً كتابٌ",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageArabic},
+			want: "جميل شكرا كتاب",
+		},
+		{
+			name: "arabicKeepsHarakat",
+			text: "كَتَبَ",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageArabic},
+			want: "كَتَبَ",
+		},
+		{
+			name: "arabicStripDiacritics",
+			text: "كَتَبَ مُدَرِّسٌ عَلَىٰ",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageArabic, StripDiacritics: true},
+			want: "كتب مدرس على",
+		},
+		{
+			name: "arabicTaMarbutaAlefMaqsura",
+			text: "مدرسة على",
+			cfg: NormalizeConfig{
+				CaseSensitive: true, Language: LanguageArabic, TaMarbutaToHa: true, AlefMaqsuraToYa: true,
+			},
+			want: "مدرسه علي",
+		},
+		{
+			name: "urdu",
+			text: "آپ كيسے ہيں؟ یہ كتاب ہے",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageUrdu},
+			want: "آپ کیسے ہیں؟ یہ کتاب ہے",
+		},
+		{
+			name: "urduHehTaMarbutaAlefMaqsura",
+			text: "مدرسة عيسى اللہ الله",
+			cfg: NormalizeConfig{
+				CaseSensitive: true, Language: LanguageUrdu, TaMarbutaToHa: true, AlefMaqsuraToYa: true,
+			},
+			want: "مدرسہ عیسی اللہ اللہ",
+		},
+		{
+			name: "urduKeepsTanween",
+			text: "فوراً",
+			cfg:  NormalizeConfig{CaseSensitive: true, Language: LanguageUrdu},
+			want: "فوراً",
+		},
+		{
+			name: "otherScripts",
+			text: "hello এর মূল্য",
+			cfg:  NormalizeConfig{Language: LanguageArabic, StripDiacritics: true, TaMarbutaToHa: true},
+			want: "hello এর মূল্য",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			if err := tc.cfg.Validate(); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			if got := normalizeText(tc.text, tc.cfg); got != tc.want {
+				subT.Errorf("unexpected normalized text, want=%q, got=%q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		cfg     NormalizeConfig
+		wantErr bool
+	}{
+		{name: "empty", cfg: NormalizeConfig{}},
+		{name: "urdu", cfg: NormalizeConfig{Language: LanguageUrdu, StripDiacritics: true}},
+		{name: "unsupportedLanguage", cfg: NormalizeConfig{Language: "fa"}, wantErr: true},
+		{name: "ruleWithoutLanguage", cfg: NormalizeConfig{TaMarbutaToHa: true}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			if err := tc.cfg.Validate(); (err != nil) != tc.wantErr {
+				subT.Errorf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// roverNullWord is the word rover outputs when the vote is for no word.
+const roverNullWord = "@"
+
+// Combine fuses the given hypotheses into a single hypothesis using rover. The
+// hypotheses are read and normalized the same way as when scoring, converted to
+// the CTM format if needed and combined by word-level voting. The combined
+// hypothesis is written to the output directory both in the CTM format, and in
+// the provided file format so that it can be scored like any other hypothesis
+// file. The returned Hypothesis points to the latter. The name of the combined
+// hypothesis must differ from those of the given hypotheses once sanitized,
+// since their ctm files are written to the same directory.
+func Combine(
+	ctx context.Context, fileFormat FileFormat, normCfg NormalizeConfig, roverCfg sctk.RoverCfg,
+	outDir, name string, hypFiles []sctk.Hypothesis,
+) (sctk.Hypothesis, error) {
+	const (
+		filePerm = 0777
+	)
+
+	names := make([]string, 0, len(hypFiles)+1)
+	for _, hyp := range hypFiles {
+		names = append(names, hyp.SystemName)
+	}
+
+	if err := ValidateSystemNames(append(names, name)); err != nil {
+		return sctk.Hypothesis{}, err
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return sctk.Hypothesis{}, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctmFiles := make([]sctk.Hypothesis, 0, len(hypFiles))
+	hypWords := make([][]TimedWord, 0, len(hypFiles))
+
+	for _, hyp := range hypFiles {
+		words, err := readHypWords(ctx, fileFormat, normCfg, hyp.FilePath)
+		if err != nil {
+			return sctk.Hypothesis{}, fmt.Errorf("failed to read hypothesis file: %w", err)
+		}
+
+		words = normalizeTimedWords(words, normCfg)
+		hypWords = append(hypWords, words)
+
+		sanitizedName := SanitizeSystemName(hyp.SystemName)
+		ctmFile := path.Join(outDir, sanitizedName+".ctm")
+
+		if err := writeCtmFile(ctx, words, ctmFile); err != nil {
+			return sctk.Hypothesis{}, fmt.Errorf("failed to write hypothesis ctm file: %w", err)
+		}
+
+		ctmFiles = append(ctmFiles, sctk.Hypothesis{SystemName: sanitizedName, FilePath: ctmFile})
+	}
+
+	sanitizedName := SanitizeSystemName(name)
+	combinedCtm := path.Join(outDir, sanitizedName+".ctm")
+
+	if err := sctk.RunRover(ctx, roverCfg, combinedCtm, ctmFiles); err != nil {
+		return sctk.Hypothesis{}, err
+	}
+
+	words, err := readCtmFile(ctx, combinedCtm)
+	if err != nil {
+		return sctk.Hypothesis{}, fmt.Errorf("failed to read combined ctm file: %w", err)
+	}
+
+	utts := combinedUtts(hypWords, words)
+
+	combinedFile := path.Join(outDir, sanitizedName+fileFormat.extension())
+	if err := writeDelimitedFile(ctx, utts, fileFormat, combinedFile); err != nil {
+		return sctk.Hypothesis{}, fmt.Errorf("failed to write combined hypothesis file: %w", err)
+	}
+
+	return sctk.Hypothesis{SystemName: sanitizedName, FilePath: combinedFile}, nil
+}
+
+// combinedUtts returns the utterances of the combined hypothesis, made of the
+// words output by rover without the null words it inserts. Utterances are in
+// the order they first appear in the given hypotheses, so that utterances
+// missing from some of them are kept; those for which rover output no words
+// have empty transcripts.
+func combinedUtts(hypWords [][]TimedWord, roverWords []TimedWord) []Utt {
+	uttIDs := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, words := range hypWords {
+		for _, utt := range wordsToUtts(words) {
+			if _, ok := seen[utt.ID]; !ok {
+				seen[utt.ID] = struct{}{}
+				uttIDs = append(uttIDs, utt.ID)
+			}
+		}
+	}
+
+	words := make([]TimedWord, 0, len(roverWords))
+	for _, w := range roverWords {
+		if w.Word != roverNullWord {
+			words = append(words, w)
+		}
+	}
+
+	combined := make(map[string]string)
+	for _, utt := range wordsToUtts(words) {
+		combined[utt.ID] = utt.Transcript
+	}
+
+	utts := make([]Utt, 0, len(uttIDs))
+	for _, id := range uttIDs {
+		utts = append(utts, Utt{ID: id, Transcript: combined[id]})
+	}
+
+	return utts
+}
+
+// extension returns the file extension commonly used for files with the
+// delimiter of the file format.
+func (f *FileFormat) extension() string {
+	switch f.Delimiter {
+	case ',':
+		return ".csv"
+	case '\t':
+		return ".tsv"
+	default:
+		return ".txt"
+	}
+}
+
+// writeDelimitedFile writes the provided list of utterances to the given path in
+// the provided file format, so that it can be read back by readTranscriptFile.
+func writeDelimitedFile(
+	ctx context.Context, utts []Utt, fileFormat FileFormat, filePath string,
+) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output transcript file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	numCols := fileFormat.ColTrn
+	if numCols < fileFormat.ColID {
+		numCols = fileFormat.ColID
+	}
+
+	numCols++
+
+	w := csv.NewWriter(f)
+	w.Comma = fileFormat.Delimiter
+
+	if fileFormat.IgnoreFirstRow {
+		header := make([]string, numCols)
+		for i := range header {
+			header[i] = "col" + strconv.Itoa(i)
+		}
+
+		header[fileFormat.ColID], header[fileFormat.ColTrn] = "utterance_id", "transcript"
+
+		if err := w.Write(header); err != nil {
+			return fmt.Errorf("failed to write header to transcript file: %w", err)
+		}
+	}
+
+	for _, utt := range utts {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		row := make([]string, numCols)
+		row[fileFormat.ColID], row[fileFormat.ColTrn] = utt.ID, utt.Transcript
+
+		if err := w.Write(row); err != nil {
+			return fmt.Errorf("failed to write line to transcript file: %w", err)
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
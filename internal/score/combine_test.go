@@ -0,0 +1,178 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+func TestCombinedUtts(t *testing.T) {
+	t.Parallel()
+
+	word := func(file string, start float64, w string) TimedWord {
+		return TimedWord{File: file, Channel: "1", Start: start, Duration: 0.1, Word: w, Conf: "1"}
+	}
+
+	testCases := []struct {
+		name       string
+		hypWords   [][]TimedWord
+		roverWords []TimedWord
+		want       []Utt
+	}{
+		{
+			name: "sameUtterances",
+			hypWords: [][]TimedWord{
+				{word("spk1-u1", 0, "a"), word("spk1-u2", 0, "b")},
+				{word("spk1-u1", 0, "a"), word("spk1-u2", 0, "c")},
+			},
+			roverWords: []TimedWord{word("spk1-u1", 0, "a"), word("spk1-u2", 0, "b")},
+			want: []Utt{
+				{ID: "spk1-u1", Transcript: "a"},
+				{ID: "spk1-u2", Transcript: "b"},
+			},
+		},
+		{
+			// Utterances missing from the first hypothesis are kept, in the
+			// order they first appear in the others.
+			name: "differentUtterances",
+			hypWords: [][]TimedWord{
+				{word("spk1-u2", 0, "b")},
+				{word("spk1-u1", 0, "a"), word("spk1-u2", 0, "b")},
+				{word("spk1-u3", 0, "c"), word("spk1-u1", 0, "a")},
+			},
+			roverWords: []TimedWord{
+				word("spk1-u1", 0, "a"), word("spk1-u2", 0, "b"), word("spk1-u3", 0, "c"),
+			},
+			want: []Utt{
+				{ID: "spk1-u2", Transcript: "b"},
+				{ID: "spk1-u1", Transcript: "a"},
+				{ID: "spk1-u3", Transcript: "c"},
+			},
+		},
+		{
+			// Null words are dropped, and utterances left without any words
+			// have empty transcripts.
+			name: "nullWords",
+			hypWords: [][]TimedWord{
+				{word("spk1-u1", 0, "a"), word("spk1-u1", 0.2, "b")},
+				{word("spk1-u1", 0, "a"), word("spk1-u2", 0, "c")},
+			},
+			roverWords: []TimedWord{
+				word("spk1-u1", 0.2, "b"),
+				word("spk1-u1", 0, "a"),
+				word("spk1-u2", 0, roverNullWord),
+			},
+			want: []Utt{
+				{ID: "spk1-u1", Transcript: "a b"},
+				{ID: "spk1-u2", Transcript: ""},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got := combinedUtts(tc.hypWords, tc.roverWords)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(Utt{})); diff != "" {
+				subT.Errorf("unexpected combined utterances, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	t.Parallel()
+
+	const hypFile = "testdata/validate/good1_hyp.csv"
+
+	testCases := []struct {
+		name     string
+		combined string
+		hypFiles []sctk.Hypothesis
+	}{
+		{
+			name:     "nameClash",
+			combined: "rover",
+			hypFiles: []sctk.Hypothesis{{SystemName: "Rover", FilePath: hypFile}, {SystemName: "b", FilePath: hypFile}},
+		},
+		{
+			name:     "duplicateHyps",
+			combined: "rover",
+			hypFiles: []sctk.Hypothesis{{SystemName: "sys a", FilePath: hypFile}, {SystemName: "SYS A", FilePath: hypFile}},
+		},
+		{
+			name:     "reservedName",
+			combined: "ref",
+			hypFiles: []sctk.Hypothesis{{SystemName: "a", FilePath: hypFile}, {SystemName: "b", FilePath: hypFile}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			roverCfg := sctk.RoverCfg{Method: sctk.RoverFrequency}
+
+			_, err := Combine(
+				context.Background(), FileFormat{Delimiter: ',', ColTrn: 1}, NormalizeConfig{}, roverCfg,
+				outDir, tc.combined, tc.hypFiles,
+			)
+			if err == nil {
+				subT.Fatalf("did not get expected error, want=non-nil, got=nil")
+			}
+
+			// Nothing should have been written before the names were checked.
+			if entries, _ := os.ReadDir(outDir); len(entries) != 0 {
+				subT.Errorf("unexpected files written to output directory: %v", entries)
+			}
+		})
+	}
+}
+
+func TestCombineNotEmbedded(t *testing.T) {
+	t.Parallel()
+
+	if _, err := embedded.Rover(); err == nil {
+		t.Skip("rover is embedded")
+	}
+
+	hypFiles := []sctk.Hypothesis{
+		{SystemName: "a", FilePath: "testdata/validate/good1_hyp.csv"},
+		{SystemName: "b", FilePath: "testdata/validate/good1_hyp.csv"},
+	}
+
+	_, err := Combine(
+		context.Background(), FileFormat{Delimiter: ',', ColTrn: 1}, NormalizeConfig{},
+		sctk.RoverCfg{Method: sctk.RoverFrequency}, t.TempDir(), "rover", hypFiles,
+	)
+	if !errors.Is(err, embedded.ErrNotEmbedded) {
+		t.Errorf("unexpected error without rover, want=%v, got=%v", embedded.ErrNotEmbedded, err)
+	}
+}
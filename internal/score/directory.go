@@ -0,0 +1,151 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+)
+
+// readTranscriptDir reads the transcript files under the given directory, each
+// containing the transcript of one utterance. Files are read recursively in
+// lexical order, skipping hidden files and directories. The ID of each
+// utterance is derived from the path of the file relative to the given
+// directory, as described for FileFormat.IDPattern, so that files with the same
+// relative path in reference and hypothesis directories are matched with each
+// other.
+func readTranscriptDir(ctx context.Context, dir string, fileFormat FileFormat) ([]Utt, error) {
+	var idRe *regexp.Regexp
+
+	if fileFormat.IDPattern != "" {
+		var err error
+		if idRe, err = regexp.Compile(fileFormat.IDPattern); err != nil {
+			return nil, fmt.Errorf("invalid utterance ID pattern: %w", err)
+		}
+	}
+
+	utts := make([]Utt, 0)
+	paths := make(map[string]string)
+
+	walkFn := func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if filePath != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		ID, ok := uttIDFromPath(relPath, idRe)
+		if !ok {
+			return nil
+		}
+
+		if other, ok := paths[ID]; ok {
+			return fmt.Errorf(
+				"files %q and %q have the same utterance ID %q", other, relPath, ID,
+			)
+		}
+
+		f, err := fileutils.Open(filePath)
+		if err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(f)
+		fileutils.CloseOrLog(f, filePath)
+
+		if err != nil {
+			return err
+		}
+
+		paths[ID] = relPath
+		trn := strings.Join(strings.Fields(string(data)), " ")
+		utts = append(utts, Utt{ID: ID, Transcript: trn, rawID: relPath})
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(dir, walkFn); err != nil {
+		return nil, fmt.Errorf("failed to read transcript directory: %w", err)
+	}
+
+	return utts, nil
+}
+
+// uttIDFromPath derives the utterance ID from the given slash separated relative
+// path of a transcript file. If idRe is nil, the ID is the path without its
+// extension, ignoring the extensions of compressed files. Otherwise, the ID is
+// made up of the non-empty groups captured by idRe joined with dashes, or the
+// whole match if idRe has no groups; false is returned if the path does not
+// match. Slashes in the ID are replaced by dashes, so that directories act as
+// speaker IDs.
+func uttIDFromPath(relPath string, idRe *regexp.Regexp) (string, bool) {
+	ID := fileutils.TrimCompressedExt(relPath)
+	ID = strings.TrimSuffix(ID, path.Ext(ID))
+
+	if idRe != nil {
+		m := idRe.FindStringSubmatch(relPath)
+		if m == nil {
+			return "", false
+		}
+
+		ID = m[0]
+		if len(m) > 1 {
+			groups := make([]string, 0, len(m)-1)
+			for _, g := range m[1:] {
+				if g != "" {
+					groups = append(groups, g)
+				}
+			}
+
+			ID = strings.Join(groups, "-")
+		}
+	}
+
+	ID = sanitizeUttID(strings.ReplaceAll(ID, "/", "-"))
+
+	return ID, ID != ""
+}
@@ -0,0 +1,76 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestReadTranscriptDir(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		idPattern string
+		want      []Utt
+		wantErr   bool
+	}{
+		{
+			name: "default_pattern",
+			want: []Utt{
+				{ID: "spk1-utt1", Transcript: "hello world"},
+				{ID: "spk1-utt2", Transcript: "good morning"},
+				{ID: "spk2-utt1", Transcript: "how are you"},
+			},
+		},
+		{
+			name:      "pattern_with_groups",
+			idPattern: `^(spk\d)/(utt\d)\.txt$`,
+			want: []Utt{
+				{ID: "spk1-utt1", Transcript: "hello world"},
+				{ID: "spk1-utt2", Transcript: "good morning"},
+			},
+		},
+		{
+			name:      "duplicate_ids",
+			idPattern: `utt\d`,
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			fileFormat := FileFormat{IDPattern: tc.idPattern}
+
+			got, err := readTranscriptFile(context.Background(), "testdata/dir", fileFormat)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(Utt{})); diff != "" {
+				subT.Errorf("unexpected utterances, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,432 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+const (
+	// FormattingFile is the name of the file in the output directory of a
+	// scoring run to which case and punctuation error rates are written.
+	FormattingFile = "formatting.json"
+
+	// formattedExt is the extension of the transcript files written next to
+	// the normalized ones, holding the transcripts before case folding and
+	// stripping punctuation.
+	formattedExt = ".formatted.trn"
+)
+
+// PunctCounts are the counts of one or all punctuation marks in the reference
+// and a hypothesis. Recall, precision and F1 are percentages.
+type PunctCounts struct {
+	Mark string `json:"mark,omitempty"`
+
+	// Ref and Hyp are the numbers of marks in the reference and hypothesis.
+	// Hits are the marks found on the same side of aligned reference and
+	// hypothesis words.
+	Ref  int `json:"ref"`
+	Hyp  int `json:"hyp"`
+	Hits int `json:"hits"`
+
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+	F1        float64 `json:"f1"`
+}
+
+// FormattingMetrics are the case and punctuation error rates of a system, found
+// by projecting the transcripts before case folding and stripping punctuation
+// onto the alignments of the normalized transcripts.
+type FormattingMetrics struct {
+	System string `json:"system"`
+
+	// NumUtts is the number of utterances projected onto the alignments, and
+	// SkippedUtts the number whose words could not be matched to them.
+	NumUtts     int `json:"num_utterances"`
+	SkippedUtts int `json:"skipped_utterances"`
+
+	// CaseErrors is the number of correctly aligned words with the wrong case,
+	// and CaseErrorRate its percentage of all correctly aligned words.
+	CorrectWords  int     `json:"correct_words"`
+	CaseErrors    int     `json:"case_errors"`
+	CaseErrorRate float64 `json:"case_error_rate"`
+
+	// Punctuation are the counts of all punctuation marks, and Marks the counts
+	// of each, ordered by mark.
+	Punctuation PunctCounts   `json:"punctuation"`
+	Marks       []PunctCounts `json:"marks"`
+}
+
+// A formattedWord is a word of a transcript before case folding and stripping
+// punctuation, along with the punctuation marks before and after it.
+type formattedWord struct {
+	// word is the word without the punctuation around it, and norm the word as
+	// it appears in the alignments.
+	word string
+	norm string
+
+	before []rune
+	after  []rune
+}
+
+// stripPunctuation removes punctuation at the start and end of each word of the
+// given text, dropping words made of punctuation only.
+func stripPunctuation(s string) string {
+	words := strings.Fields(s)
+
+	n := 0
+	for _, w := range words {
+		if w = strings.TrimFunc(w, unicode.IsPunct); w != "" {
+			words[n] = w
+			n++
+		}
+	}
+
+	return strings.Join(words[:n], " ")
+}
+
+// formattedConfig returns the normalization config producing the transcripts
+// projected onto the alignments: all normalization steps are applied, except
+// case folding and stripping punctuation.
+func formattedConfig(cfg NormalizeConfig) NormalizeConfig {
+	cfg.CaseSensitive, cfg.StripPunctuation = true, false
+
+	return cfg
+}
+
+// writeFormattedFile writes the transcripts of the given utterances normalized
+// with formattedConfig to the given path, in the same format as the normalized
+// transcripts.
+func writeFormattedFile(ctx context.Context, utts []Utt, cfg NormalizeConfig, filePath string) error {
+	formatted := make([]Utt, len(utts))
+	copy(formatted, utts)
+
+	normalizeUtts(formatted, formattedConfig(cfg))
+
+	if err := writeTranscriptFile(ctx, formatted, filePath); err != nil {
+		return fmt.Errorf("failed to write formatted transcript file: %w", err)
+	}
+
+	return nil
+}
+
+// readFormattedFile reads the transcripts in a file written by
+// writeFormattedFile, indexed by utterance ID.
+func readFormattedFile(filePath string) (map[string]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read formatted transcript file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	trns := make(map[string]string)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		start := strings.LastIndex(line, "(")
+		if start < 0 || !strings.HasSuffix(line, ")") {
+			continue
+		}
+
+		trns[line[start+1:len(line)-1]] = strings.TrimSpace(line[:start])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read formatted transcript file: %w", err)
+	}
+
+	return trns, nil
+}
+
+// formattedWords splits the given formatted transcript into words, with the
+// punctuation marks around them. Words made of punctuation only are attached
+// as marks after the previous word, or before the next word at the start.
+func formattedWords(trn string, cfg NormalizeConfig) []formattedWord {
+	words := make([]formattedWord, 0)
+
+	var pending []rune
+
+	for _, tok := range strings.Fields(trn) {
+		w := formattedWord{norm: tok}
+
+		if !cfg.CaseSensitive {
+			w.norm = strings.ToLower(w.norm)
+		}
+
+		if cfg.StripPunctuation {
+			w.norm = strings.TrimFunc(w.norm, unicode.IsPunct)
+		}
+
+		w.word = strings.TrimFunc(tok, unicode.IsPunct)
+		start := strings.Index(tok, w.word)
+		w.before = []rune(tok[:start])
+		w.after = []rune(tok[start+len(w.word):])
+
+		if w.norm == "" {
+			if len(words) > 0 {
+				words[len(words)-1].after = append(words[len(words)-1].after, []rune(tok)...)
+			} else {
+				pending = append(pending, []rune(tok)...)
+			}
+
+			continue
+		}
+
+		if len(words) == 0 {
+			w.before = append(pending, w.before...)
+		}
+
+		words = append(words, w)
+	}
+
+	return words
+}
+
+// countMarks adds the marks around aligned reference and hypothesis words, either
+// of which may be nil, to the given counts indexed by mark. Marks are hits if
+// found on the same side of both words.
+func countMarks(counts map[string]*PunctCounts, ref, hyp *formattedWord) {
+	sides := func(w *formattedWord) [2]map[string]int {
+		s := [2]map[string]int{make(map[string]int), make(map[string]int)}
+		if w == nil {
+			return s
+		}
+
+		for i, marks := range [][]rune{w.before, w.after} {
+			for _, m := range marks {
+				s[i][string(m)]++
+			}
+		}
+
+		return s
+	}
+
+	get := func(mark string) *PunctCounts {
+		if _, ok := counts[mark]; !ok {
+			counts[mark] = &PunctCounts{Mark: mark}
+		}
+
+		return counts[mark]
+	}
+
+	refMarks, hypMarks := sides(ref), sides(hyp)
+
+	for i := range refMarks {
+		for m, n := range refMarks[i] {
+			get(m).Ref += n
+			if hits := hypMarks[i][m]; hits < n {
+				get(m).Hits += hits
+			} else {
+				get(m).Hits += n
+			}
+		}
+
+		for m, n := range hypMarks[i] {
+			get(m).Hyp += n
+		}
+	}
+}
+
+// projectSentence projects the formatted reference and hypothesis transcripts
+// of an aligned sentence onto its words, counting case errors and punctuation
+// marks. It returns false if the formatted words do not match the alignment.
+func (m *FormattingMetrics) projectSentence(
+	s *sctk.AlignedSentence, refWords, hypWords []formattedWord, counts map[string]*PunctCounts,
+) bool {
+	refIdx, hypIdx := alignedIndexes(s)
+	if len(refIdx) != len(refWords) || len(hypIdx) != len(hypWords) {
+		return false
+	}
+
+	for k, i := range refIdx {
+		if s.Words[i].Ref != refWords[k].norm {
+			return false
+		}
+	}
+
+	for k, i := range hypIdx {
+		if s.Words[i].Hyp != hypWords[k].norm {
+			return false
+		}
+	}
+
+	r, h := 0, 0
+
+	for _, w := range s.Words {
+		switch w.Label {
+		case "D":
+			countMarks(counts, &refWords[r], nil)
+			r++
+		case "I":
+			countMarks(counts, nil, &hypWords[h])
+			h++
+		default:
+			if w.Label == "C" {
+				m.CorrectWords++
+				if refWords[r].word != hypWords[h].word {
+					m.CaseErrors++
+				}
+			}
+
+			countMarks(counts, &refWords[r], &hypWords[h])
+			r++
+			h++
+		}
+	}
+
+	return true
+}
+
+// FormattingErrorRates computes the case and punctuation error rates of the
+// aligned sentences of a system, given the formatted reference and hypothesis
+// transcripts indexed by utterance ID, and the normalization config of the run.
+func FormattingErrorRates(
+	aligned *sctk.AlignedHypothesis, refTrns, hypTrns map[string]string, cfg NormalizeConfig,
+) FormattingMetrics {
+	m := FormattingMetrics{System: aligned.SystemName, Marks: make([]PunctCounts, 0)}
+	counts := make(map[string]*PunctCounts)
+
+	for _, sents := range aligned.Speakers {
+		for _, s := range sents {
+			id := strings.Trim(s.SentenceID, "()")
+			refWords := formattedWords(refTrns[id], cfg)
+			hypWords := formattedWords(hypTrns[id], cfg)
+
+			if !m.projectSentence(s, refWords, hypWords, counts) {
+				logrus.WithFields(logrus.Fields{
+					"system":    aligned.SystemName,
+					"utterance": id,
+				}).Warn("skipping utterance whose formatted transcripts do not match its alignment")
+
+				m.SkippedUtts++
+
+				continue
+			}
+
+			m.NumUtts++
+		}
+	}
+
+	m.CaseErrorRate = percentage(m.CaseErrors, m.CorrectWords)
+
+	marks := textutils.SortedKeys(counts)
+
+	for _, mark := range marks {
+		c := counts[mark]
+		c.setRates()
+
+		m.Punctuation.Ref += c.Ref
+		m.Punctuation.Hyp += c.Hyp
+		m.Punctuation.Hits += c.Hits
+		m.Marks = append(m.Marks, *c)
+	}
+
+	m.Punctuation.setRates()
+
+	return m
+}
+
+// setRates sets recall, precision and F1 from the counts. Each is 0 if
+// undefined.
+func (c *PunctCounts) setRates() {
+	c.Recall = percentage(c.Hits, c.Ref)
+	c.Precision = percentage(c.Hits, c.Hyp)
+	c.F1 = 0
+
+	if c.Recall+c.Precision > 0 {
+		c.F1 = 2 * c.Recall * c.Precision / (c.Recall + c.Precision) //nolint: gomnd // harmonic mean.
+	}
+}
+
+// scoreFormatting computes the case and punctuation error rates of the given
+// normalized hypotheses, from the sgml alignments written by sclite and the
+// formatted transcripts written next to the normalized ones, and writes them
+// to formatting.json in the output directory.
+func scoreFormatting(cfg NormalizeConfig, outDir, refNorm string, hypFiles []sctk.Hypothesis) error {
+	refTrns, err := readFormattedFile(strings.TrimSuffix(refNorm, ".trn") + formattedExt)
+	if err != nil {
+		return err
+	}
+
+	metrics := make([]FormattingMetrics, 0, len(hypFiles))
+
+	for _, hyp := range hypFiles {
+		hypTrns, err := readFormattedFile(strings.TrimSuffix(hyp.FilePath, ".trn") + formattedExt)
+		if err != nil {
+			return err
+		}
+
+		aligned, err := sctk.ReadAlignmentSgml(path.Join(outDir, path.Base(hyp.FilePath)+".sgml"))
+		if err != nil {
+			return fmt.Errorf("failed to read alignments: %w", err)
+		}
+
+		m := FormattingErrorRates(aligned, refTrns, hypTrns, cfg)
+
+		logrus.WithFields(logrus.Fields{
+			"system":          m.System,
+			"case_error_rate": fmt.Sprintf("%.2f%%", m.CaseErrorRate),
+			"punct_recall":    fmt.Sprintf("%.2f%%", m.Punctuation.Recall),
+			"punct_precision": fmt.Sprintf("%.2f%%", m.Punctuation.Precision),
+		}).Info("computed case and punctuation error rates")
+
+		metrics = append(metrics, m)
+	}
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode case and punctuation error rates: %w", err)
+	}
+
+	if err := os.WriteFile(path.Join(outDir, FormattingFile), data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write case and punctuation error rates: %w", err)
+	}
+
+	return nil
+}
+
+// checkFormatting checks whether case and punctuation error rates can be
+// computed for the given run.
+func checkFormatting(fileFormat FileFormat, scliteCfg sctk.ScliteCfg, refFile string) error {
+	switch {
+	case scliteCfg.CER:
+		return fmt.Errorf("case and punctuation error rates can only be computed when scoring words")
+	case fileFormat.refFormat(refFile) == FormatStm:
+		return fmt.Errorf("case and punctuation error rates can only be computed against delimited references")
+	case !scliteCfg.WritesReport("sgml"):
+		return fmt.Errorf("case and punctuation error rates need the sgml report, see --reports")
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestScoreFormatting(t *testing.T) {
+	t.Parallel()
+
+	// punct returns punctuation counts with recall and precision given as
+	// fractions.
+	punct := func(mark string, ref, hyp, hits int, recall, precision float64) PunctCounts {
+		c := PunctCounts{Mark: mark, Ref: ref, Hyp: hyp, Hits: hits, Recall: 100 * recall, Precision: 100 * precision}
+
+		if recall+precision > 0 {
+			c.F1 = 100 * 2 * recall * precision / (recall + precision)
+		}
+
+		return c
+	}
+
+	testCases := []struct {
+		name      string
+		normCfg   NormalizeConfig
+		scliteCfg sctk.ScliteCfg
+		want      []FormattingMetrics
+		wantErr   bool
+	}{
+		{
+			name:      "stripPunctuation",
+			normCfg:   NormalizeConfig{StripPunctuation: true, ScoreFormatting: true},
+			scliteCfg: sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"},
+			want: []FormattingMetrics{
+				{
+					System: "hyp1", NumUtts: 3, CorrectWords: 15, CaseErrors: 4, CaseErrorRate: 100 * 4.0 / 15,
+					Punctuation: punct("", 6, 5, 2, 2.0/6, 2.0/5),
+					Marks: []PunctCounts{
+						punct("!", 1, 1, 0, 0, 0),
+						punct(",", 2, 1, 0, 0, 0),
+						punct(".", 2, 2, 1, 1.0/2, 1.0/2),
+						punct("?", 1, 1, 1, 1, 1),
+					},
+				},
+			},
+		},
+		{
+			// The case of words must already match for them to be correct.
+			name:      "caseSensitive",
+			normCfg:   NormalizeConfig{CaseSensitive: true, StripPunctuation: true, ScoreFormatting: true},
+			scliteCfg: sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"},
+			want: []FormattingMetrics{
+				{
+					System: "hyp1", NumUtts: 3, CorrectWords: 11,
+					Punctuation: punct("", 6, 5, 2, 2.0/6, 2.0/5),
+					Marks: []PunctCounts{
+						punct("!", 1, 1, 0, 0, 0),
+						punct(",", 2, 1, 0, 0, 0),
+						punct(".", 2, 2, 1, 1.0/2, 1.0/2),
+						punct("?", 1, 1, 1, 1, 1),
+					},
+				},
+			},
+		},
+		{
+			name:      "cer",
+			normCfg:   NormalizeConfig{StripPunctuation: true, ScoreFormatting: true},
+			scliteCfg: sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8", CER: true},
+			wantErr:   true,
+		},
+		{
+			name:    "noSgml",
+			normCfg: NormalizeConfig{StripPunctuation: true, ScoreFormatting: true},
+			scliteCfg: sctk.ScliteCfg{
+				LineWidth: 120, Encoding: "utf-8", Reports: []string{"sum"},
+				AlignmentFormats: []string{sctk.AlignmentFormatNone},
+				UtteranceFormats: []string{sctk.UtteranceFormatNone},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			fileFormat := FileFormat{Delimiter: '\t', ColTrn: 1}
+			hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "testdata/formatting/hyp.tsv"}}
+
+			err := Score(
+				context.Background(), fileFormat, tc.normCfg, tc.scliteCfg, outDir,
+				"testdata/formatting/ref.tsv", hypFiles,
+			)
+			if tc.wantErr {
+				if err == nil {
+					subT.Errorf("did not get expected error, want=non-nil, got=nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			data, err := os.ReadFile(path.Join(outDir, FormattingFile))
+			if err != nil {
+				subT.Fatalf("failed to read case and punctuation error rates: %v", err)
+			}
+
+			var got []FormattingMetrics
+			if err := json.Unmarshal(data, &got); err != nil {
+				subT.Fatalf("failed to decode case and punctuation error rates: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				subT.Errorf("unexpected case and punctuation error rates, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStripPunctuation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "edges", text: `"Hello," she said.`, want: "Hello she said"},
+		{name: "punctuationOnly", text: "wait - what ?!", want: "wait what"},
+		{name: "withinWords", text: "don't e-mail 3.5", want: "don't e-mail 3.5"},
+		{name: "danda", text: "তার নাম কালীপ্রসন্ন।", want: "তার নাম কালীপ্রসন্ন"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			if got := stripPunctuation(tc.text); got != tc.want {
+				subT.Errorf("unexpected text, want=%q, got=%q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,437 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/gate"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+	"github.com/shahruk10/go-sctk/internal/version"
+)
+
+const (
+	// ManifestFile is the name of the manifest written to the output directory
+	// of each scoring run.
+	ManifestFile = "run.json"
+
+	// manifestVersion is the version of the manifest format, incremented
+	// whenever fields are changed in a way that older versions can not read.
+	manifestVersion = 2
+)
+
+// A Manifest records everything needed to reproduce a scoring run: the
+// configuration, the inputs and their checksums, and the versions of the tools
+// used.
+type Manifest struct {
+	ManifestVersion int `json:"manifest_version"`
+
+	// ToolVersion is the version of this tool, EmbeddedChecksum the checksum of
+	// the embedded SCTK tools and SctkBinDir the directory of system installed
+	// SCTK tools used instead of the embedded ones, if any.
+	ToolVersion      string `json:"tool_version"`
+	EmbeddedChecksum string `json:"embedded_checksum"`
+	SctkBinDir       string `json:"sctk_bin_dir,omitempty"`
+
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+
+	// Mode is how the hypotheses were scored; one of ModeUtterance, ModeOverlap
+	// or ModeSession. Paths in the configuration are absolute.
+	Mode            string          `json:"mode"`
+	FileFormat      FileFormat      `json:"file_format"`
+	NormalizeConfig NormalizeConfig `json:"normalize_config"`
+	ScliteCfg       sctk.ScliteCfg  `json:"sclite_config"`
+	Gates           gate.Config     `json:"gates"`
+
+	OutDir string          `json:"out_dir"`
+	Ref    ManifestInput   `json:"ref"`
+	Hyps   []ManifestInput `json:"hyps"`
+
+	// WordWeights is the word weight list given to sclite, TermList the list of
+	// terms scored and WeightTable the table of word costs of the weighted error
+	// rates, if any. Baseline is the output directory of the baseline run that
+	// the error rates were compared against by the quality gates, if any.
+	WordWeights *ManifestInput `json:"word_weights,omitempty"`
+	TermList    *ManifestInput `json:"term_list,omitempty"`
+	WeightTable *ManifestInput `json:"weight_table,omitempty"`
+	Baseline    *ManifestInput `json:"baseline,omitempty"`
+
+	// Terms are the counts of each system over all terms in TermList, and
+	// TermsReports the files with the counts per category and term, relative to
+	// OutDir. Both are only set if a term list was given.
+	Terms        map[string]TermCounts `json:"terms,omitempty"`
+	TermsReports []string              `json:"terms_reports,omitempty"`
+}
+
+// A ManifestInput describes an input file of a scoring run.
+type ManifestInput struct {
+	SystemName string `json:"system_name,omitempty"`
+	Format     string `json:"format,omitempty"`
+
+	// Path is the absolute path to the input file, or "-" for the standard
+	// input. SHA256 is the hex encoded checksum of the file as stored on disk;
+	// for directories, it covers the relative paths and contents of all files.
+	// It is empty for the standard input.
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+
+	// NumRead is the number of utterances read; segments and words for stm and
+	// ctm files. For hypotheses, NumFiltered is the number of utterances
+	// ignored for not having a reference, and NumMissing the number of
+	// reference utterances not in the hypothesis.
+	NumRead     int `json:"num_read"`
+	NumFiltered int `json:"num_filtered"`
+	NumMissing  int `json:"num_missing"`
+}
+
+// newManifest creates a manifest for a scoring run with the given
+// configuration and inputs, computing the checksums of the input files.
+func newManifest(
+	ctx context.Context, fileFormat FileFormat, normCfg NormalizeConfig, scliteCfg sctk.ScliteCfg,
+	opts Options, outDir, refFile string, hypFiles []sctk.Hypothesis,
+) (Manifest, error) {
+	m := Manifest{
+		ManifestVersion: manifestVersion,
+		ToolVersion:     version.Version(),
+		SctkBinDir:      embedded.BinDir(),
+		StartTime:       time.Now(),
+		Mode:            ModeUtterance,
+		FileFormat:      fileFormat,
+		NormalizeConfig: normCfg,
+		ScliteCfg:       scliteCfg,
+		Gates:           opts.Gates,
+	}
+
+	switch {
+	case fileFormat.Sessions:
+		m.Mode = ModeSession
+	case scliteCfg.Overlap:
+		m.Mode = ModeOverlap
+	}
+
+	if checksum, err := embedded.Checksum(); err != nil {
+		logrus.WithFields(logrus.Fields{"error": err}).Warn("failed to compute checksum of embedded tools")
+	} else {
+		m.EmbeddedChecksum = checksum
+	}
+
+	var err error
+
+	if m.OutDir, err = filepath.Abs(outDir); err != nil {
+		return m, fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+
+	if m.Ref, err = newManifestInput(ctx, refFile); err != nil {
+		return m, err
+	}
+
+	for _, hyp := range hypFiles {
+		input, err := newManifestInput(ctx, hyp.FilePath)
+		if err != nil {
+			return m, err
+		}
+
+		input.SystemName, input.Format = hyp.SystemName, hyp.Format
+		m.Hyps = append(m.Hyps, input)
+	}
+
+	// The paths of optional inputs in the configuration are replaced with
+	// absolute ones, so that the run can be reproduced from any directory.
+	for _, opt := range []struct {
+		filePath *string
+		input    **ManifestInput
+	}{
+		{&m.ScliteCfg.WordWeights, &m.WordWeights},
+		{&opts.TermList, &m.TermList},
+		{&opts.WeightTable, &m.WeightTable},
+		{&m.Gates.BaselineDir, &m.Baseline},
+	} {
+		// Weighing all words equally needs no word weight list.
+		if *opt.filePath == "" || (opt.input == &m.WordWeights && *opt.filePath == sctk.WordWeightsUnity) {
+			continue
+		}
+
+		input, err := newManifestInput(ctx, *opt.filePath)
+		if err != nil {
+			return m, err
+		}
+
+		*opt.filePath, *opt.input = input.Path, &input
+	}
+
+	return m, nil
+}
+
+// newManifestInput describes the given input file, computing its checksum.
+func newManifestInput(ctx context.Context, filePath string) (ManifestInput, error) {
+	if filePath == fileutils.Stdin {
+		return ManifestInput{Path: filePath}, nil
+	}
+
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return ManifestInput{}, fmt.Errorf("failed to resolve path of %q: %w", filePath, err)
+	}
+
+	checksum, err := inputChecksum(ctx, absPath)
+	if err != nil {
+		return ManifestInput{}, fmt.Errorf("failed to compute checksum of %q: %w", filePath, err)
+	}
+
+	return ManifestInput{Path: absPath, SHA256: checksum}, nil
+}
+
+// setTerms sets the counts of each system over all terms, and the reports
+// written with the counts, from the given term summary.
+func (m *Manifest) setTerms(summary *TermsSummary) {
+	m.Terms = make(map[string]TermCounts, len(summary.Systems))
+	for _, st := range summary.Systems {
+		m.Terms[st.System] = st.Total
+	}
+
+	m.TermsReports = []string{TermsFile + ".json", TermsFile + ".html"}
+}
+
+// setCounts sets the number of utterances read, filtered and missing for each
+// input from the given validation report, which lists the reference file first
+// followed by the hypotheses in the same order as in the manifest.
+func (m *Manifest) setCounts(report ValidationReport) {
+	inputs := make([]*ManifestInput, 0, len(m.Hyps)+1)
+	inputs = append(inputs, &m.Ref)
+
+	for i := range m.Hyps {
+		inputs = append(inputs, &m.Hyps[i])
+	}
+
+	for i, f := range report.Files {
+		if i == len(inputs) {
+			break
+		}
+
+		inputs[i].NumRead = f.NumUtts
+
+		if c := f.Coverage; c != nil {
+			inputs[i].NumFiltered = c.NumExtra
+			inputs[i].NumMissing = c.NumRef - c.NumMatched
+		}
+	}
+}
+
+// write writes the manifest to the given path in the JSON format.
+func (m *Manifest) write(filePath string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run manifest: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write run manifest: %w", err)
+	}
+
+	return nil
+}
+
+// ReadManifest reads the run manifest at the given path.
+func ReadManifest(filePath string) (Manifest, error) {
+	var m Manifest
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return m, fmt.Errorf("failed to read run manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to decode run manifest: %w", err)
+	}
+
+	if m.ManifestVersion != manifestVersion {
+		return m, fmt.Errorf(
+			"unsupported run manifest version %d, expected %d", m.ManifestVersion, manifestVersion,
+		)
+	}
+
+	return m, nil
+}
+
+// Rerun reproduces the scoring run described by the given manifest, writing
+// the results to the given output directory, which must differ from that of
+// the original run. The checksums of the input files must match those recorded
+// in the manifest. Differences in the versions of the tools used are logged as
+// warnings, since they may lead to different results.
+func Rerun(ctx context.Context, m Manifest, outDir string) error {
+	if outDir == "" {
+		return fmt.Errorf("an output directory is needed to rerun scoring")
+	}
+
+	absOutDir, err := filepath.Abs(outDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve output directory: %w", err)
+	}
+
+	if absOutDir == m.OutDir {
+		return fmt.Errorf("can not rerun scoring into the output directory of the original run %q", m.OutDir)
+	}
+
+	inputs := append([]ManifestInput{m.Ref}, m.Hyps...)
+	for _, input := range []*ManifestInput{m.WordWeights, m.TermList, m.WeightTable, m.Baseline} {
+		if input != nil {
+			inputs = append(inputs, *input)
+		}
+	}
+
+	for _, input := range inputs {
+		if input.Path == fileutils.Stdin {
+			return fmt.Errorf("can not rerun scoring of inputs read from the standard input")
+		}
+
+		checksum, err := inputChecksum(ctx, input.Path)
+		if err != nil {
+			return fmt.Errorf("failed to compute checksum of %q: %w", input.Path, err)
+		}
+
+		if checksum != input.SHA256 {
+			return fmt.Errorf("input %q has changed since the original run", input.Path)
+		}
+	}
+
+	current := Manifest{ToolVersion: version.Version(), SctkBinDir: embedded.BinDir()}
+	current.EmbeddedChecksum, _ = embedded.Checksum()
+
+	for _, diff := range []struct{ name, was, is string }{
+		{"tool version", m.ToolVersion, current.ToolVersion},
+		{"embedded tools checksum", m.EmbeddedChecksum, current.EmbeddedChecksum},
+		{"sctk binary directory", m.SctkBinDir, current.SctkBinDir},
+	} {
+		if diff.was != diff.is {
+			logrus.WithFields(logrus.Fields{
+				"original": diff.was,
+				"current":  diff.is,
+			}).Warnf("%s differs from the original run, results may differ", diff.name)
+		}
+	}
+
+	hypFiles := make([]sctk.Hypothesis, 0, len(m.Hyps))
+	for _, hyp := range m.Hyps {
+		hypFiles = append(hypFiles, sctk.Hypothesis{
+			SystemName: hyp.SystemName, FilePath: hyp.Path, Format: hyp.Format,
+		})
+	}
+
+	if m.Mode == ModeSession {
+		_, err := ScoreSessions(
+			ctx, m.FileFormat, m.NormalizeConfig, m.ScliteCfg.CER, outDir, m.Ref.Path, hypFiles,
+		)
+
+		return err
+	}
+
+	opts := Options{Gates: m.Gates}
+	if m.TermList != nil {
+		opts.TermList = m.TermList.Path
+	}
+
+	if m.WeightTable != nil {
+		opts.WeightTable = m.WeightTable.Path
+	}
+
+	_, err = Run(ctx, m.FileFormat, m.NormalizeConfig, m.ScliteCfg, opts, outDir, m.Ref.Path, hypFiles)
+
+	return err
+}
+
+// inputChecksum returns the hex encoded SHA-256 checksum of the given file. For
+// directories, the checksum covers the relative path and contents of each file
+// under it, in lexical order.
+func inputChecksum(ctx context.Context, filePath string) (string, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.IsDir() {
+		return fileChecksum(filePath)
+	}
+
+	h := sha256.New()
+
+	walkFn := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(filePath, p)
+		if err != nil {
+			return err
+		}
+
+		checksum, err := fileChecksum(p)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "%s %s\n", checksum, strings.ReplaceAll(filepath.ToSlash(relPath), "\n", " "))
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(filePath, walkFn); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// fileChecksum returns the hex encoded SHA-256 checksum of the given file.
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,199 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/gate"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestRerun(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	// Copying inputs so that they can be modified.
+	refFile, hypFile := path.Join(tmpDir, "ref.csv"), path.Join(tmpDir, "hyp.csv")
+	termList, weightTable := path.Join(tmpDir, "terms.txt"), path.Join(tmpDir, "table.txt")
+
+	for src, dst := range map[string]string{
+		"testdata/validate/good1_ref.csv": refFile,
+		"testdata/validate/good1_hyp.csv": hypFile,
+		"testdata/terms/terms.txt":        termList,
+		"testdata/weights/table.txt":      weightTable,
+	} {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatalf("failed to read test data: %v", err)
+		}
+
+		if err := os.WriteFile(dst, data, 0600); err != nil {
+			t.Fatalf("failed to write test data: %v", err)
+		}
+	}
+
+	outDir := path.Join(tmpDir, "out")
+	fileFormat := FileFormat{Delimiter: ',', ColTrn: 1}
+	scliteCfg := sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"}
+	hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: hypFile}}
+	maxWER := 100.0
+	opts := Options{TermList: termList, WeightTable: weightTable, Gates: gate.Config{MaxWER: &maxWER}}
+
+	if _, err := Run(ctx, fileFormat, NormalizeConfig{}, scliteCfg, opts, outDir, refFile, hypFiles); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	m, err := ReadManifest(path.Join(outDir, ManifestFile))
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	ignoreChecksums := cmp.FilterPath(func(p cmp.Path) bool {
+		return p.Last().String() == ".SHA256"
+	}, cmp.Ignore())
+
+	wantHyps := []ManifestInput{{SystemName: "hyp1", Path: hypFile, NumRead: 2}}
+	if diff := cmp.Diff(wantHyps, m.Hyps, ignoreChecksums); diff != "" {
+		t.Errorf("unexpected hypotheses in manifest, (-want, +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		input *ManifestInput
+		want  string
+	}{
+		{name: "term list", input: m.TermList, want: termList},
+		{name: "weight table", input: m.WeightTable, want: weightTable},
+	} {
+		if tc.input == nil || tc.input.Path != tc.want || tc.input.SHA256 == "" {
+			t.Errorf("unexpected %s in manifest, want path=%q with checksum, got=%+v", tc.name, tc.want, tc.input)
+		}
+	}
+
+	wantReports := []string{TermsFile + ".json", TermsFile + ".html"}
+	if diff := cmp.Diff(wantReports, m.TermsReports); diff != "" {
+		t.Errorf("unexpected term reports in manifest, (-want, +got):\n%s", diff)
+	}
+
+	if _, ok := m.Terms["hyp1"]; !ok {
+		t.Errorf("expected term counts of hyp1 in manifest, got=%+v", m.Terms)
+	}
+
+	report, err := os.ReadFile(path.Join(outDir, "hyp1.trn.pra.html"))
+	if err != nil {
+		t.Fatalf("failed to read alignment report: %v", err)
+	}
+
+	if !strings.Contains(string(report), `<a href="terms.html">`) {
+		t.Errorf("expected alignment report to link to the term report")
+	}
+
+	if diff := cmp.Diff(opts.Gates, m.Gates); diff != "" {
+		t.Errorf("unexpected gates in manifest, (-want, +got):\n%s", diff)
+	}
+
+	for _, dir := range []string{"", outDir} {
+		if err := Rerun(ctx, m, dir); err == nil {
+			t.Errorf("did not get expected error for output directory %q, want=non-nil, got=%v", dir, err)
+		}
+	}
+
+	rerunDir := path.Join(tmpDir, "rerun")
+	if err := Rerun(ctx, m, rerunDir); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	for _, name := range []string{"hyp1.trn.sys", TermsFile + ".json", WeightedFile} {
+		want, _ := os.ReadFile(path.Join(outDir, name))
+		got, err := os.ReadFile(path.Join(rerunDir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s of rerun: %v", name, err)
+		}
+
+		if diff := cmp.Diff(string(want), string(got)); diff != "" {
+			t.Errorf("differences between original and rerun %s (-want, +got):\n%s", name, diff)
+		}
+	}
+
+	for _, filePath := range []string{termList, hypFile} {
+		original, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("failed to read test data: %v", err)
+		}
+
+		if err := os.WriteFile(filePath, []byte("spk1-utt1,changed\n"), 0600); err != nil {
+			t.Fatalf("failed to modify test data: %v", err)
+		}
+
+		if err := Rerun(ctx, m, path.Join(tmpDir, "rerun-changed")); err == nil {
+			t.Errorf("did not get expected error for modified %q, want=non-nil, got=%v", filePath, err)
+		}
+
+		if err := os.WriteFile(filePath, original, 0600); err != nil {
+			t.Fatalf("failed to restore test data: %v", err)
+		}
+	}
+}
+
+func TestRerunSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	outDir := path.Join(tmpDir, "out")
+
+	fileFormat := FileFormat{Delimiter: ',', ColTrn: 2, IgnoreFirstRow: true, Sessions: true, ColSpeaker: 1}
+	hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "testdata/sessions/hyp1.csv"}}
+
+	if _, err := ScoreSessions(
+		ctx, fileFormat, NormalizeConfig{}, false, outDir, "testdata/sessions/ref.csv", hypFiles,
+	); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	m, err := ReadManifest(path.Join(outDir, ManifestFile))
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	if m.Mode != ModeSession {
+		t.Errorf("unexpected mode in manifest, want=%q, got=%q", ModeSession, m.Mode)
+	}
+
+	rerunDir := path.Join(tmpDir, "rerun")
+	if err := Rerun(ctx, m, rerunDir); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	want, _ := os.ReadFile(path.Join(outDir, SessionsFile))
+	got, err := os.ReadFile(path.Join(rerunDir, SessionsFile))
+	if err != nil {
+		t.Fatalf("failed to read session scores of rerun: %v", err)
+	}
+
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("differences between original and rerun session scores (-want, +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// OracleSuffix is appended to the system name of N-best hypotheses to name the
+// system made of their oracle transcripts.
+const OracleSuffix = "_oracle"
+
+// NBestSummary summarizes how well the N-best lists of a hypothesis file could
+// do against the reference. Errors are counted by word edit distance, or by
+// character edit distance ignoring spaces when evaluating CER, over the
+// utterances that have both a reference and an N-best list.
+type NBestSummary struct {
+	System        string  `json:"system"`
+	NumUtts       int     `json:"num_utterances"`
+	AvgListSize   float64 `json:"avg_list_size"`
+	RefWords      int     `json:"ref_words"`
+	OneBestErrs   int     `json:"one_best_errors"`
+	OneBestRate   float64 `json:"one_best_error_rate"`
+	OracleErrs    int     `json:"oracle_errors"`
+	OracleRate    float64 `json:"oracle_error_rate"`
+	AvgOracleRank float64 `json:"avg_oracle_rank"`
+
+	// OracleRanks counts how often the transcript at each rank, starting at 1,
+	// was picked as the oracle. Ties are resolved in favor of the better rank.
+	OracleRanks map[int]int `json:"oracle_rank_counts"`
+}
+
+// An nbestList holds the transcripts of an utterance in an N-best file, best
+// first.
+type nbestList struct {
+	id   string
+	utts []Utt
+}
+
+// nbestLine is a line of a JSONL N-best file.
+type nbestLine struct {
+	ID    string   `json:"id"`
+	NBest []string `json:"nbest"`
+}
+
+// prepareNBest reads the N-best lists in the given hypothesis files, and picks
+// the oracle transcript of each utterance, the one closest to the reference
+// after normalization. For each file, the 1-best and oracle transcripts are
+// written to delimited files in the output directory, in the layout given by
+// fileFormat, to be scored as two systems: the system of the hypothesis and the
+// same with OracleSuffix. A summary of each file is written to
+// <system>.nbest.json in the output directory. The reference utterances are
+// returned along with the hypotheses to score, so that the reference is only
+// read once, as it may be the standard input.
+func prepareNBest(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig, cer bool,
+	outDir, refFile string, hypFiles []sctk.Hypothesis,
+) ([]sctk.Hypothesis, []Utt, error) {
+	const (
+		filePerm = 0777
+	)
+
+	if fileFormat.refFormat(refFile) != FormatDelimited {
+		return nil, nil, fmt.Errorf("N-best lists can only be scored against delimited references")
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return nil, nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// The reference has one transcript per utterance, without ranks.
+	refFileFormat := fileFormat
+	refFileFormat.NBest = false
+
+	refUtts, err := readTranscriptFile(ctx, refFile, refFileFormat)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	refTokens := make(map[string][]string, len(refUtts))
+	for _, utt := range refUtts {
+		refTokens[utt.ID] = scoringTokens(normalizeText(utt.Transcript, cfg), cer)
+	}
+
+	flatFiles := make([]sctk.Hypothesis, 0, 2*len(hypFiles)) //nolint: gomnd // 1-best and oracle.
+
+	for _, hyp := range hypFiles {
+		lists, err := readNBestFile(ctx, fileFormat, hyp.FilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read N-best file: %w", err)
+		}
+
+		summary := NBestSummary{
+			System:      SanitizeSystemName(hyp.SystemName),
+			OracleRanks: make(map[int]int),
+		}
+
+		oneBest := make([]Utt, 0, len(lists))
+		oracle := make([]Utt, 0, len(lists))
+		numHyps := 0
+
+		for _, l := range lists {
+			best := 0
+
+			if ref, ok := refTokens[l.id]; ok {
+				dists := make([]int, len(l.utts))
+				for i, utt := range l.utts {
+					dists[i] = textutils.EditDistance(ref, scoringTokens(normalizeText(utt.Transcript, cfg), cer))
+					if dists[i] < dists[best] {
+						best = i
+					}
+				}
+
+				summary.NumUtts++
+				summary.RefWords += len(ref)
+				summary.OneBestErrs += dists[0]
+				summary.OracleErrs += dists[best]
+				summary.OracleRanks[best+1]++
+				summary.AvgOracleRank += float64(best + 1)
+				numHyps += len(l.utts)
+			}
+
+			oneBest = append(oneBest, l.utts[0])
+			oracle = append(oracle, l.utts[best])
+		}
+
+		if summary.NumUtts > 0 {
+			summary.AvgListSize = float64(numHyps) / float64(summary.NumUtts)
+			summary.AvgOracleRank /= float64(summary.NumUtts)
+		}
+
+		summary.OneBestRate = sctk.ErrorRate(summary.OneBestErrs, summary.RefWords)
+		summary.OracleRate = sctk.ErrorRate(summary.OracleErrs, summary.RefWords)
+
+		summary.log()
+
+		if err := summary.write(path.Join(outDir, summary.System+".nbest.json")); err != nil {
+			return nil, nil, err
+		}
+
+		for _, sys := range []struct {
+			name string
+			utts []Utt
+		}{
+			{hyp.SystemName, oneBest},
+			{hyp.SystemName + OracleSuffix, oracle},
+		} {
+			flatFile := path.Join(outDir, SanitizeSystemName(sys.name)+fileFormat.extension())
+			if err := writeDelimitedFile(ctx, sys.utts, fileFormat, flatFile); err != nil {
+				return nil, nil, fmt.Errorf("failed to write %s transcripts: %w", sys.name, err)
+			}
+
+			flatFiles = append(flatFiles, sctk.Hypothesis{SystemName: sys.name, FilePath: flatFile})
+		}
+	}
+
+	return flatFiles, refUtts, nil
+}
+
+// readNBestFile reads the N-best lists in the given delimited or JSONL file,
+// in the order their utterances first appear.
+func readNBestFile(ctx context.Context, fileFormat FileFormat, filePath string) ([]nbestList, error) {
+	if fileFormat.hypFormat(filePath) == FormatJSONL {
+		return readNBestJSONL(ctx, filePath)
+	}
+
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return nil, fmt.Errorf("N-best lists can not be read from directories")
+	}
+
+	utts, err := readTranscriptFile(ctx, filePath, fileFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := make([]nbestList, 0)
+	index := make(map[string]int)
+
+	for _, utt := range utts {
+		i, ok := index[utt.ID]
+		if !ok {
+			i = len(lists)
+			index[utt.ID] = i
+			lists = append(lists, nbestList{id: utt.ID})
+		}
+
+		lists[i].utts = append(lists[i].utts, utt)
+	}
+
+	if fileFormat.ColRank >= 0 {
+		for _, l := range lists {
+			sort.SliceStable(l.utts, func(i, j int) bool { return l.utts[i].rank < l.utts[j].rank })
+		}
+	}
+
+	return lists, nil
+}
+
+// readNBestJSONL reads the N-best lists in the given JSONL file.
+func readNBestJSONL(ctx context.Context, filePath string) ([]nbestList, error) {
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read N-best file: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+
+	lists := make([]nbestList, 0)
+	ldx := 0
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		ldx++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var l nbestLine
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			return nil, fmt.Errorf("failed to decode line %d: %w", ldx, err)
+		}
+
+		if l.ID == "" || len(l.NBest) == 0 {
+			return nil, fmt.Errorf("expected an ID and at least one transcript on line %d", ldx)
+		}
+
+		id := sanitizeUttID(l.ID)
+		list := nbestList{id: id, utts: make([]Utt, len(l.NBest))}
+
+		for i, trn := range l.NBest {
+			list.utts[i] = Utt{ID: id, Transcript: trn, line: ldx, rawID: l.ID, rank: i}
+		}
+
+		lists = append(lists, list)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d is longer than %d bytes", ldx+1, maxScanLineLen)
+		}
+
+		return nil, fmt.Errorf("failed to read N-best file: %w", err)
+	}
+
+	return lists, nil
+}
+
+// scoringTokens splits the given normalized transcript into the units errors
+// are counted in: words, or characters without spaces when evaluating CER.
+func scoringTokens(trn string, cer bool) []string {
+	if !cer {
+		return strings.Fields(trn)
+	}
+
+	tokens := make([]string, 0, len(trn))
+	for _, r := range trn {
+		if !unicode.IsSpace(r) {
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	return tokens
+}
+
+// log logs the summary.
+func (s *NBestSummary) log() {
+	logrus.WithFields(logrus.Fields{
+		"system":          s.System,
+		"utterances":      s.NumUtts,
+		"one_best_rate":   fmt.Sprintf("%.2f%%", s.OneBestRate),
+		"oracle_rate":     fmt.Sprintf("%.2f%%", s.OracleRate),
+		"avg_oracle_rank": fmt.Sprintf("%.2f", s.AvgOracleRank),
+	}).Info("scored N-best lists")
+}
+
+// write writes the summary to the given path as JSON.
+func (s *NBestSummary) write(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode N-best summary: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write N-best summary: %w", err)
+	}
+
+	return nil
+}
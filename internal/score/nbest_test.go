@@ -0,0 +1,199 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/gate"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestScoreNBest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		hypFile     string
+		colRank     int
+		wantSummary NBestSummary
+	}{
+		{
+			name:    "rankColumn",
+			hypFile: "testdata/nbest/hyp.csv",
+			colRank: 2,
+			wantSummary: NBestSummary{
+				System: "hyp1", NumUtts: 4, AvgListSize: 2, RefWords: 18,
+				OneBestErrs: 3, OneBestRate: 100 * 3.0 / 18,
+				AvgOracleRank: 1.75, OracleRanks: map[int]int{1: 2, 2: 1, 3: 1},
+			},
+		},
+		{
+			name:    "fileOrder",
+			hypFile: "testdata/nbest/hyp.csv",
+			colRank: -1,
+			wantSummary: NBestSummary{
+				System: "hyp1", NumUtts: 4, AvgListSize: 2, RefWords: 18,
+				OneBestErrs: 4, OneBestRate: 100 * 4.0 / 18,
+				AvgOracleRank: 2, OracleRanks: map[int]int{1: 1, 2: 2, 3: 1},
+			},
+		},
+		{
+			name:    "jsonl",
+			hypFile: "testdata/nbest/hyp.jsonl",
+			colRank: -1,
+			wantSummary: NBestSummary{
+				System: "hyp1", NumUtts: 2, AvgListSize: 1.5, RefWords: 11,
+				OneBestErrs: 3, OneBestRate: 100 * 3.0 / 11,
+				OracleErrs: 2, OracleRate: 100 * 2.0 / 11,
+				AvgOracleRank: 1.5, OracleRanks: map[int]int{1: 1, 2: 1},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			fileFormat := FileFormat{Delimiter: ',', ColTrn: 1, NBest: true, ColRank: tc.colRank}
+			scliteCfg := sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"}
+			hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: tc.hypFile}}
+
+			err := Score(
+				context.Background(), fileFormat, NormalizeConfig{}, scliteCfg, outDir,
+				"testdata/nbest/ref.csv", hypFiles,
+			)
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			data, err := os.ReadFile(path.Join(outDir, "hyp1.nbest.json"))
+			if err != nil {
+				subT.Fatalf("failed to read N-best summary: %v", err)
+			}
+
+			var got NBestSummary
+			if err := json.Unmarshal(data, &got); err != nil {
+				subT.Fatalf("failed to decode N-best summary: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantSummary, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				subT.Errorf("unexpected N-best summary, (-want, +got):\n%s", diff)
+			}
+
+			// Both the 1-best and oracle transcripts should have been scored.
+			for _, name := range []string{"hyp1.trn.sys", "hyp1" + OracleSuffix + ".trn.sys"} {
+				if _, err := os.Stat(path.Join(outDir, name)); err != nil {
+					subT.Errorf("expected report %s to be written: %v", name, err)
+				}
+			}
+		})
+	}
+}
+
+// TestScoreNBestStdinRef checks that the reference is only read once when
+// scoring N-best lists, so that it can be read from the standard input. It
+// replaces os.Stdin, so it does not run in parallel with other tests.
+func TestScoreNBestStdinRef(t *testing.T) { //nolint: paralleltest // replaces os.Stdin.
+	ref, err := os.Open("testdata/nbest/ref.csv")
+	if err != nil {
+		t.Fatalf("failed to open reference: %v", err)
+	}
+
+	defer ref.Close()
+
+	stdin := os.Stdin
+	os.Stdin = ref
+
+	defer func() { os.Stdin = stdin }()
+
+	outDir := t.TempDir()
+	fileFormat := FileFormat{Delimiter: ',', ColTrn: 1, NBest: true, ColRank: 2}
+	scliteCfg := sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"}
+	hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "testdata/nbest/hyp.csv"}}
+
+	if err := Score(
+		context.Background(), fileFormat, NormalizeConfig{}, scliteCfg, outDir, fileutils.Stdin, hypFiles,
+	); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	data, err := os.ReadFile(path.Join(outDir, "hyp1.nbest.json"))
+	if err != nil {
+		t.Fatalf("failed to read N-best summary: %v", err)
+	}
+
+	var got NBestSummary
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to decode N-best summary: %v", err)
+	}
+
+	if got.NumUtts != 4 || got.RefWords != 18 {
+		t.Errorf("unexpected N-best summary, want 4 utterances and 18 words, got %d and %d", got.NumUtts, got.RefWords)
+	}
+}
+
+func TestRunGatesNBest(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	fileFormat := FileFormat{Delimiter: ',', ColTrn: 1, NBest: true, ColRank: 2}
+	scliteCfg := sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"}
+
+	// Alignments left in the output directory by an earlier run should not be
+	// gated.
+	err := Score(
+		context.Background(), fileFormat, NormalizeConfig{}, scliteCfg, outDir,
+		"testdata/nbest/ref.csv", []sctk.Hypothesis{{SystemName: "old", FilePath: "testdata/nbest/hyp.csv"}},
+	)
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	maxWER := 100.0
+	opts := Options{Gates: gate.Config{MaxWER: &maxWER}}
+	hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "testdata/nbest/hyp.csv"}}
+
+	results, err := Run(
+		context.Background(), fileFormat, NormalizeConfig{}, scliteCfg, opts, outDir,
+		"testdata/nbest/ref.csv", hypFiles,
+	)
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	// The oracle transcripts are not hypotheses of the run, so only the 1-best
+	// should be gated.
+	var got []string
+	for _, result := range results {
+		got = append(got, result.System)
+	}
+
+	if diff := cmp.Diff([]string{"hyp1"}, got); diff != "" {
+		t.Errorf("unexpected gated systems, (-want, +got):\n%s", diff)
+	}
+}
@@ -18,9 +18,13 @@ package score
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"os"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/dlclark/regexp2"
@@ -36,20 +40,141 @@ import (
 type Utt struct {
 	ID         string
 	Transcript string
+
+	// line is the line number of the utterance in the file it was read from,
+	// and rawID its ID before sanitizing; used when reporting problems.
+	line  int
+	rawID string
+
+	// rank is the rank of the hypothesis in the N-best list of the utterance,
+	// read from the rank column of N-best files; see FileFormat.NBest.
+	rank int
+
+	// speaker is the speaker of the utterance, read from the speaker column of
+	// session files; see FileFormat.Sessions.
+	speaker string
 }
 
 // NormalizeConfig specifies how to normalize utterance transcripts.
 type NormalizeConfig struct {
-	CaseSensitive    bool
-	NormalizeUnicode bool
+	CaseSensitive    bool `json:"case_sensitive"`
+	NormalizeUnicode bool `json:"normalize_unicode"`
+
+	// StripSubtitleMarkup removes markup commonly found in subtitles, such as
+	// formatting tags, speaker labels and sound effects in brackets, and joins
+	// the lines of the transcript. It is always applied to subtitle hypotheses.
+	StripSubtitleMarkup bool `json:"strip_subtitle_markup"`
+
+	// StripPunctuation removes punctuation at the start and end of words, and
+	// words made of punctuation only. Punctuation within words, such as the
+	// apostrophe in "don't", is kept.
+	StripPunctuation bool `json:"strip_punctuation"`
+
+	// ScoreFormatting computes the case and punctuation error rates hidden by
+	// case folding and stripping punctuation, by projecting the transcripts
+	// before those steps onto the alignments of the normalized transcripts.
+	// Only supported when scoring words against delimited references.
+	ScoreFormatting bool `json:"score_formatting"`
+
+	// Language selects a profile of language specific normalization rules;
+	// LanguageArabic or LanguageUrdu, or none if empty. TaMarbutaToHa,
+	// AlefMaqsuraToYa and StripDiacritics enable optional rules of the Arabic
+	// script profiles; see normalizeArabicScript.
+	Language        string `json:"language,omitempty"`
+	TaMarbutaToHa   bool   `json:"ta_marbuta_to_ha,omitempty"`
+	AlefMaqsuraToYa bool   `json:"alef_maqsura_to_ya,omitempty"`
+	StripDiacritics bool   `json:"strip_diacritics,omitempty"`
+}
+
+// Validate checks whether the normalization config is valid.
+func (c *NormalizeConfig) Validate() error {
+	switch c.Language {
+	case "", LanguageArabic, LanguageUrdu:
+	default:
+		return fmt.Errorf(
+			"unsupported language %q, supported %s|%s", c.Language, LanguageArabic, LanguageUrdu,
+		)
+	}
+
+	if c.Language == "" && (c.TaMarbutaToHa || c.AlefMaqsuraToYa || c.StripDiacritics) {
+		return fmt.Errorf("rules for the Arabic script need a language, %s or %s", LanguageArabic, LanguageUrdu)
+	}
+
+	return nil
 }
 
+// Formats of reference and hypotheses files.
+const (
+	// FormatAuto detects the format from the file extension; files ending with
+	// .stm or .ctm are read as such, all others as delimited files.
+	FormatAuto = "auto"
+	// FormatDelimited files contain one utterance per row, with the utterance
+	// ID and transcript in delimited columns.
+	FormatDelimited = "delimited"
+	// FormatStm files contain reference segments with speaker, channel and time
+	// boundaries. They can only be scored against ctm hypotheses.
+	FormatStm = "stm"
+	// FormatCtm files contain one hypothesis word per row with its start time,
+	// duration and optionally confidence score.
+	FormatCtm = "ctm"
+	// FormatSrt and FormatVtt files are SubRip and WebVTT subtitles, containing
+	// timed cues of one video each. They can only be used as hypotheses; see
+	// SubtitleConcat and SubtitleOverlap for how they are scored.
+	FormatSrt = "srt"
+	FormatVtt = "vtt"
+	// FormatJSONL files contain one JSON object per line. They can only be used
+	// as N-best hypotheses; see FileFormat.NBest.
+	FormatJSONL = "jsonl"
+)
+
 // FileFormat specifies the expected format of reference and hypotheses files.
 type FileFormat struct {
-	Delimiter      rune
-	ColTrn         int
-	ColID          int
-	IgnoreFirstRow bool
+	Delimiter      rune `json:"delimiter"`
+	ColTrn         int  `json:"col_trn"`
+	ColID          int  `json:"col_id"`
+	IgnoreFirstRow bool `json:"ignore_first_row"`
+
+	// RefFormat and HypFormat are the formats of the reference and hypotheses
+	// files respectively; FormatAuto if empty. The delimiter and column options
+	// only apply to delimited files.
+	RefFormat string `json:"ref_format"`
+	HypFormat string `json:"hyp_format"`
+
+	// SubtitleMode is how subtitle hypotheses are scored; SubtitleConcat or
+	// SubtitleOverlap. If empty, subtitles are mapped by time overlap when the
+	// reference is a stm file, and concatenated otherwise.
+	SubtitleMode string `json:"subtitle_mode"`
+
+	// IDPattern is a regular expression used to derive utterance IDs from the
+	// paths of transcript files, when reference or hypotheses are directories
+	// with one transcript file per utterance. It is matched against the path of
+	// each file relative to the directory; the groups it captures are joined
+	// with dashes to form the ID, or the whole match is used if it has no
+	// groups. Files that do not match are skipped. If empty, the ID is the
+	// relative path without its extension. In either case, slashes are replaced
+	// with dashes.
+	IDPattern string `json:"id_pattern"`
+
+	// Strict turns problems found when validating the input files before
+	// scoring into errors; otherwise they are logged as warnings.
+	Strict bool `json:"strict"`
+
+	// NBest reads hypotheses as N-best lists, with several transcripts per
+	// utterance, best first. In delimited files, IDs repeat once per
+	// transcript, ranked by the ColRank column if >= 0, or else by the order
+	// they appear in. In JSONL files, each line holds the ID of an utterance
+	// and its list, e.g. {"id": "utt1", "nbest": ["best", "second"]}. See
+	// Score for how they are scored.
+	NBest   bool `json:"nbest,omitempty"`
+	ColRank int  `json:"col_rank,omitempty"`
+
+	// Sessions reads inputs as sessions with several speakers, for speaker
+	// attributed scoring. In delimited files, the ColID column holds the
+	// session and the ColSpeaker column the speaker of each row. In stm and ctm
+	// files, the file is the session, and the speaker and channel columns hold
+	// the speaker respectively. See ScoreSessions.
+	Sessions   bool `json:"sessions,omitempty"`
+	ColSpeaker int  `json:"col_speaker,omitempty"`
 }
 
 // Validate checks whether the options configured for the file format are
@@ -63,29 +188,178 @@ func (f *FileFormat) Validate() error {
 		return fmt.Errorf("column index for transcript and ID must not be the same")
 	}
 
+	switch f.RefFormat {
+	case "", FormatAuto, FormatDelimited, FormatStm:
+	default:
+		return fmt.Errorf(
+			"unsupported reference format %q, supported %s|%s|%s",
+			f.RefFormat, FormatAuto, FormatDelimited, FormatStm,
+		)
+	}
+
+	switch f.HypFormat {
+	case "", FormatAuto, FormatDelimited, FormatCtm, FormatSrt, FormatVtt:
+	case FormatJSONL:
+		if !f.NBest {
+			return fmt.Errorf("%s hypotheses are only supported for N-best lists", FormatJSONL)
+		}
+	default:
+		return fmt.Errorf(
+			"unsupported hypothesis format %q, supported %s|%s|%s|%s|%s|%s",
+			f.HypFormat, FormatAuto, FormatDelimited, FormatCtm, FormatSrt, FormatVtt, FormatJSONL,
+		)
+	}
+
+	if f.NBest {
+		switch f.HypFormat {
+		case "", FormatAuto, FormatDelimited, FormatJSONL:
+		default:
+			return fmt.Errorf("N-best lists must be delimited or %s files, got %q", FormatJSONL, f.HypFormat)
+		}
+
+		if f.ColRank >= 0 && (f.ColRank == f.ColID || f.ColRank == f.ColTrn) {
+			return fmt.Errorf("column index for rank must not be the same as for transcript or ID")
+		}
+	}
+
+	if f.Sessions {
+		if f.NBest {
+			return fmt.Errorf("N-best lists can not be scored as sessions")
+		}
+
+		switch f.HypFormat {
+		case "", FormatAuto, FormatDelimited, FormatCtm:
+		default:
+			return fmt.Errorf("session hypotheses must be delimited or %s files, got %q", FormatCtm, f.HypFormat)
+		}
+
+		if f.ColSpeaker >= 0 && (f.ColSpeaker == f.ColID || f.ColSpeaker == f.ColTrn) {
+			return fmt.Errorf("column index for speaker must not be the same as for transcript or ID")
+		}
+	}
+
+	if _, err := regexp.Compile(f.IDPattern); err != nil {
+		return fmt.Errorf("invalid utterance ID pattern: %w", err)
+	}
+
+	switch f.SubtitleMode {
+	case "", SubtitleConcat, SubtitleOverlap:
+	default:
+		return fmt.Errorf(
+			"unsupported subtitle mode %q, supported %s|%s",
+			f.SubtitleMode, SubtitleConcat, SubtitleOverlap,
+		)
+	}
+
 	return nil
 }
 
+// refFormat returns the format of the given reference file.
+func (f *FileFormat) refFormat(filePath string) string {
+	return detectFormat(f.RefFormat, filePath)
+}
+
+// hypFormat returns the format of the given hypothesis file.
+func (f *FileFormat) hypFormat(filePath string) string {
+	return detectFormat(f.HypFormat, filePath)
+}
+
+// subtitleMode returns how subtitle hypotheses are scored against a reference
+// file of the given format.
+func (f *FileFormat) subtitleMode(refFormat string) string {
+	if f.SubtitleMode != "" {
+		return f.SubtitleMode
+	}
+
+	if refFormat == FormatStm {
+		return SubtitleOverlap
+	}
+
+	return SubtitleConcat
+}
+
+// detectFormat returns the given format, or if it is FormatAuto, the format
+// detected from the extension of the given file, ignoring the extensions of
+// compressed files.
+func detectFormat(format, filePath string) string {
+	if format != "" && format != FormatAuto {
+		return format
+	}
+
+	switch strings.ToLower(path.Ext(fileutils.TrimCompressedExt(filePath))) {
+	case ".stm":
+		return FormatStm
+	case ".ctm":
+		return FormatCtm
+	case ".srt":
+		return FormatSrt
+	case ".vtt":
+		return FormatVtt
+	case ".jsonl":
+		return FormatJSONL
+	default:
+		return FormatDelimited
+	}
+}
+
 // normalizeFiles parses the reference and hypotheses files, and normalizes them
 // based on the provided configs. The normalized files are written to the
 // provided output directory; the normalized reference file is named ref.trn,
-// while hypotheses files are named based on their system name.
+// while hypotheses files are named based on their system name. Problems found
+// in the input files are logged and written to validation.json, and returned
+// as an error in strict mode. The validation report is returned along with the
+// normalized files. If refUtts is not nil, it holds the utterances already read
+// from the delimited reference file, which is then not read again.
 func normalizeFiles(
 	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig,
-	outDir, refFile string, hypFiles []sctk.Hypothesis,
-) (string, []sctk.Hypothesis, error) {
+	outDir, refFile string, refUtts []Utt, hypFiles []sctk.Hypothesis,
+) (string, []sctk.Hypothesis, ValidationReport, error) {
 	const (
 		filePerm = 0777
 	)
 
+	var report ValidationReport
+
 	if err := os.MkdirAll(outDir, filePerm); err != nil {
-		return "", nil, fmt.Errorf("failed to create output directory: %w", err)
+		return "", nil, report, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	refFormat := fileFormat.refFormat(refFile)
+
+	for _, hyp := range hypFiles {
+		if !isSubtitleFormat(fileFormat.hypFormat(hyp.FilePath)) {
+			continue
+		}
+
+		switch mode := fileFormat.subtitleMode(refFormat); {
+		case mode == SubtitleOverlap && refFormat != FormatStm:
+			return "", nil, report, fmt.Errorf("subtitles can only be mapped by time overlap onto stm references")
+		case mode == SubtitleConcat && refFormat == FormatStm:
+			return "", nil, report, fmt.Errorf("subtitles can only be concatenated when scored against delimited references")
+		}
+	}
+
+	if refFormat == FormatStm {
+		return normalizeTimedFiles(ctx, fileFormat, cfg, outDir, refFile, hypFiles)
 	}
 
 	// Read reference transcripts.
-	refUtts, err := readTranscriptFile(ctx, refFile, fileFormat)
-	if err != nil {
-		return "", nil, fmt.Errorf("failed to read reference file: %w", err)
+	if refUtts == nil {
+		var err error
+
+		refUtts, err = readTranscriptFile(ctx, refFile, fileFormat)
+		if err != nil {
+			return "", nil, report, fmt.Errorf("failed to read reference file: %w", err)
+		}
+	}
+
+	// Checking inputs for problems before they are normalized.
+	refIDs := report.addRef(refFile, refUtts, cfg)
+
+	if cfg.ScoreFormatting {
+		if err := writeFormattedFile(ctx, refUtts, cfg, path.Join(outDir, "ref"+formattedExt)); err != nil {
+			return "", nil, report, err
+		}
 	}
 
 	// Write normalized reference transcripts into format expected by SCTK.
@@ -93,18 +367,12 @@ func normalizeFiles(
 
 	refNorm := path.Join(outDir, "ref.trn")
 	if err := writeTranscriptFile(ctx, refUtts, refNorm); err != nil {
-		return "", nil, fmt.Errorf("failed to write normalized reference file: %w", err)
-	}
-
-	// Getting the set of reference utt IDs. Will filter utts from hypotheses that
-	// do not have a reference utt.
-	refIDs := make(map[string]struct{})
-	for _, utt := range refUtts {
-		refIDs[utt.ID] = struct{}{}
+		return "", nil, report, fmt.Errorf("failed to write normalized reference file: %w", err)
 	}
 
+	// Will filter utts from hypotheses that do not have a reference utt.
 	if len(refIDs) == 0 {
-		return "", nil, fmt.Errorf("reference file does not contain any utterances")
+		return "", nil, report, fmt.Errorf("reference file does not contain any utterances")
 	}
 
 	// Read hypothesis transcripts and write out normalized version in the format
@@ -112,25 +380,40 @@ func normalizeFiles(
 	normHypFiles := make([]sctk.Hypothesis, 0, len(hypFiles))
 
 	for _, hyp := range hypFiles {
-		hypUtts, err := readTranscriptFile(ctx, hyp.FilePath, fileFormat)
+		hypUtts, err := readHypUtts(ctx, fileFormat, hyp.FilePath)
 		if err != nil {
-			return "", nil, fmt.Errorf("failed to read hypothesis file: %w", err)
+			return "", nil, report, fmt.Errorf("failed to read hypothesis file: %w", err)
+		}
+
+		report.addHyp(hyp.FilePath, hypUtts, refIDs, cfg)
+
+		hypCfg := cfg
+		if isSubtitleFormat(fileFormat.hypFormat(hyp.FilePath)) {
+			hypCfg.StripSubtitleMarkup = true
+		}
+
+		sanitizedName := SanitizeSystemName(hyp.SystemName)
+
+		if cfg.ScoreFormatting {
+			formattedFile := path.Join(outDir, sanitizedName+formattedExt)
+			if err := writeFormattedFile(ctx, hypUtts, hypCfg, formattedFile); err != nil {
+				return "", nil, report, err
+			}
 		}
 
-		normalizeUtts(hypUtts, cfg)
+		normalizeUtts(hypUtts, hypCfg)
 
 		hypUtts = filterUtts(hypUtts, refIDs)
 		if len(hypUtts) == 0 {
-			return "", nil, fmt.Errorf(
+			return "", nil, report, fmt.Errorf(
 				"no utterance IDs in common between reference file and %q", hyp.FilePath,
 			)
 		}
 
-		sanitizedName := sanitizeSystemName(hyp.SystemName)
 		hypNorm := path.Join(outDir, sanitizedName+".trn")
 
 		if err := writeTranscriptFile(ctx, hypUtts, hypNorm); err != nil {
-			return "", nil, fmt.Errorf("failed to write normalized hypothesis file: %w", err)
+			return "", nil, report, fmt.Errorf("failed to write normalized hypothesis file: %w", err)
 		}
 
 		normHypFiles = append(normHypFiles, sctk.Hypothesis{
@@ -139,26 +422,89 @@ func normalizeFiles(
 		})
 	}
 
-	return refNorm, normHypFiles, nil
+	report.log()
+
+	if err := report.write(path.Join(outDir, "validation.json")); err != nil {
+		return "", nil, report, err
+	}
+
+	if fileFormat.Strict {
+		if err := report.Err(); err != nil {
+			return "", nil, report, err
+		}
+	}
+
+	return refNorm, normHypFiles, report, nil
 }
 
 // normalizeUtts applies different normalization processes in-place on the
 // provided list of utts.
 func normalizeUtts(utts []Utt, cfg NormalizeConfig) {
 	for i := range utts {
-		trn := utts[i].Transcript
+		utts[i].Transcript = normalizeText(utts[i].Transcript, cfg)
+	}
+}
 
-		if !cfg.CaseSensitive {
-			trn = strings.ToLower(trn)
-		}
+// normalizeText applies different normalization processes on the provided text
+// and returns the normalized text.
+func normalizeText(trn string, cfg NormalizeConfig) string {
+	// Speaker labels are detected by their case, so markup must be stripped
+	// before lower casing.
+	if cfg.StripSubtitleMarkup {
+		trn = stripSubtitleMarkup(trn)
+	}
 
-		if cfg.NormalizeUnicode {
-			trn = norm.NFC.String(trn)
-			trn = removeZW(trn)
-		}
+	if !cfg.CaseSensitive {
+		trn = strings.ToLower(trn)
+	}
+
+	if cfg.NormalizeUnicode {
+		trn = norm.NFC.String(trn)
+		trn = removeZW(trn)
+	}
+
+	if cfg.Language != "" {
+		trn = normalizeArabicScript(trn, cfg)
+	}
+
+	if cfg.StripPunctuation {
+		trn = stripPunctuation(trn)
+	}
+
+	return trn
+}
+
+var (
+	// subtitleTagRe matches formatting tags such as <i>, <font color="red">,
+	// WebVTT voice and timestamp tags such as <v Bob> and <00:01.000>, and SSA
+	// override tags such as {\an8}.
+	subtitleTagRe = regexp.MustCompile(`<[^>]*>|\{\\[^}]*\}`)
+
+	// subtitleSoundRe matches sound effects and other non-speech annotations in
+	// brackets or parentheses, such as [music] or (laughs), and music notes.
+	subtitleSoundRe = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|[♪♫]`)
 
-		utts[i].Transcript = trn
+	// subtitleSpeakerRe matches dialogue dashes, speaker change markers (>>)
+	// and upper case speaker labels such as "JOHN:" at the start of a line.
+	subtitleSpeakerRe = regexp.MustCompile(`^(-\s*)?(>>+\s*)?(\p{Lu}[\p{Lu}\p{N} .'_-]*:\s*)?`)
+)
+
+// stripSubtitleMarkup removes formatting tags, sound effects, speaker labels
+// and HTML entities from each line of the given subtitle text, and joins the
+// lines with a space.
+func stripSubtitleMarkup(s string) string {
+	lines := strings.Split(s, "\n")
+	words := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		line = subtitleTagRe.ReplaceAllString(line, " ")
+		line = html.UnescapeString(line)
+		line = subtitleSoundRe.ReplaceAllString(line, " ")
+		line = subtitleSpeakerRe.ReplaceAllString(strings.TrimSpace(line), "")
+		words = append(words, strings.Fields(line)...)
 	}
+
+	return strings.Join(words, " ")
 }
 
 // removeZW removes all optional occurrences of ZWNJ or ZWJ from Bangla text.
@@ -223,19 +569,67 @@ func filterUtts(utts []Utt, refIDs map[string]struct{}) []Utt {
 	return utts[:n]
 }
 
+// readHypUtts reads the utterances in the given hypothesis file. Words in ctm
+// files are joined into one utterance per file, with the file name as the
+// utterance ID. Cues in subtitle files are joined into one utterance, with the
+// name of the subtitle file as the utterance ID.
+func readHypUtts(ctx context.Context, fileFormat FileFormat, filePath string) ([]Utt, error) {
+	switch format := fileFormat.hypFormat(filePath); {
+	case isSubtitleFormat(format):
+		return readSubtitleUtt(ctx, filePath)
+	case format != FormatCtm:
+		return readTranscriptFile(ctx, filePath, fileFormat)
+	}
+
+	words, err := readCtmFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return wordsToUtts(words), nil
+}
+
+// readHypWords reads the words in the given hypothesis file. Utterances in
+// delimited files are converted to words with the utterance ID as the file name.
+// Cues in subtitle files are converted to words with the name of the subtitle
+// file as the file name.
+func readHypWords(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig, filePath string,
+) ([]TimedWord, error) {
+	switch format := fileFormat.hypFormat(filePath); {
+	case format == FormatCtm:
+		return readCtmFile(ctx, filePath)
+	case isSubtitleFormat(format):
+		return readSubtitleWords(ctx, filePath, "1", cfg)
+	}
+
+	utts, err := readTranscriptFile(ctx, filePath, fileFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return uttsToWords(utts), nil
+}
+
 // readTranscriptFile reads the utterance data from the given transcript file
-// based on the provided file format.
+// based on the provided file format. If the given path is a directory, the
+// files under it are read with readTranscriptDir instead.
 func readTranscriptFile(
 	ctx context.Context, filePath string, fileFormat FileFormat,
 ) ([]Utt, error) {
-	f, err := os.Open(filePath)
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return readTranscriptDir(ctx, filePath, fileFormat)
+	}
+
+	f, err := fileutils.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read reference file: %w", err)
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
 	}
 
-	defer fileutils.CloseFileOrLog(f)
+	defer fileutils.CloseOrLog(f, filePath)
 
 	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
 	ldx := 0
 
 	if fileFormat.IgnoreFirstRow {
@@ -245,11 +639,23 @@ func readTranscriptFile(
 
 	utts := make([]Utt, 0)
 
+	readRank := fileFormat.NBest && fileFormat.ColRank >= 0
+
 	maxColsExpected := fileFormat.ColTrn
 	if maxColsExpected < fileFormat.ColID {
 		maxColsExpected = fileFormat.ColID
 	}
 
+	if readRank && maxColsExpected < fileFormat.ColRank {
+		maxColsExpected = fileFormat.ColRank
+	}
+
+	readSpeaker := fileFormat.Sessions && fileFormat.ColSpeaker >= 0
+
+	if readSpeaker && maxColsExpected < fileFormat.ColSpeaker {
+		maxColsExpected = fileFormat.ColSpeaker
+	}
+
 	// Length is 1 greater than zero-based index.
 	maxColsExpected += 1
 
@@ -276,8 +682,31 @@ func readTranscriptFile(
 			)
 		}
 
-		ID, trn := sanitizeUttID(parts[fileFormat.ColID]), parts[fileFormat.ColTrn]
-		utts = append(utts, Utt{ID, trn})
+		rawID, trn := parts[fileFormat.ColID], parts[fileFormat.ColTrn]
+		utt := Utt{ID: sanitizeUttID(rawID), Transcript: trn, line: ldx, rawID: rawID}
+
+		if readRank {
+			rank, err := strconv.Atoi(strings.TrimSpace(parts[fileFormat.ColRank]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid rank %q on line %d", parts[fileFormat.ColRank], ldx)
+			}
+
+			utt.rank = rank
+		}
+
+		if readSpeaker {
+			utt.speaker = strings.TrimSpace(parts[fileFormat.ColSpeaker])
+		}
+
+		utts = append(utts, utt)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d is longer than %d bytes", ldx+1, maxScanLineLen)
+		}
+
+		return nil, fmt.Errorf("failed to read transcript file: %w", err)
 	}
 
 	return utts, nil
@@ -317,13 +746,52 @@ func writeTranscriptFile(
 	return nil
 }
 
-// sanitizeSystemName converts the given string representing a system name to all
+// SanitizeSystemName converts the given string representing a system name to all
 // lower case, and replaces and spaces with underscores.
-func sanitizeSystemName(name string) string {
+func SanitizeSystemName(name string) string {
 	parts := strings.Fields(strings.ToLower(name))
 	return strings.Join(parts, "_")
 }
 
+// ValidateSystemName returns an error if the given system name, once sanitized,
+// cannot be used to name the files of the system in the output directory:
+// names that are empty, contain path separators, or clash with the files of the
+// reference.
+func ValidateSystemName(name string) error {
+	switch sanitized := SanitizeSystemName(name); {
+	case sanitized == "":
+		return fmt.Errorf("system name %q is empty", name)
+	case sanitized == "." || sanitized == ".." || strings.ContainsAny(sanitized, `/\`):
+		return fmt.Errorf("system name %q must not contain path separators", name)
+	case sanitized == "ref":
+		return fmt.Errorf("system name %q is reserved for the reference", name)
+	}
+
+	return nil
+}
+
+// ValidateSystemNames validates each of the given system names, and returns an
+// error if any two of them are the same once sanitized, since their files in
+// the output directory would overwrite each other.
+func ValidateSystemNames(names []string) error {
+	seen := make(map[string]string, len(names))
+
+	for _, name := range names {
+		if err := ValidateSystemName(name); err != nil {
+			return err
+		}
+
+		sanitized := SanitizeSystemName(name)
+		if prev, ok := seen[sanitized]; ok {
+			return fmt.Errorf("system names %q and %q are the same once sanitized", prev, name)
+		}
+
+		seen[sanitized] = name
+	}
+
+	return nil
+}
+
 // sanitizeUttID converts the given string representing an utterance ID by
 // replacing spaces with underscores
 func sanitizeUttID(ID string) string {
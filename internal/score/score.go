@@ -20,24 +20,157 @@ package score
 import (
 	"context"
 	"fmt"
+	"path"
+	"time"
 
+	"github.com/shahruk10/go-sctk/internal/gate"
 	"github.com/shahruk10/go-sctk/internal/sctk"
 )
 
+const (
+	// ModeUtterance scores hypotheses against the reference utterance by
+	// utterance, with sclite; see Score.
+	ModeUtterance = "utterance"
+	// ModeSession scores sessions with several speakers, whose hypothesis
+	// speaker labels need not match those of the reference; see ScoreSessions.
+	ModeSession = "session"
+	// ModeOverlap scores ctm hypotheses against stm references with overlapping
+	// speech, aligning them with asclite; see sctk.ScliteCfg.Overlap.
+	ModeOverlap = "overlap"
+)
+
+// Options are the optional scoring steps run by Run once the hypotheses have
+// been aligned against the reference.
+type Options struct {
+	// TermList is the path to a list of terms scored with ScoreTerms, and
+	// WeightTable the path to a table of word costs with which weighted error
+	// rates are computed with ScoreWeighted; skipped if empty.
+	TermList    string
+	WeightTable string
+
+	// Gates are the quality gates checked once all reports have been written.
+	Gates gate.Config
+}
+
+// Score normalizes the reference and hypotheses files and scores the hypotheses
+// with sclite, writing the normalized files and reports to the output
+// directory. A manifest recording the configuration and inputs of the run is
+// written to run.json in the output directory, so that it can be reproduced
+// with Rerun.
+//
+// If fileFormat.NBest is set, the hypotheses are N-best lists. The 1-best
+// transcripts of each are scored under its system name, and the oracle
+// transcripts, those closest to the reference, as an extra system named with
+// OracleSuffix. The 1-best and oracle error rates and the ranks of the oracle
+// transcripts are summarized in <system>.nbest.json.
+//
+// If normCfg.ScoreFormatting is set, the case and punctuation error rates of
+// the hypotheses are written to formatting.json.
 func Score(
 	ctx context.Context, fileFormat FileFormat, normCfg NormalizeConfig, scliteCfg sctk.ScliteCfg,
 	outDir, refFile string, hypFiles []sctk.Hypothesis,
 ) error {
-	normRef, normHypFiles, err := normalizeFiles(
-		ctx, fileFormat, normCfg, outDir, refFile, hypFiles,
+	_, err := Run(ctx, fileFormat, normCfg, scliteCfg, Options{}, outDir, refFile, hypFiles)
+	return err
+}
+
+// Run scores the hypotheses like Score, followed by the optional steps in opts,
+// all of which are recorded in the run manifest. If quality gates are
+// configured, their results are returned, along with a *gate.FailedError if any
+// failed.
+func Run(
+	ctx context.Context, fileFormat FileFormat, normCfg NormalizeConfig, scliteCfg sctk.ScliteCfg,
+	opts Options, outDir, refFile string, hypFiles []sctk.Hypothesis,
+) ([]gate.Result, error) {
+	if normCfg.ScoreFormatting {
+		if err := checkFormatting(fileFormat, scliteCfg, refFile); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := newManifest(ctx, fileFormat, normCfg, scliteCfg, opts, outDir, refFile, hypFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run manifest: %w", err)
+	}
+
+	var refUtts []Utt
+
+	// Quality gates are only checked for the given hypotheses, and not for the
+	// oracle transcripts of N-best lists.
+	gated := make(map[string]bool, len(hypFiles))
+	for _, hyp := range hypFiles {
+		gated[SanitizeSystemName(hyp.SystemName)] = true
+	}
+
+	if fileFormat.NBest {
+		hypFiles, refUtts, err = prepareNBest(ctx, fileFormat, normCfg, scliteCfg.CER, outDir, refFile, hypFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		// The 1-best and oracle transcripts are scored as plain delimited files.
+		fileFormat.NBest, fileFormat.HypFormat = false, FormatDelimited
+	}
+
+	normRef, normHypFiles, report, err := normalizeFiles(
+		ctx, fileFormat, normCfg, outDir, refFile, refUtts, hypFiles,
 	)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if fileFormat.refFormat(refFile) == FormatStm {
+		scliteCfg.RefFormat = sctk.FormatStm
+	} else if scliteCfg.Overlap {
+		return nil, fmt.Errorf("overlapping speech can only be scored against %s references", FormatStm)
 	}
 
 	if err := sctk.RunSclite(ctx, scliteCfg, outDir, normRef, normHypFiles); err != nil {
-		return fmt.Errorf("failed to run sclite: %w", err)
+		return nil, fmt.Errorf("failed to run sclite: %w", err)
+	}
+
+	if normCfg.ScoreFormatting {
+		if err := scoreFormatting(normCfg, outDir, normRef, normHypFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.TermList != "" {
+		summary, err := ScoreTerms(opts.TermList, normCfg, scliteCfg.CER, outDir)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := linkTermsReport(outDir, normHypFiles); err != nil {
+			return nil, err
+		}
+
+		manifest.setTerms(summary)
+	}
+
+	if opts.WeightTable != "" {
+		if _, err := ScoreWeighted(opts.WeightTable, normCfg, outDir); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest.setCounts(report)
+	manifest.EndTime = time.Now()
+
+	if err := manifest.write(path.Join(outDir, ManifestFile)); err != nil {
+		return nil, err
+	}
+
+	if !opts.Gates.Enabled() {
+		return nil, nil
+	}
+
+	alignmentFiles := make([]string, 0, len(normHypFiles))
+	for _, hyp := range normHypFiles {
+		if gated[hyp.SystemName] {
+			alignmentFiles = append(alignmentFiles, path.Join(outDir, path.Base(hyp.FilePath)+".pra."+sctk.AlignmentFormatJSON))
+		}
 	}
 
-	return nil
+	return gate.Check(opts.Gates, scliteCfg.CER, alignmentFiles)
 }
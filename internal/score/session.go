@@ -0,0 +1,566 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+const (
+	// SessionsFile is the name of the file in the output directory to which
+	// the speaker attributed error rates of sessions are written.
+	SessionsFile = "sessions.json"
+
+	// ignoreSegment is the transcript of stm segments excluded from scoring.
+	ignoreSegment = "ignore_time_segment_in_scoring"
+
+	// maxORCStates and maxORCOps bound the memory and time used to compute the
+	// ORC error rate of a session, as the number of states of the dynamic
+	// program and the number of updates of those states. Sessions exceeding
+	// either are skipped.
+	maxORCStates = 1 << 24
+	maxORCOps    = 1e10
+)
+
+// SpeakerMatch is a reference speaker paired with a hypothesis speaker by the
+// cpWER speaker mapping. Either speaker is empty if the other is not paired,
+// in which case all its words are deletions or insertions.
+type SpeakerMatch struct {
+	Ref      string `json:"ref,omitempty"`
+	Hyp      string `json:"hyp,omitempty"`
+	RefWords int    `json:"ref_words"`
+	HypWords int    `json:"hyp_words"`
+	Errors   int    `json:"errors"`
+}
+
+// SessionScore holds the speaker attributed error rates of a session.
+//
+// The concatenated minimum-permutation error rate (cpWER) concatenates the
+// words of each speaker, and pairs reference and hypothesis speakers so that
+// the total edit distance between paired speakers is the lowest. Mapping lists
+// the pairs.
+//
+// The optimal reference combination error rate (ORC-WER) ignores hypothesis
+// speaker labels, and assigns each reference segment to one hypothesis speaker
+// so that the total edit distance between the segments assigned to each
+// speaker and its words is the lowest. It is nil if the session was too large
+// to compute it; see maxORCStates.
+type SessionScore struct {
+	Session     string         `json:"session"`
+	RefSpeakers int            `json:"ref_speakers"`
+	HypSpeakers int            `json:"hyp_speakers"`
+	RefWords    int            `json:"ref_words"`
+	CpErrors    int            `json:"cp_errors"`
+	CpErrorRate float64        `json:"cp_error_rate"`
+	Mapping     []SpeakerMatch `json:"mapping"`
+
+	ORCErrors    *int     `json:"orc_errors,omitempty"`
+	ORCErrorRate *float64 `json:"orc_error_rate,omitempty"`
+}
+
+// SessionSummary holds the speaker attributed error rates of the sessions of a
+// hypothesis file, and their totals. The ORC totals only cover the sessions for
+// which it was computed; the others are counted by ORCSkipped.
+type SessionSummary struct {
+	System      string         `json:"system"`
+	Sessions    []SessionScore `json:"sessions"`
+	RefWords    int            `json:"ref_words"`
+	CpErrors    int            `json:"cp_errors"`
+	CpErrorRate float64        `json:"cp_error_rate"`
+
+	ORCRefWords  int     `json:"orc_ref_words"`
+	ORCErrors    int     `json:"orc_errors"`
+	ORCErrorRate float64 `json:"orc_error_rate"`
+	ORCSkipped   int     `json:"orc_skipped,omitempty"`
+}
+
+// A sessionSegment is a segment of a session spoken by one speaker, with its
+// normalized scoring tokens.
+type sessionSegment struct {
+	speaker string
+	start   float64
+	tokens  []string
+}
+
+// ScoreSessions computes the cpWER and ORC-WER of each session in the given
+// hypothesis files against the reference, which are read as sessions with
+// several speakers; see FileFormat.Sessions and SessionScore. Transcripts are
+// normalized as in Score, and errors are counted by word edit distance, or by
+// character edit distance ignoring spaces when cer is true. Sessions missing
+// from a hypothesis are scored as deleted; sessions missing from the reference
+// are ignored. The scores are written to sessions.json in the output
+// directory, along with a run manifest as in Score.
+func ScoreSessions(
+	ctx context.Context, fileFormat FileFormat, normCfg NormalizeConfig, cer bool,
+	outDir, refFile string, hypFiles []sctk.Hypothesis,
+) ([]SessionSummary, error) {
+	const (
+		filePerm = 0777
+	)
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	fileFormat.Sessions = true
+
+	manifest, err := newManifest(
+		ctx, fileFormat, normCfg, sctk.ScliteCfg{CER: cer}, Options{}, outDir, refFile, hypFiles,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run manifest: %w", err)
+	}
+
+	refSessions, err := readSessions(ctx, fileFormat, normCfg, cer, refFile, fileFormat.refFormat(refFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	ids := textutils.SortedKeys(refSessions)
+
+	summaries := make([]SessionSummary, 0, len(hypFiles))
+
+	for _, hyp := range hypFiles {
+		hypSessions, err := readSessions(ctx, fileFormat, normCfg, cer, hyp.FilePath, fileFormat.hypFormat(hyp.FilePath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hypothesis file: %w", err)
+		}
+
+		summary := SessionSummary{System: SanitizeSystemName(hyp.SystemName)}
+
+		for id := range hypSessions {
+			if _, ok := refSessions[id]; !ok {
+				logrus.WithFields(logrus.Fields{
+					"system":  summary.System,
+					"session": id,
+				}).Warn("ignoring session missing from reference")
+			}
+		}
+
+		for _, id := range ids {
+			s := scoreSession(id, refSessions[id], hypSessions[id])
+
+			summary.RefWords += s.RefWords
+			summary.CpErrors += s.CpErrors
+
+			if s.ORCErrors == nil {
+				logrus.WithFields(logrus.Fields{
+					"system":  summary.System,
+					"session": id,
+				}).Warn("session is too large to compute ORC error rate, skipping")
+
+				summary.ORCSkipped++
+			} else {
+				summary.ORCRefWords += s.RefWords
+				summary.ORCErrors += *s.ORCErrors
+			}
+
+			summary.Sessions = append(summary.Sessions, s)
+		}
+
+		summary.CpErrorRate = sctk.ErrorRate(summary.CpErrors, summary.RefWords)
+		summary.ORCErrorRate = sctk.ErrorRate(summary.ORCErrors, summary.ORCRefWords)
+
+		logrus.WithFields(logrus.Fields{
+			"system":         summary.System,
+			"sessions":       len(summary.Sessions),
+			"cp_error_rate":  fmt.Sprintf("%.2f%%", summary.CpErrorRate),
+			"orc_error_rate": fmt.Sprintf("%.2f%%", summary.ORCErrorRate),
+		}).Info("scored sessions")
+
+		summaries = append(summaries, summary)
+	}
+
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session scores: %w", err)
+	}
+
+	if err := os.WriteFile(path.Join(outDir, SessionsFile), data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return nil, fmt.Errorf("failed to write session scores: %w", err)
+	}
+
+	manifest.EndTime = time.Now()
+
+	if err := manifest.write(path.Join(outDir, ManifestFile)); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// readSessions reads the segments of each session in the given file, of the
+// given format, normalizing their transcripts. Segments of stm and ctm files
+// are sorted by start time; those of delimited files are kept in the order
+// they appear in.
+func readSessions(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig, cer bool, filePath, format string,
+) (map[string][]sessionSegment, error) {
+	sessions := make(map[string][]sessionSegment)
+
+	add := func(session, speaker string, start float64, trn string) {
+		sessions[session] = append(sessions[session], sessionSegment{
+			speaker: speaker,
+			start:   start,
+			tokens:  scoringTokens(normalizeText(trn, cfg), cer),
+		})
+	}
+
+	switch format {
+	case FormatDelimited:
+		if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+			return nil, fmt.Errorf("directories can not be scored as sessions: %q", filePath)
+		}
+
+		if fileFormat.ColSpeaker < 0 {
+			return nil, fmt.Errorf("delimited files can only be scored as sessions with a speaker column")
+		}
+
+		utts, err := readTranscriptFile(ctx, filePath, fileFormat)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, utt := range utts {
+			if utt.speaker == "" {
+				return nil, fmt.Errorf("missing speaker on line %d", utt.line)
+			}
+
+			add(utt.ID, utt.speaker, 0, utt.Transcript)
+		}
+
+		return sessions, nil
+
+	case FormatStm:
+		segs, err := readStmFile(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, seg := range segs {
+			if strings.EqualFold(seg.Transcript, ignoreSegment) {
+				continue
+			}
+
+			add(seg.File, seg.Speaker, seg.Start, seg.Transcript)
+		}
+
+	case FormatCtm:
+		words, err := readCtmFile(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, w := range words {
+			add(w.File, w.Channel, w.Start, w.Word)
+		}
+
+	default:
+		return nil, fmt.Errorf("%s files can not be scored as sessions", format)
+	}
+
+	for _, segs := range sessions {
+		sort.SliceStable(segs, func(i, j int) bool { return segs[i].start < segs[j].start })
+	}
+
+	return sessions, nil
+}
+
+// speakerStreams returns the speakers of the given segments in sorted order,
+// along with the concatenated tokens of each.
+func speakerStreams(segs []sessionSegment) ([]string, [][]string) {
+	tokens := make(map[string][]string)
+	for _, seg := range segs {
+		tokens[seg.speaker] = append(tokens[seg.speaker], seg.tokens...)
+	}
+
+	speakers := textutils.SortedKeys(tokens)
+
+	streams := make([][]string, len(speakers))
+	for i, spk := range speakers {
+		streams[i] = tokens[spk]
+	}
+
+	return speakers, streams
+}
+
+// scoreSession computes the cpWER and ORC-WER of a session, given its
+// reference and hypothesis segments.
+func scoreSession(id string, refSegs, hypSegs []sessionSegment) SessionScore {
+	refSpeakers, refStreams := speakerStreams(refSegs)
+	hypSpeakers, hypStreams := speakerStreams(hypSegs)
+
+	s := SessionScore{
+		Session:     id,
+		RefSpeakers: len(refSpeakers),
+		HypSpeakers: len(hypSpeakers),
+	}
+
+	// Speakers without a counterpart are paired with empty ones, making the
+	// cost matrix square.
+	n := len(refSpeakers)
+	if n < len(hypSpeakers) {
+		n = len(hypSpeakers)
+	}
+
+	stream := func(streams [][]string, i int) []string {
+		if i < len(streams) {
+			return streams[i]
+		}
+
+		return nil
+	}
+
+	costs := make([][]int, n)
+	for i := range costs {
+		costs[i] = make([]int, n)
+		for j := range costs[i] {
+			costs[i][j] = textutils.EditDistance(stream(refStreams, i), stream(hypStreams, j))
+		}
+	}
+
+	for i, j := range minCostAssignment(costs) {
+		m := SpeakerMatch{
+			RefWords: len(stream(refStreams, i)),
+			HypWords: len(stream(hypStreams, j)),
+			Errors:   costs[i][j],
+		}
+
+		if i < len(refSpeakers) {
+			m.Ref = refSpeakers[i]
+		}
+
+		if j < len(hypSpeakers) {
+			m.Hyp = hypSpeakers[j]
+		}
+
+		s.RefWords += m.RefWords
+		s.CpErrors += m.Errors
+		s.Mapping = append(s.Mapping, m)
+	}
+
+	s.CpErrorRate = sctk.ErrorRate(s.CpErrors, s.RefWords)
+
+	refUtts := make([][]string, 0, len(refSegs))
+	for _, seg := range refSegs {
+		if len(seg.tokens) > 0 {
+			refUtts = append(refUtts, seg.tokens)
+		}
+	}
+
+	if errs, ok := orcErrors(refUtts, hypStreams); ok {
+		rate := sctk.ErrorRate(errs, s.RefWords)
+		s.ORCErrors, s.ORCErrorRate = &errs, &rate
+	}
+
+	return s
+}
+
+// minCostAssignment solves the assignment problem for the given square cost
+// matrix with the Hungarian algorithm, returning the column assigned to each
+// row such that the sum of their costs is the lowest.
+func minCostAssignment(costs [][]int) []int {
+	n := len(costs)
+	inf := math.MaxInt / 2 //nolint: gomnd // headroom for adding costs.
+
+	// Potentials of rows and columns, the row assigned to each column, and the
+	// previous column on the augmenting path; all one-based, with column 0 as
+	// the start of the path.
+	u, v := make([]int, n+1), make([]int, n+1)
+	rowOf, way := make([]int, n+1), make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		rowOf[0] = i
+		col := 0
+
+		minv := make([]int, n+1)
+		used := make([]bool, n+1)
+
+		for j := range minv {
+			minv[j] = inf
+		}
+
+		for rowOf[col] != 0 {
+			used[col] = true
+			row, delta, next := rowOf[col], inf, 0
+
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+
+				if c := costs[row-1][j-1] - u[row] - v[j]; c < minv[j] {
+					minv[j], way[j] = c, col
+				}
+
+				if minv[j] < delta {
+					delta, next = minv[j], j
+				}
+			}
+
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[rowOf[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+
+			col = next
+		}
+
+		for col != 0 {
+			prev := way[col]
+			rowOf[col] = rowOf[prev]
+			col = prev
+		}
+	}
+
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[rowOf[j]-1] = j - 1
+	}
+
+	return assignment
+}
+
+// orcErrors returns the lowest number of errors over all assignments of the
+// given reference utterances to the hypothesis streams, where the errors of an
+// assignment are the edit distances between each stream and the utterances
+// assigned to it, concatenated in order. The second return value is false if
+// the dynamic program is too large to solve; see maxORCStates and maxORCOps.
+//
+// The states of the dynamic program are the positions reached in each stream,
+// after aligning a prefix of the reference utterances.
+func orcErrors(refUtts [][]string, streams [][]string) (int, bool) {
+	refWords := 0
+	for _, utt := range refUtts {
+		refWords += len(utt)
+	}
+
+	if len(streams) == 0 {
+		return refWords, true
+	}
+
+	strides := make([]int, len(streams))
+	numStates := 1
+
+	for k, stream := range streams {
+		if numStates > maxORCStates/(len(stream)+1) {
+			return 0, false
+		}
+
+		strides[k] = numStates
+		numStates *= len(stream) + 1
+	}
+
+	if float64(len(streams))*float64(refWords+len(refUtts))*float64(numStates) > maxORCOps {
+		return 0, false
+	}
+
+	const inf = math.MaxInt32 / 2
+
+	pos := func(state, k int) int {
+		return state / strides[k] % (len(streams[k]) + 1)
+	}
+
+	// insertAll lets the words of every stream be inserted between utterances.
+	insertAll := func(dist []int32) {
+		for k := range streams {
+			for state := range dist {
+				if pos(state, k) > 0 && dist[state-strides[k]]+1 < dist[state] {
+					dist[state] = dist[state-strides[k]] + 1
+				}
+			}
+		}
+	}
+
+	dist := make([]int32, numStates)
+	best := make([]int32, numStates)
+	curr := make([]int32, numStates)
+	next := make([]int32, numStates)
+
+	for state := range dist {
+		dist[state] = inf
+	}
+
+	dist[0] = 0
+	insertAll(dist)
+
+	for _, utt := range refUtts {
+		for state := range best {
+			best[state] = inf
+		}
+
+		// Align the utterance against each stream in turn, from the positions
+		// reached so far, keeping the best over all streams.
+		for k, stream := range streams {
+			copy(curr, dist)
+
+			for _, word := range utt {
+				for state := range next {
+					d := curr[state] + 1
+
+					if p := pos(state, k); p > 0 {
+						prev := state - strides[k]
+
+						sub := curr[prev]
+						if stream[p-1] != word {
+							sub++
+						}
+
+						if sub < d {
+							d = sub
+						}
+
+						if next[prev]+1 < d {
+							d = next[prev] + 1
+						}
+					}
+
+					next[state] = d
+				}
+
+				curr, next = next, curr
+			}
+
+			for state, d := range curr {
+				if d < best[state] {
+					best[state] = d
+				}
+			}
+		}
+
+		dist, best = best, dist
+		insertAll(dist)
+	}
+
+	return int(dist[numStates-1]), true
+}
@@ -0,0 +1,245 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestScoreSessions(t *testing.T) {
+	t.Parallel()
+
+	intPtr := func(v int) *int { return &v }
+	floatPtr := func(v float64) *float64 { return &v }
+
+	testCases := []struct {
+		name        string
+		refFile     string
+		hypFile     string
+		colSpeaker  int
+		wantSummary SessionSummary
+		wantErr     bool
+	}{
+		{
+			// Hypothesis speaker labels differ from the reference, and a
+			// hypothesis speaker has no counterpart in meet2.
+			name:       "relabeled",
+			refFile:    "testdata/sessions/ref.csv",
+			hypFile:    "testdata/sessions/hyp1.csv",
+			colSpeaker: 1,
+			wantSummary: SessionSummary{
+				System: "hyp1",
+				Sessions: []SessionScore{
+					{
+						Session: "meet1", RefSpeakers: 2, HypSpeakers: 2, RefWords: 10,
+						CpErrors: 1, CpErrorRate: 10,
+						Mapping: []SpeakerMatch{
+							{Ref: "alice", Hyp: "spk_b", RefWords: 6, HypWords: 6, Errors: 1},
+							{Ref: "bob", Hyp: "spk_a", RefWords: 4, HypWords: 4},
+						},
+						ORCErrors: intPtr(1), ORCErrorRate: floatPtr(10),
+					},
+					{
+						Session: "meet2", RefSpeakers: 1, HypSpeakers: 2, RefWords: 2,
+						CpErrors: 1, CpErrorRate: 50,
+						Mapping: []SpeakerMatch{
+							{Ref: "carol", Hyp: "spk_x", RefWords: 2, HypWords: 2},
+							{Hyp: "spk_y", HypWords: 1, Errors: 1},
+						},
+						ORCErrors: intPtr(1), ORCErrorRate: floatPtr(50),
+					},
+				},
+				RefWords: 12, CpErrors: 2, CpErrorRate: 100 * 2.0 / 12,
+				ORCRefWords: 12, ORCErrors: 2, ORCErrorRate: 100 * 2.0 / 12,
+			},
+		},
+		{
+			// Utterances of both reference speakers are attributed to one
+			// hypothesis speaker, which only ORC-WER forgives; meet2 is missing.
+			name:       "speakerConfusion",
+			refFile:    "testdata/sessions/ref.csv",
+			hypFile:    "testdata/sessions/hyp2.csv",
+			colSpeaker: 1,
+			wantSummary: SessionSummary{
+				System: "hyp1",
+				Sessions: []SessionScore{
+					{
+						Session: "meet1", RefSpeakers: 2, HypSpeakers: 2, RefWords: 10,
+						CpErrors: 6, CpErrorRate: 60,
+						Mapping: []SpeakerMatch{
+							{Ref: "alice", Hyp: "spk_b", RefWords: 6, HypWords: 3, Errors: 3},
+							{Ref: "bob", Hyp: "spk_a", RefWords: 4, HypWords: 7, Errors: 3},
+						},
+						ORCErrors: intPtr(0), ORCErrorRate: floatPtr(0),
+					},
+					{
+						Session: "meet2", RefSpeakers: 1, RefWords: 2,
+						CpErrors: 2, CpErrorRate: 100,
+						Mapping: []SpeakerMatch{
+							{Ref: "carol", RefWords: 2, Errors: 2},
+						},
+						ORCErrors: intPtr(2), ORCErrorRate: floatPtr(100),
+					},
+				},
+				RefWords: 12, CpErrors: 8, CpErrorRate: 100 * 8.0 / 12,
+				ORCRefWords: 12, ORCErrors: 2, ORCErrorRate: 100 * 2.0 / 12,
+			},
+		},
+		{
+			name:       "stmCtm",
+			refFile:    "testdata/sessions/ref.stm",
+			hypFile:    "testdata/sessions/hyp.ctm",
+			colSpeaker: -1,
+			wantSummary: SessionSummary{
+				System: "hyp1",
+				Sessions: []SessionScore{
+					{
+						Session: "meet1", RefSpeakers: 2, HypSpeakers: 2, RefWords: 10,
+						CpErrors: 1, CpErrorRate: 10,
+						Mapping: []SpeakerMatch{
+							{Ref: "alice", Hyp: "B", RefWords: 6, HypWords: 6, Errors: 1},
+							{Ref: "bob", Hyp: "A", RefWords: 4, HypWords: 4},
+						},
+						ORCErrors: intPtr(1), ORCErrorRate: floatPtr(10),
+					},
+				},
+				RefWords: 10, CpErrors: 1, CpErrorRate: 10,
+				ORCRefWords: 10, ORCErrors: 1, ORCErrorRate: 10,
+			},
+		},
+		{
+			name:       "noSpeakerColumn",
+			refFile:    "testdata/sessions/ref.csv",
+			hypFile:    "testdata/sessions/hyp1.csv",
+			colSpeaker: -1,
+			wantErr:    true,
+		},
+		{
+			name:       "missingSpeaker",
+			refFile:    "testdata/sessions/ref.csv",
+			hypFile:    "testdata/sessions/nospeaker.csv",
+			colSpeaker: 1,
+			wantErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			fileFormat := FileFormat{
+				Delimiter: ',', ColTrn: 2, IgnoreFirstRow: true, Sessions: true, ColSpeaker: tc.colSpeaker,
+			}
+			hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: tc.hypFile}}
+
+			_, err := ScoreSessions(
+				context.Background(), fileFormat, NormalizeConfig{}, false, outDir, tc.refFile, hypFiles,
+			)
+			if tc.wantErr {
+				if err == nil {
+					subT.Fatalf("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			data, err := os.ReadFile(path.Join(outDir, SessionsFile))
+			if err != nil {
+				subT.Fatalf("failed to read session scores: %v", err)
+			}
+
+			var got []SessionSummary
+			if err := json.Unmarshal(data, &got); err != nil {
+				subT.Fatalf("failed to decode session scores: %v", err)
+			}
+
+			want := []SessionSummary{tc.wantSummary}
+			if diff := cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				subT.Errorf("unexpected session scores, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestORCErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		refUtts [][]string
+		streams [][]string
+		want    int
+	}{
+		{
+			name:    "noStreams",
+			refUtts: [][]string{{"a", "b"}, {"c"}},
+			want:    3,
+		},
+		{
+			name:    "interleaved",
+			refUtts: [][]string{{"a", "b"}, {"x"}, {"c"}, {"y", "z"}},
+			streams: [][]string{{"a", "b", "c"}, {"x", "y", "z"}},
+			want:    0,
+		},
+		{
+			// The inserted word "q" is best placed between the utterances
+			// assigned to the first stream.
+			name:    "insertionsBetweenUtterances",
+			refUtts: [][]string{{"a"}, {"x"}, {"b"}},
+			streams: [][]string{{"a", "q", "b"}, {"x", "w"}},
+			want:    2,
+		},
+		{
+			name:    "substitutions",
+			refUtts: [][]string{{"a", "b"}, {"x"}},
+			streams: [][]string{{"x"}, {"a", "c"}},
+			want:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got, ok := orcErrors(tc.refUtts, tc.streams)
+			if !ok {
+				subT.Fatalf("expected ORC errors to be computed")
+			}
+
+			if got != tc.want {
+				subT.Errorf("unexpected ORC errors, want=%d, got=%d", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+)
+
+// Modes for scoring subtitle hypotheses.
+const (
+	// SubtitleConcat concatenates all cues of a subtitle file into one
+	// utterance, with the file name (without extension) as the utterance ID.
+	SubtitleConcat = "concat"
+	// SubtitleOverlap maps the words of each cue onto the segments of a stm
+	// reference by time overlap. The file name (without extension) must match
+	// the file column of the stm reference.
+	SubtitleOverlap = "overlap"
+)
+
+// A Cue is a single caption in a subtitle file, shown between the start and
+// end times in seconds.
+type Cue struct {
+	Start float64
+	End   float64
+
+	// Text of the cue, with lines separated by new lines. Markup is not
+	// stripped.
+	Text string
+}
+
+// isSubtitleFormat returns true if the given format is a subtitle format.
+func isSubtitleFormat(format string) bool {
+	return format == FormatSrt || format == FormatVtt
+}
+
+// subtitleFileID returns the ID of the given subtitle file, which is the file
+// name without its extension.
+func subtitleFileID(filePath string) string {
+	base := path.Base(fileutils.TrimCompressedExt(filePath))
+	return sanitizeUttID(strings.TrimSuffix(base, path.Ext(base)))
+}
+
+// readSubtitleUtt reads the cues in the given subtitle file and concatenates
+// them into one utterance.
+func readSubtitleUtt(ctx context.Context, filePath string) ([]Utt, error) {
+	cues, err := readSubtitleFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(cues))
+	for _, c := range cues {
+		lines = append(lines, c.Text)
+	}
+
+	return []Utt{{ID: subtitleFileID(filePath), Transcript: strings.Join(lines, "\n")}}, nil
+}
+
+// readSubtitleWords reads the cues in the given subtitle file and converts them
+// into timed words on the given channel. Markup is stripped from each cue, and
+// the duration of the cue is divided equally among its words.
+func readSubtitleWords(
+	ctx context.Context, filePath, channel string, cfg NormalizeConfig,
+) ([]TimedWord, error) {
+	cues, err := readSubtitleFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.StripSubtitleMarkup = true
+	fileID := subtitleFileID(filePath)
+	words := make([]TimedWord, 0, len(cues))
+
+	for _, c := range cues {
+		cueWords := strings.Fields(normalizeText(c.Text, cfg))
+		if len(cueWords) == 0 {
+			continue
+		}
+
+		dur := (c.End - c.Start) / float64(len(cueWords))
+
+		for i, w := range cueWords {
+			words = append(words, TimedWord{
+				File:     fileID,
+				Channel:  channel,
+				Start:    roundMillis(c.Start + float64(i)*dur),
+				Duration: roundMillis(dur),
+				Word:     w,
+			})
+		}
+	}
+
+	return words, nil
+}
+
+// roundMillis rounds the given time in seconds to the nearest millisecond, the
+// resolution of subtitle timestamps.
+func roundMillis(t float64) float64 {
+	return math.Round(t*1000) / 1000 //nolint: gomnd // milliseconds in a second.
+}
+
+// subtitleTimingRe matches the timing line of a cue in the form
+// "<start> --> <end> [<settings>]", where timestamps are in the form
+// "[hh:]mm:ss[,.]mmm".
+var subtitleTimingRe = regexp.MustCompile(
+	`^((?:\d+:)?\d{2}:\d{2}[,.]\d{3})[ \t]+-->[ \t]+((?:\d+:)?\d{2}:\d{2}[,.]\d{3})(?:[ \t].*)?$`,
+)
+
+// readSubtitleFile reads the cues in the given SubRip (srt) or WebVTT (vtt)
+// file. Both formats consist of blocks separated by blank lines; cue blocks
+// start with an optional identifier line, followed by a timing line in the form
+// "<start> --> <end> [<settings>]" and one or more lines of text. Only the
+// first or second line of a block is read as a timing line, so cue text may
+// contain "-->". Blocks without a timing line, such as the WEBVTT header, NOTE,
+// STYLE and REGION blocks, are skipped.
+func readSubtitleFile(ctx context.Context, filePath string) ([]Cue, error) {
+	const (
+		timingSep = "-->"
+		utf8BOM   = "\uFEFF"
+	)
+
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitle file: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitle file: %w", err)
+	}
+
+	text := strings.TrimPrefix(string(data), utf8BOM)
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	cues := make([]Cue, 0)
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+
+		// Reading the block starting on this line, until the next blank line.
+		block := make([]string, 0)
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			block = append(block, strings.TrimSpace(lines[i]))
+		}
+
+		blockStart := i - len(block)
+
+		if isSubtitleMetaBlock(block[0]) {
+			continue
+		}
+
+		// The timing line is either the first line of the block, or the second
+		// if the cue has an identifier.
+		timing := -1
+		for j := 0; j < len(block) && j < 2; j++ {
+			if strings.Contains(block[j], timingSep) {
+				timing = j
+				break
+			}
+		}
+
+		if timing < 0 {
+			continue
+		}
+
+		m := subtitleTimingRe.FindStringSubmatch(block[timing])
+		if m == nil {
+			return nil, fmt.Errorf(
+				"invalid cue timing on line %d: %q", blockStart+timing+1, block[timing],
+			)
+		}
+
+		var c Cue
+
+		if c.Start, err = parseSubtitleTime(m[1]); err != nil {
+			return nil, fmt.Errorf("invalid cue start time on line %d: %w", blockStart+timing+1, err)
+		}
+
+		if c.End, err = parseSubtitleTime(m[2]); err != nil {
+			return nil, fmt.Errorf("invalid cue end time on line %d: %w", blockStart+timing+1, err)
+		}
+
+		c.Text = strings.Join(block[timing+1:], "\n")
+		cues = append(cues, c)
+	}
+
+	return cues, nil
+}
+
+// isSubtitleMetaBlock returns true if a block starting with the given line is
+// a WebVTT header, comment, style or region block rather than a cue.
+func isSubtitleMetaBlock(firstLine string) bool {
+	for _, keyword := range []string{"WEBVTT", "NOTE", "STYLE", "REGION"} {
+		if firstLine == keyword || strings.HasPrefix(firstLine, keyword+" ") ||
+			strings.HasPrefix(firstLine, keyword+"\t") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseSubtitleTime parses a cue timestamp in the form "[hh:]mm:ss[,.]mmm" into
+// seconds.
+func parseSubtitleTime(s string) (float64, error) {
+	parts := strings.Split(strings.ReplaceAll(s, ",", "."), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("expected timestamp in the form [hh:]mm:ss.mmm, got %q", s)
+	}
+
+	secs := 0.0
+
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, fmt.Errorf("expected timestamp in the form [hh:]mm:ss.mmm, got %q", s)
+		}
+
+		secs = secs*60 + v //nolint: gomnd // seconds in a minute, minutes in an hour.
+	}
+
+	return secs, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadSubtitleFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		input    string
+		want     []Cue
+		wantText []string
+		wantErr  bool
+	}{
+		{
+			name:  "good1_srt",
+			input: "testdata/subtitle/good1.srt",
+			want: []Cue{
+				{Start: 1, End: 3.5, Text: "<i>JOHN: Hello there.</i>\n- How are you?"},
+				{Start: 4, End: 6, Text: "[door creaks] I am fine &amp; you?"},
+			},
+			wantText: []string{"Hello there. How are you?", "I am fine & you?"},
+		},
+		{
+			name:  "good1_vtt",
+			input: "testdata/subtitle/good1.vtt",
+			want: []Cue{
+				{Start: 1, End: 3.5, Text: "<v John>Hello there.</v>\n>> How are you?"},
+				{Start: 3604, End: 3606, Text: `{\an8}♪ (laughs) I am fine &amp; you? ♪`},
+			},
+			wantText: []string{"Hello there. How are you?", "I am fine & you?"},
+		},
+		{
+			// Lines with "-->" in the text of a cue are not read as timing lines.
+			name:  "arrow1_srt",
+			input: "testdata/subtitle/arrow1.srt",
+			want: []Cue{
+				{Start: 1, End: 2, Text: "Take the left path --> not the right one."},
+				{Start: 3, End: 4.5, Text: "She said:\n00:00:05,000 --> 00:00:06,000"},
+			},
+			wantText: []string{
+				"Take the left path --> not the right one.",
+				"She said: 00:00:05,000 --> 00:00:06,000",
+			},
+		},
+		{
+			name:    "bad1_srt",
+			input:   "testdata/subtitle/bad1.srt",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got, err := readSubtitleFile(context.Background(), tc.input)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected cues, (-want, +got):\n%s", diff)
+			}
+
+			gotText := make([]string, 0, len(got))
+			for _, c := range got {
+				gotText = append(gotText, stripSubtitleMarkup(c.Text))
+			}
+
+			if diff := cmp.Diff(tc.wantText, gotText); diff != "" {
+				subT.Errorf("unexpected stripped text, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
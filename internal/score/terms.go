@@ -0,0 +1,469 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"html"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// TermsFile is the name, without extension, of the files in the output
+// directory of a scoring run to which term metrics are written, as terms.json
+// and terms.html.
+const TermsFile = "terms"
+
+// A Term is a keyword or multi-word phrase whose recognition is scored
+// separately, optionally grouped with other terms in a category.
+type Term struct {
+	Text     string `json:"term"`
+	Category string `json:"category,omitempty"`
+
+	// tokens are the words of the normalized term, or its characters ignoring
+	// spaces when evaluating CER, matched against aligned words.
+	tokens []string
+}
+
+// TermCounts are the counts of the occurrences of one or more terms in the
+// reference and a hypothesis. Recall, precision and F1 are percentages.
+type TermCounts struct {
+	// Ref and Hyp are the numbers of occurrences in the reference and the
+	// hypothesis. Hits are the reference occurrences aligned to the same words
+	// in the hypothesis, without insertions between them.
+	Ref  int `json:"ref"`
+	Hyp  int `json:"hyp"`
+	Hits int `json:"hits"`
+
+	// Misses are the reference occurrences that were not hits. A miss is
+	// attributed to a substitution if any of its words was substituted or
+	// words were inserted between them, and to a deletion otherwise.
+	Substitutions int `json:"substitutions"`
+	Deletions     int `json:"deletions"`
+
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+	F1        float64 `json:"f1"`
+}
+
+// TermResult are the counts of a single term.
+type TermResult struct {
+	Term     string `json:"term"`
+	Category string `json:"category,omitempty"`
+	TermCounts
+}
+
+// CategoryResult are the counts of all terms in a category.
+type CategoryResult struct {
+	Category string `json:"category"`
+	TermCounts
+}
+
+// SystemTerms are the term counts of a system, over all terms, per category,
+// and per term in the order of the term list.
+type SystemTerms struct {
+	System     string           `json:"system"`
+	Total      TermCounts       `json:"total"`
+	Categories []CategoryResult `json:"categories,omitempty"`
+	Terms      []TermResult     `json:"terms"`
+}
+
+// TermsSummary are the term counts of all systems scored in a run.
+type TermsSummary struct {
+	TermList string        `json:"term_list"`
+	Systems  []SystemTerms `json:"systems"`
+}
+
+// ReadTerms reads a term list, with one keyword or phrase per line, optionally
+// followed by a tab and the name of its category. Blank lines and lines
+// starting with # are ignored. Terms are normalized like transcripts, and
+// split into characters if cer is true.
+func ReadTerms(filePath string, normCfg NormalizeConfig, cer bool) ([]Term, error) {
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read term list: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+
+	terms := make([]Term, 0)
+	seen := make(map[string]int)
+	ldx := 0
+
+	for scanner.Scan() {
+		ldx++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		t := Term{Text: line}
+		if i := strings.LastIndex(line, "\t"); i >= 0 {
+			t.Text, t.Category = strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:])
+		}
+
+		t.tokens = scoringTokens(normalizeText(t.Text, normCfg), cer)
+		if len(t.tokens) == 0 {
+			return nil, fmt.Errorf("expected a term on line %d of term list", ldx)
+		}
+
+		key := strings.Join(t.tokens, " ")
+		if prev, ok := seen[key]; ok {
+			logrus.WithFields(logrus.Fields{
+				"term":  t.Text,
+				"line":  ldx,
+				"first": prev,
+			}).Warn("skipping duplicate term")
+
+			continue
+		}
+
+		seen[key] = ldx
+		terms = append(terms, t)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read term list: %w", err)
+	}
+
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("no terms found in %q", filePath)
+	}
+
+	return terms, nil
+}
+
+// ScoreTerms counts the occurrences of the terms in the given term list in the
+// reference and hypotheses of a scoring run, from the alignments written to its
+// output directory, and writes the counts to terms.json and terms.html there.
+// The normalization and cer flag must match those of the run.
+func ScoreTerms(termList string, normCfg NormalizeConfig, cer bool, outDir string) (*TermsSummary, error) {
+	terms, err := ReadTerms(termList, normCfg, cer)
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := compare.LoadRun(outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := textutils.SortedKeys(systems)
+
+	summary := &TermsSummary{TermList: termList, Systems: make([]SystemTerms, 0, len(names))}
+
+	for _, name := range names {
+		st := CountTerms(systems[name], terms)
+		st.log()
+
+		summary.Systems = append(summary.Systems, st)
+	}
+
+	outPrefix := path.Join(outDir, TermsFile)
+
+	if err := summary.writeJSON(outPrefix + ".json"); err != nil {
+		return nil, err
+	}
+
+	if err := summary.writeHTML(outPrefix + ".html"); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// linkTermsReport adds a link to the HTML term report below the header of the
+// HTML alignment report of each of the given hypotheses, where written.
+func linkTermsReport(outDir string, hypFiles []sctk.Hypothesis) error {
+	const (
+		headerEnd = "</h2>\n"
+	)
+
+	link := fmt.Sprintf("\n<p>Terms = <a href=\"%s.html\">%s.html</a></p>\n", TermsFile, TermsFile)
+
+	for _, hyp := range hypFiles {
+		filePath := path.Join(outDir, path.Base(hyp.FilePath)+".pra."+sctk.AlignmentFormatHTML)
+
+		data, err := os.ReadFile(filePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read alignment report: %w", err)
+		}
+
+		linked := strings.Replace(string(data), headerEnd, headerEnd+link, 1)
+
+		if err := os.WriteFile(filePath, []byte(linked), 0644); err != nil { //nolint: gomnd // file permissions.
+			return fmt.Errorf("failed to write alignment report: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CountTerms counts the occurrences of the given terms in the reference and
+// hypothesis of the aligned sentences of a system.
+func CountTerms(aligned *sctk.AlignedHypothesis, terms []Term) SystemTerms {
+	st := SystemTerms{System: aligned.SystemName, Terms: make([]TermResult, len(terms))}
+
+	for i, t := range terms {
+		st.Terms[i] = TermResult{Term: t.Text, Category: t.Category}
+	}
+
+	for _, sents := range aligned.Speakers {
+		for _, s := range sents {
+			refIdx, hypIdx := alignedIndexes(s)
+
+			for i, t := range terms {
+				st.Terms[i].count(s, refIdx, hypIdx, t.tokens)
+			}
+		}
+	}
+
+	categories := make(map[string]*TermCounts)
+
+	for i := range st.Terms {
+		tr := &st.Terms[i]
+		tr.setRates()
+		st.Total.add(tr.TermCounts)
+
+		if tr.Category == "" {
+			continue
+		}
+
+		if _, ok := categories[tr.Category]; !ok {
+			categories[tr.Category] = &TermCounts{}
+		}
+
+		categories[tr.Category].add(tr.TermCounts)
+	}
+
+	st.Total.setRates()
+
+	names := textutils.SortedKeys(categories)
+
+	for _, c := range names {
+		categories[c].setRates()
+		st.Categories = append(st.Categories, CategoryResult{Category: c, TermCounts: *categories[c]})
+	}
+
+	return st
+}
+
+// alignedIndexes returns the indexes of the aligned words of the sentence that
+// hold reference words, i.e. all but insertions, and those that hold hypothesis
+// words, i.e. all but deletions.
+func alignedIndexes(s *sctk.AlignedSentence) (refIdx, hypIdx []int) {
+	refIdx = make([]int, 0, len(s.Words))
+	hypIdx = make([]int, 0, len(s.Words))
+
+	for i, w := range s.Words {
+		if w.Label != "I" {
+			refIdx = append(refIdx, i)
+		}
+
+		if w.Label != "D" {
+			hypIdx = append(hypIdx, i)
+		}
+	}
+
+	return refIdx, hypIdx
+}
+
+// count adds the occurrences of the term with the given tokens in the sentence.
+// Occurrences are matched from left to right without overlapping.
+func (c *TermCounts) count(s *sctk.AlignedSentence, refIdx, hypIdx []int, tokens []string) {
+	refWord := func(i int) string { return s.Words[i].Ref }
+	hypWord := func(i int) string { return s.Words[i].Hyp }
+
+	for _, start := range findTokens(refIdx, refWord, tokens) {
+		c.Ref++
+
+		hit, sub := true, false
+
+		for _, w := range s.Words[refIdx[start] : refIdx[start+len(tokens)-1]+1] {
+			switch w.Label {
+			case "C":
+			case "D":
+				hit = false
+			default:
+				hit, sub = false, true
+			}
+		}
+
+		switch {
+		case hit:
+			c.Hits++
+		case sub:
+			c.Substitutions++
+		default:
+			c.Deletions++
+		}
+	}
+
+	c.Hyp += len(findTokens(hypIdx, hypWord, tokens))
+}
+
+// findTokens returns the positions in the given word indexes at which the
+// tokens occur, without overlapping.
+func findTokens(idx []int, word func(int) string, tokens []string) []int {
+	found := make([]int, 0)
+
+	for i := 0; i+len(tokens) <= len(idx); {
+		match := true
+
+		for j, tok := range tokens {
+			if word(idx[i+j]) != tok {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			found = append(found, i)
+			i += len(tokens)
+		} else {
+			i++
+		}
+	}
+
+	return found
+}
+
+// add adds the occurrences counted in o.
+func (c *TermCounts) add(o TermCounts) {
+	c.Ref += o.Ref
+	c.Hyp += o.Hyp
+	c.Hits += o.Hits
+	c.Substitutions += o.Substitutions
+	c.Deletions += o.Deletions
+}
+
+// setRates sets recall, precision and F1 from the counts. Each is 0 if
+// undefined.
+func (c *TermCounts) setRates() {
+	c.Recall = percentage(c.Hits, c.Ref)
+	c.Precision = percentage(c.Hits, c.Hyp)
+	c.F1 = 0
+
+	if c.Recall+c.Precision > 0 {
+		c.F1 = 2 * c.Recall * c.Precision / (c.Recall + c.Precision) //nolint: gomnd // harmonic mean.
+	}
+}
+
+// percentage returns num as a percentage of den, or 0 if den is 0.
+func percentage(num, den int) float64 {
+	if den == 0 {
+		return 0
+	}
+
+	return 100 * float64(num) / float64(den) //nolint: gomnd // percentage.
+}
+
+// log logs the counts of the system over all terms.
+func (st *SystemTerms) log() {
+	logrus.WithFields(logrus.Fields{
+		"system":        st.System,
+		"terms":         st.Total.Ref,
+		"recall":        fmt.Sprintf("%.2f%%", st.Total.Recall),
+		"precision":     fmt.Sprintf("%.2f%%", st.Total.Precision),
+		"f1":            fmt.Sprintf("%.2f%%", st.Total.F1),
+		"substitutions": st.Total.Substitutions,
+		"deletions":     st.Total.Deletions,
+	}).Info("scored terms")
+}
+
+// writeJSON writes the summary to the given path as JSON.
+func (s *TermsSummary) writeJSON(filePath string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode term summary: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write term summary: %w", err)
+	}
+
+	return nil
+}
+
+// writeHTML writes the summary to the given path as an HTML report, with one
+// section per system holding a table of the totals and categories, and a table
+// of the terms.
+func (s *TermsSummary) writeHTML(filePath string) error {
+	w := strings.Builder{}
+
+	header := func(first ...interface{}) table.Row {
+		return append(first, "Ref", "Hyp", "Hits", "Sub", "Del", "Recall", "Precision", "F1")
+	}
+
+	row := func(name string, c TermCounts) table.Row {
+		return table.Row{
+			name, c.Ref, c.Hyp, c.Hits, c.Substitutions, c.Deletions,
+			fmt.Sprintf("%.2f%%", c.Recall), fmt.Sprintf("%.2f%%", c.Precision), fmt.Sprintf("%.2f%%", c.F1),
+		}
+	}
+
+	w.WriteString(fmt.Sprintf("\n<h2>TERMS</h2>\n\n<p>Term List = %s</p>\n", html.EscapeString(s.TermList)))
+
+	for _, st := range s.Systems {
+		w.WriteString(fmt.Sprintf("\n<h3>%s</h3>\n", html.EscapeString(st.System)))
+
+		t := table.NewWriter()
+		t.AppendHeader(header(""))
+		t.AppendRow(row("Total", st.Total))
+
+		for _, c := range st.Categories {
+			t.AppendRow(row(c.Category, c.TermCounts))
+		}
+
+		w.WriteString(t.RenderHTML())
+		w.WriteString("\n<br>\n")
+
+		t = table.NewWriter()
+		t.AppendHeader(header("Category", "Term"))
+
+		for _, tr := range st.Terms {
+			t.AppendRow(append(table.Row{tr.Category}, row(tr.Term, tr.TermCounts)...))
+		}
+
+		w.WriteString(t.RenderHTML())
+		w.WriteString("\n<br>\n")
+	}
+
+	if err := os.WriteFile(filePath, []byte(w.String()), 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write term report: %w", err)
+	}
+
+	return nil
+}
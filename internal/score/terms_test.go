@@ -0,0 +1,156 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestScoreTerms(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	scliteCfg := sctk.ScliteCfg{LineWidth: 120, Encoding: "utf-8"}
+	hypFiles := []sctk.Hypothesis{{SystemName: "hyp1", FilePath: "testdata/terms/hyp.csv"}}
+
+	err := Score(
+		context.Background(), FileFormat{Delimiter: ',', ColTrn: 1}, NormalizeConfig{}, scliteCfg,
+		outDir, "testdata/terms/ref.csv", hypFiles,
+	)
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	// counts returns term counts with recall, precision and F1 given as
+	// fractions.
+	counts := func(ref, hyp, hits, sub, del int, recall, precision float64) TermCounts {
+		c := TermCounts{
+			Ref: ref, Hyp: hyp, Hits: hits, Substitutions: sub, Deletions: del,
+			Recall: 100 * recall, Precision: 100 * precision,
+		}
+
+		if recall+precision > 0 {
+			c.F1 = 100 * 2 * recall * precision / (recall + precision)
+		}
+
+		return c
+	}
+
+	testCases := []struct {
+		name     string
+		termList string
+		want     []SystemTerms
+		wantErr  bool
+	}{
+		{
+			name:     "categories",
+			termList: "testdata/terms/terms.txt",
+			want: []SystemTerms{
+				{
+					System: "hyp1",
+					Total:  counts(7, 4, 3, 3, 1, 3.0/7, 3.0/4),
+					Categories: []CategoryResult{
+						{Category: "amount", TermCounts: counts(1, 0, 0, 1, 0, 0, 0)},
+						{Category: "place", TermCounts: counts(2, 2, 1, 1, 0, 1.0/2, 1.0/2)},
+						{Category: "product", TermCounts: counts(3, 2, 2, 0, 1, 2.0/3, 1)},
+					},
+					Terms: []TermResult{
+						{Term: "iPhone Fifteen", Category: "product", TermCounts: counts(2, 1, 1, 0, 1, 1.0/2, 1)},
+						{Term: "pro max", Category: "product", TermCounts: counts(1, 1, 1, 0, 0, 1, 1)},
+						{Term: "new york", Category: "place", TermCounts: counts(2, 2, 1, 1, 0, 1.0/2, 1.0/2)},
+						{Term: "twenty dollars", Category: "amount", TermCounts: counts(1, 0, 0, 1, 0, 0, 0)},
+						{Term: "two", TermCounts: counts(1, 0, 0, 1, 0, 0, 0)},
+					},
+				},
+			},
+		},
+		{
+			name:     "empty",
+			termList: "testdata/terms/empty.txt",
+			wantErr:  true,
+		},
+		{
+			name:     "missing",
+			termList: "testdata/terms/missing.txt",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			// Each test case writes its summary next to a copy of the alignments.
+			runDir := subT.TempDir()
+			alignments := "hyp1.trn.pra.json"
+
+			data, err := os.ReadFile(path.Join(outDir, alignments))
+			if err != nil {
+				subT.Fatalf("failed to read alignments: %v", err)
+			}
+
+			if err := os.WriteFile(path.Join(runDir, alignments), data, 0644); err != nil { //nolint: gomnd // file permissions.
+				subT.Fatalf("failed to copy alignments: %v", err)
+			}
+
+			got, err := ScoreTerms(tc.termList, NormalizeConfig{}, false, runDir)
+			if tc.wantErr {
+				if err == nil {
+					subT.Errorf("did not get expected error, want=non-nil, got=nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, got.Systems, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				subT.Errorf("unexpected term counts, (-want, +got):\n%s", diff)
+			}
+
+			data, err = os.ReadFile(path.Join(runDir, TermsFile+".json"))
+			if err != nil {
+				subT.Fatalf("failed to read term summary: %v", err)
+			}
+
+			var written TermsSummary
+			if err := json.Unmarshal(data, &written); err != nil {
+				subT.Fatalf("failed to decode term summary: %v", err)
+			}
+
+			if diff := cmp.Diff(got, &written); diff != "" {
+				subT.Errorf("unexpected term summary written, (-want, +got):\n%s", diff)
+			}
+
+			if _, err := os.Stat(path.Join(runDir, TermsFile+".html")); err != nil {
+				subT.Errorf("expected term report to be written: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,539 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// A Segment is a segment of a reference transcript with speaker, channel and
+// time boundaries, as found in stm files.
+type Segment struct {
+	File       string
+	Channel    string
+	Speaker    string
+	Start      float64
+	End        float64
+	Label      string
+	Transcript string
+
+	// line is the line number of the segment in the file it was read from.
+	line int
+}
+
+// A TimedWord is a single word of a hypothesis with its start time, duration
+// and optionally confidence score, as found in ctm files.
+type TimedWord struct {
+	File     string
+	Channel  string
+	Start    float64
+	Duration float64
+	Word     string
+
+	// Conf is the confidence score as written in the ctm file; empty if the
+	// ctm file does not contain confidence scores.
+	Conf string
+
+	// line is the line number of the word in the file it was read from; 0 if
+	// it was not read from a ctm file.
+	line int
+}
+
+// normalizeTimedFiles parses the segment time marked (stm) reference file and
+// the time marked (ctm) hypotheses files, and normalizes them based on the
+// provided configs. The normalized files are written to the provided output
+// directory; the normalized reference file is named ref.stm, while hypotheses
+// files are named based on their system name. The files are validated before
+// normalization, like utterance based inputs; the returned report counts
+// segments and words instead of utterances, and the coverage of reference
+// files and channels instead of utterances.
+func normalizeTimedFiles(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig,
+	outDir, refFile string, hypFiles []sctk.Hypothesis,
+) (string, []sctk.Hypothesis, ValidationReport, error) {
+	var report ValidationReport
+
+	refSegs, err := readStmFile(ctx, refFile)
+	if err != nil {
+		return "", nil, report, fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	// Checking inputs for problems before they are normalized.
+	refIDs := report.addTimedRef(refFile, refSegs, cfg)
+
+	for i := range refSegs {
+		refSegs[i].Transcript = normalizeText(refSegs[i].Transcript, cfg)
+	}
+
+	refNorm := path.Join(outDir, "ref.stm")
+	if err := writeStmFile(ctx, refSegs, refNorm); err != nil {
+		return "", nil, report, fmt.Errorf("failed to write normalized reference file: %w", err)
+	}
+
+	// Will filter words from hypotheses that do not have a reference file and
+	// channel.
+	refChannels := firstChannels(refSegs)
+
+	if len(refIDs) == 0 {
+		return "", nil, report, fmt.Errorf("reference file does not contain any segments")
+	}
+
+	normHypFiles := make([]sctk.Hypothesis, 0, len(hypFiles))
+
+	for _, hyp := range hypFiles {
+		words, err := readTimedHyp(ctx, fileFormat, cfg, hyp.FilePath, refChannels)
+		if err != nil {
+			return "", nil, report, err
+		}
+
+		report.addTimedHyp(hyp.FilePath, words, refIDs, cfg)
+		words = normalizeTimedWords(words, cfg)
+
+		n := 0
+		for _, w := range words {
+			if _, ok := refIDs[w.File+" "+w.Channel]; ok {
+				words[n] = w
+				n++
+			}
+		}
+
+		words = words[:n]
+		if len(words) == 0 {
+			return "", nil, report, fmt.Errorf(
+				"no files and channels in common between reference file and %q", hyp.FilePath,
+			)
+		}
+
+		sanitizedName := SanitizeSystemName(hyp.SystemName)
+		hypNorm := path.Join(outDir, sanitizedName+".ctm")
+
+		if err := writeCtmFile(ctx, words, hypNorm); err != nil {
+			return "", nil, report, fmt.Errorf("failed to write normalized hypothesis file: %w", err)
+		}
+
+		normHypFiles = append(normHypFiles, sctk.Hypothesis{
+			SystemName: sanitizedName,
+			FilePath:   hypNorm,
+			Format:     sctk.FormatCtm,
+		})
+	}
+
+	report.log()
+
+	if err := report.write(path.Join(outDir, "validation.json")); err != nil {
+		return "", nil, report, err
+	}
+
+	if fileFormat.Strict {
+		if err := report.Err(); err != nil {
+			return "", nil, report, err
+		}
+	}
+
+	return refNorm, normHypFiles, report, nil
+}
+
+// firstChannels returns the first channel of each file in the given segments.
+func firstChannels(segs []Segment) map[string]string {
+	channels := make(map[string]string)
+
+	for _, seg := range segs {
+		if _, ok := channels[seg.File]; !ok {
+			channels[seg.File] = seg.Channel
+		}
+	}
+
+	return channels
+}
+
+// readTimedHyp reads the words of a hypothesis file scored against a stm
+// reference, either a ctm or subtitle file. Words of subtitle hypotheses are
+// placed on the first channel of the reference file with the same name, given
+// by refChannels.
+func readTimedHyp(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig,
+	filePath string, refChannels map[string]string,
+) ([]TimedWord, error) {
+	var (
+		words []TimedWord
+		err   error
+	)
+
+	switch format := fileFormat.hypFormat(filePath); {
+	case format == FormatCtm:
+		words, err = readCtmFile(ctx, filePath)
+	case isSubtitleFormat(format):
+		words, err = readSubtitleWords(ctx, filePath, refChannels[subtitleFileID(filePath)], cfg)
+	default:
+		return nil, fmt.Errorf(
+			"stm references can only be scored against ctm or subtitle hypotheses, got %s for %q",
+			format, filePath,
+		)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hypothesis file: %w", err)
+	}
+
+	return words, nil
+}
+
+// normalizeTimedWords normalizes each of the given words, dropping words that
+// are empty after normalization. The normalized list of words is returned.
+func normalizeTimedWords(words []TimedWord, cfg NormalizeConfig) []TimedWord {
+	n := 0
+	for _, w := range words {
+		w.Word = strings.Join(strings.Fields(normalizeText(w.Word, cfg)), "_")
+		if w.Word != "" {
+			words[n] = w
+			n++
+		}
+	}
+
+	return words[:n]
+}
+
+// wordsToUtts joins the words of each file in the given list into the
+// transcript of one utterance, with the file name as the utterance ID. The
+// utterances are returned in the order in which the files first appear.
+func wordsToUtts(words []TimedWord) []Utt {
+	sorted := make([]TimedWord, len(words))
+	copy(sorted, words)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	order := make([]string, 0)
+	transcripts := make(map[string][]string)
+
+	for _, w := range words {
+		if _, ok := transcripts[w.File]; !ok {
+			order = append(order, w.File)
+			transcripts[w.File] = nil
+		}
+	}
+
+	for _, w := range sorted {
+		transcripts[w.File] = append(transcripts[w.File], w.Word)
+	}
+
+	utts := make([]Utt, 0, len(order))
+	for _, id := range order {
+		utts = append(utts, Utt{
+			ID:         sanitizeUttID(id),
+			Transcript: strings.Join(transcripts[id], " "),
+		})
+	}
+
+	return utts
+}
+
+// uttsToWords converts the given utterances into timed words, with the
+// utterance ID as the file name. Since transcripts carry no timing information,
+// each word is given a fixed duration, one after the other, and the same
+// confidence score.
+func uttsToWords(utts []Utt) []TimedWord {
+	const (
+		wordDur     = 0.1
+		defaultConf = "1.00"
+	)
+
+	words := make([]TimedWord, 0, len(utts))
+
+	for _, utt := range utts {
+		for i, word := range strings.Fields(utt.Transcript) {
+			words = append(words, TimedWord{
+				File:     utt.ID,
+				Channel:  "1",
+				Start:    float64(i) * wordDur,
+				Duration: wordDur,
+				Word:     word,
+				Conf:     defaultConf,
+			})
+		}
+	}
+
+	return words
+}
+
+// readStmFile reads the segments in the given stm file. Lines starting with ";;"
+// are treated as comments and skipped.
+func readStmFile(ctx context.Context, filePath string) ([]Segment, error) {
+	const (
+		minCols = 5
+	)
+
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stm file: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	segs := make([]Segment, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+	ldx := 0
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		ldx++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";;") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < minCols {
+			return nil, fmt.Errorf(
+				"expected stm file to contain at least %d columns, got %d on line %d",
+				minCols, len(parts), ldx,
+			)
+		}
+
+		seg := Segment{File: parts[0], Channel: parts[1], Speaker: parts[2], line: ldx}
+
+		if seg.Start, err = strconv.ParseFloat(parts[3], 64); err != nil {
+			return nil, fmt.Errorf("invalid start time on line %d: %w", ldx, err)
+		}
+
+		if seg.End, err = strconv.ParseFloat(parts[4], 64); err != nil {
+			return nil, fmt.Errorf("invalid end time on line %d: %w", ldx, err)
+		}
+
+		rest := parts[minCols:]
+		if len(rest) > 0 && strings.HasPrefix(rest[0], "<") && strings.HasSuffix(rest[0], ">") {
+			seg.Label, rest = rest[0], rest[1:]
+		}
+
+		seg.Transcript = strings.Join(rest, " ")
+		segs = append(segs, seg)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d is longer than %d bytes", ldx+1, maxScanLineLen)
+		}
+
+		return nil, fmt.Errorf("failed to read stm file: %w", err)
+	}
+
+	return segs, nil
+}
+
+// writeStmFile writes the given segments to the provided path in the stm format
+// - "<file> <channel> <speaker> <start> <end> [<label>] <transcript>". Segments
+// are sorted by file, channel and start time, as expected by SCTK tools.
+func writeStmFile(ctx context.Context, segs []Segment, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output stm file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	sorted := make([]Segment, len(segs))
+	copy(sorted, segs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+
+		return a.Start < b.Start
+	})
+
+	w := bufio.NewWriter(f)
+
+	for _, seg := range sorted {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		fields := []string{
+			seg.File, seg.Channel, seg.Speaker, formatTime(seg.Start), formatTime(seg.End),
+		}
+
+		if seg.Label != "" {
+			fields = append(fields, seg.Label)
+		}
+
+		if seg.Transcript != "" {
+			fields = append(fields, seg.Transcript)
+		}
+
+		if _, err := w.WriteString(strings.Join(fields, " ") + "\n"); err != nil {
+			return fmt.Errorf("failed to write line to stm file: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush write buffer to stm file: %w", err)
+	}
+
+	return nil
+}
+
+// readCtmFile reads the words in the given ctm file. Lines starting with ";;"
+// are treated as comments and skipped.
+func readCtmFile(ctx context.Context, filePath string) ([]TimedWord, error) {
+	const (
+		minCols = 5
+	)
+
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ctm file: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	words := make([]TimedWord, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+	ldx := 0
+
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		ldx++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ";;") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < minCols {
+			return nil, fmt.Errorf(
+				"expected ctm file to contain at least %d columns, got %d on line %d",
+				minCols, len(parts), ldx,
+			)
+		}
+
+		w := TimedWord{File: parts[0], Channel: parts[1], Word: parts[4], line: ldx}
+
+		if w.Start, err = strconv.ParseFloat(parts[2], 64); err != nil {
+			return nil, fmt.Errorf("invalid start time on line %d: %w", ldx, err)
+		}
+
+		if w.Duration, err = strconv.ParseFloat(parts[3], 64); err != nil {
+			return nil, fmt.Errorf("invalid duration on line %d: %w", ldx, err)
+		}
+
+		if len(parts) > minCols {
+			w.Conf = parts[minCols]
+		}
+
+		words = append(words, w)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("line %d is longer than %d bytes", ldx+1, maxScanLineLen)
+		}
+
+		return nil, fmt.Errorf("failed to read ctm file: %w", err)
+	}
+
+	return words, nil
+}
+
+// writeCtmFile writes the given words to the provided path in the ctm format -
+// "<file> <channel> <start> <duration> <word> [<confidence>]". Words are sorted
+// by file, channel and start time, as expected by SCTK tools.
+func writeCtmFile(ctx context.Context, words []TimedWord, filePath string) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create output ctm file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	sorted := make([]TimedWord, len(words))
+	copy(sorted, words)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+
+		return a.Start < b.Start
+	})
+
+	w := bufio.NewWriter(f)
+
+	for _, word := range sorted {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		fields := []string{
+			word.File, word.Channel, formatTime(word.Start), formatTime(word.Duration), word.Word,
+		}
+
+		if word.Conf != "" {
+			fields = append(fields, word.Conf)
+		}
+
+		if _, err := w.WriteString(strings.Join(fields, " ") + "\n"); err != nil {
+			return fmt.Errorf("failed to write line to ctm file: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to flush write buffer to ctm file: %w", err)
+	}
+
+	return nil
+}
+
+// formatTime formats the given time in seconds with as many decimals as
+// needed.
+func formatTime(t float64) string {
+	return strconv.FormatFloat(t, 'f', -1, 64)
+}
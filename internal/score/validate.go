@@ -0,0 +1,476 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Kinds of problems found when validating input files.
+const (
+	// IssueDuplicateID is reported for utterances whose ID appears more than
+	// once in the same file.
+	IssueDuplicateID = "duplicate_id"
+	// IssueIDCollision is reported for utterances whose ID is different from
+	// that of an earlier utterance, but becomes the same after spaces are
+	// replaced with underscores.
+	IssueIDCollision = "id_collision"
+	// IssueEmptyTranscript is reported for utterances with an empty transcript.
+	IssueEmptyTranscript = "empty_transcript"
+	// IssueInvalidUTF8 is reported for utterances whose ID or transcript is not
+	// valid UTF-8.
+	IssueInvalidUTF8 = "invalid_utf8"
+	// IssueBOM is reported for utterances whose ID or transcript contains a
+	// byte order mark, usually left at the start of files by some editors.
+	IssueBOM = "bom"
+	// IssueNotNFC is reported for transcripts that are not in Unicode
+	// normalization form C, when unicode normalization is disabled. The same
+	// text in different normalization forms is counted as an error.
+	IssueNotNFC = "not_nfc"
+	// IssueLongLine is reported for transcripts longer than maxTranscriptLen.
+	IssueLongLine = "long_line"
+	// IssueMissingHyp is reported for hypothesis files that do not contain all
+	// reference utterances.
+	IssueMissingHyp = "missing_hyp"
+	// IssueExtraHyp is reported for hypothesis files that contain utterances
+	// without a reference; these are ignored when scoring.
+	IssueExtraHyp = "extra_hyp"
+	// IssueInvalidTime is reported for stm segments that end before they start,
+	// and ctm words with a negative start time or duration.
+	IssueInvalidTime = "invalid_time"
+)
+
+const (
+	// maxTranscriptLen is the length in bytes beyond which transcripts are
+	// reported as too long. The time taken to align a transcript grows with the
+	// square of its length, so very long transcripts slow down scoring
+	// considerably.
+	maxTranscriptLen = 64 * 1024
+	// maxScanLineLen is the maximum length in bytes of a line that can be read
+	// from input files.
+	maxScanLineLen = 64 * 1024 * 1024
+	// maxLoggedIssues is the maximum number of issues logged per file.
+	maxLoggedIssues = 10
+)
+
+// An Issue is a problem found in an input file.
+type Issue struct {
+	Kind    string `json:"kind"`
+	Line    int    `json:"line,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Message string `json:"message"`
+}
+
+// Coverage describes how many reference utterances are present in a
+// hypothesis file.
+type Coverage struct {
+	NumRef     int     `json:"num_ref"`
+	NumMatched int     `json:"num_matched"`
+	NumExtra   int     `json:"num_extra"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// A FileReport contains the problems found in one input file.
+type FileReport struct {
+	Path    string `json:"path"`
+	NumUtts int    `json:"num_utts"`
+
+	// Coverage of the reference utterances; only set for hypothesis files.
+	Coverage *Coverage `json:"coverage,omitempty"`
+
+	Issues []Issue `json:"issues"`
+}
+
+// A ValidationReport contains the problems found in the reference and
+// hypothesis files. The reference file is always first.
+type ValidationReport struct {
+	Files []FileReport `json:"files"`
+}
+
+// NumIssues returns the total number of issues found in all files.
+func (r *ValidationReport) NumIssues() int {
+	n := 0
+	for _, f := range r.Files {
+		n += len(f.Issues)
+	}
+
+	return n
+}
+
+// Err returns an error if any issues were found, and nil otherwise.
+func (r *ValidationReport) Err() error {
+	if n := r.NumIssues(); n > 0 {
+		return fmt.Errorf("found %d problems in input files", n)
+	}
+
+	return nil
+}
+
+// log logs the issues found in each file as warnings.
+func (r *ValidationReport) log() {
+	for _, f := range r.Files {
+		for i, issue := range f.Issues {
+			if i == maxLoggedIssues {
+				logrus.WithFields(logrus.Fields{
+					"path":  f.Path,
+					"count": len(f.Issues) - maxLoggedIssues,
+				}).Warn("more problems found in input file, see validation report")
+
+				break
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"path": f.Path,
+				"line": issue.Line,
+				"id":   issue.ID,
+				"kind": issue.Kind,
+			}).Warn(issue.Message)
+		}
+	}
+}
+
+// write writes the report to the given path in the JSON format.
+func (r *ValidationReport) write(filePath string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode validation report: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write validation report: %w", err)
+	}
+
+	return nil
+}
+
+// Validate reads the reference and hypothesis files and reports problems found
+// in them, such as duplicate utterance IDs, empty transcripts, encoding problems
+// and reference utterances missing from hypotheses. The same checks are run on
+// the inputs before scoring. The transcripts are checked as read, before any
+// normalization; the normalization config only decides whether transcripts
+// that are not in Unicode normalization form C are reported. stm references
+// and their ctm or subtitle hypotheses are checked as segments and words; see
+// normalizeTimedFiles.
+func Validate(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig,
+	refFile string, hypFiles []sctk.Hypothesis,
+) (ValidationReport, error) {
+	var report ValidationReport
+
+	if fileFormat.refFormat(refFile) == FormatStm {
+		return validateTimed(ctx, fileFormat, cfg, refFile, hypFiles)
+	}
+
+	refUtts, err := readTranscriptFile(ctx, refFile, fileFormat)
+	if err != nil {
+		return report, fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	refIDs := report.addRef(refFile, refUtts, cfg)
+
+	for _, hyp := range hypFiles {
+		hypUtts, err := readHypUtts(ctx, fileFormat, hyp.FilePath)
+		if err != nil {
+			return report, fmt.Errorf("failed to read hypothesis file: %w", err)
+		}
+
+		report.addHyp(hyp.FilePath, hypUtts, refIDs, cfg)
+	}
+
+	return report, nil
+}
+
+// validateTimed reads the stm reference file and the ctm or subtitle
+// hypotheses and reports problems found in them.
+func validateTimed(
+	ctx context.Context, fileFormat FileFormat, cfg NormalizeConfig,
+	refFile string, hypFiles []sctk.Hypothesis,
+) (ValidationReport, error) {
+	var report ValidationReport
+
+	refSegs, err := readStmFile(ctx, refFile)
+	if err != nil {
+		return report, fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	refIDs := report.addTimedRef(refFile, refSegs, cfg)
+	refChannels := firstChannels(refSegs)
+
+	for _, hyp := range hypFiles {
+		words, err := readTimedHyp(ctx, fileFormat, cfg, hyp.FilePath, refChannels)
+		if err != nil {
+			return report, err
+		}
+
+		report.addTimedHyp(hyp.FilePath, words, refIDs, cfg)
+	}
+
+	return report, nil
+}
+
+// addRef adds the report for the given reference utterances, and returns the
+// set of reference utterance IDs.
+func (r *ValidationReport) addRef(
+	filePath string, utts []Utt, cfg NormalizeConfig,
+) map[string]struct{} {
+	r.Files = append(r.Files, FileReport{
+		Path:    filePath,
+		NumUtts: len(utts),
+		Issues:  checkUtts(utts, cfg),
+	})
+
+	refIDs := make(map[string]struct{})
+	for _, utt := range utts {
+		refIDs[utt.ID] = struct{}{}
+	}
+
+	return refIDs
+}
+
+// addHyp adds the report for the given hypothesis utterances, including their
+// coverage of the given set of reference utterance IDs.
+func (r *ValidationReport) addHyp(
+	filePath string, utts []Utt, refIDs map[string]struct{}, cfg NormalizeConfig,
+) {
+	f := FileReport{
+		Path:     filePath,
+		NumUtts:  len(utts),
+		Coverage: &Coverage{NumRef: len(refIDs)},
+		Issues:   checkUtts(utts, cfg),
+	}
+
+	matched := make(map[string]struct{})
+
+	for _, utt := range utts {
+		if _, ok := refIDs[utt.ID]; ok {
+			matched[utt.ID] = struct{}{}
+		} else {
+			f.Coverage.NumExtra++
+		}
+	}
+
+	f.Coverage.NumMatched = len(matched)
+	if f.Coverage.NumRef > 0 {
+		f.Coverage.Ratio = float64(f.Coverage.NumMatched) / float64(f.Coverage.NumRef)
+	}
+
+	if missing := f.Coverage.NumRef - f.Coverage.NumMatched; missing > 0 {
+		f.Issues = append(f.Issues, Issue{
+			Kind: IssueMissingHyp,
+			Message: fmt.Sprintf(
+				"%d of %d reference utterances are missing from hypothesis", missing, f.Coverage.NumRef,
+			),
+		})
+	}
+
+	if f.Coverage.NumExtra > 0 {
+		f.Issues = append(f.Issues, Issue{
+			Kind: IssueExtraHyp,
+			Message: fmt.Sprintf(
+				"%d hypothesis utterances have no reference and will be ignored", f.Coverage.NumExtra,
+			),
+		})
+	}
+
+	r.Files = append(r.Files, f)
+}
+
+// checkUtts checks the given utterances, read from one file, for problems.
+func checkUtts(utts []Utt, cfg NormalizeConfig) []Issue {
+	issues := make([]Issue, 0)
+	seen := make(map[string]Utt)
+
+	add := func(utt Utt, kind, format string, args ...interface{}) {
+		issues = append(issues, Issue{
+			Kind:    kind,
+			Line:    utt.line,
+			ID:      utt.ID,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for _, utt := range utts {
+		rawID := utt.rawID
+		if rawID == "" {
+			rawID = utt.ID
+		}
+
+		if prev, ok := seen[utt.ID]; ok {
+			prevRawID := prev.rawID
+			if prevRawID == "" {
+				prevRawID = prev.ID
+			}
+
+			if prevRawID == rawID {
+				add(utt, IssueDuplicateID, "utterance ID also used on line %d", prev.line)
+			} else {
+				add(utt, IssueIDCollision,
+					"utterance ID %q becomes the same as %q on line %d after replacing spaces",
+					rawID, prevRawID, prev.line)
+			}
+		} else {
+			seen[utt.ID] = utt
+		}
+
+		if strings.TrimSpace(utt.Transcript) == "" {
+			add(utt, IssueEmptyTranscript, "transcript is empty")
+		}
+
+		issues = append(issues, checkText(utt.line, utt.ID, rawID, utt.Transcript, cfg)...)
+	}
+
+	return issues
+}
+
+// checkText checks the raw ID and transcript of one utterance, segment or word
+// for encoding problems and excessive length.
+func checkText(line int, id, rawID, transcript string, cfg NormalizeConfig) []Issue {
+	issues := make([]Issue, 0)
+
+	add := func(kind, format string, args ...interface{}) {
+		issues = append(issues, Issue{
+			Kind:    kind,
+			Line:    line,
+			ID:      id,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if !utf8.ValidString(rawID) || !utf8.ValidString(transcript) {
+		add(IssueInvalidUTF8, "utterance ID or transcript is not valid UTF-8")
+	}
+
+	if strings.ContainsRune(rawID, '\uFEFF') || strings.ContainsRune(transcript, '\uFEFF') {
+		add(IssueBOM, "utterance ID or transcript contains a byte order mark (U+FEFF)")
+	}
+
+	if !cfg.NormalizeUnicode && !norm.NFC.IsNormalString(transcript) {
+		add(IssueNotNFC,
+			"transcript is not in Unicode normalization form C; enable unicode normalization "+
+				"to avoid counting differences in normalization forms as errors")
+	}
+
+	if len(transcript) > maxTranscriptLen {
+		add(IssueLongLine,
+			"transcript is %d bytes long, longer than %d bytes; aligning it may be very slow",
+			len(transcript), maxTranscriptLen)
+	}
+
+	return issues
+}
+
+// addTimedRef adds the report for the given reference segments, and returns
+// the set of reference files and channels, joined by a space. Empty segments
+// are not reported, since stm files use them to mark regions without speech.
+func (r *ValidationReport) addTimedRef(
+	filePath string, segs []Segment, cfg NormalizeConfig,
+) map[string]struct{} {
+	f := FileReport{Path: filePath, NumUtts: len(segs), Issues: make([]Issue, 0)}
+	refIDs := make(map[string]struct{})
+
+	for _, seg := range segs {
+		id := seg.File + " " + seg.Channel
+		refIDs[id] = struct{}{}
+
+		if seg.End < seg.Start {
+			f.Issues = append(f.Issues, Issue{
+				Kind:    IssueInvalidTime,
+				Line:    seg.line,
+				ID:      id,
+				Message: fmt.Sprintf("segment ends at %v before it starts at %v", seg.End, seg.Start),
+			})
+		}
+
+		f.Issues = append(f.Issues, checkText(seg.line, id, seg.File, seg.Transcript, cfg)...)
+	}
+
+	r.Files = append(r.Files, f)
+
+	return refIDs
+}
+
+// addTimedHyp adds the report for the given hypothesis words, including their
+// coverage of the given set of reference files and channels.
+func (r *ValidationReport) addTimedHyp(
+	filePath string, words []TimedWord, refIDs map[string]struct{}, cfg NormalizeConfig,
+) {
+	f := FileReport{
+		Path:     filePath,
+		NumUtts:  len(words),
+		Coverage: &Coverage{NumRef: len(refIDs)},
+		Issues:   make([]Issue, 0),
+	}
+
+	matched := make(map[string]struct{})
+	extra := make(map[string]struct{})
+
+	for _, w := range words {
+		id := w.File + " " + w.Channel
+		if _, ok := refIDs[id]; ok {
+			matched[id] = struct{}{}
+		} else {
+			extra[id] = struct{}{}
+		}
+
+		if w.Start < 0 || w.Duration < 0 {
+			f.Issues = append(f.Issues, Issue{
+				Kind:    IssueInvalidTime,
+				Line:    w.line,
+				ID:      id,
+				Message: fmt.Sprintf("word has negative start time %v or duration %v", w.Start, w.Duration),
+			})
+		}
+
+		f.Issues = append(f.Issues, checkText(w.line, id, w.File, w.Word, cfg)...)
+	}
+
+	f.Coverage.NumMatched = len(matched)
+	f.Coverage.NumExtra = len(extra)
+
+	if f.Coverage.NumRef > 0 {
+		f.Coverage.Ratio = float64(f.Coverage.NumMatched) / float64(f.Coverage.NumRef)
+	}
+
+	if missing := f.Coverage.NumRef - f.Coverage.NumMatched; missing > 0 {
+		f.Issues = append(f.Issues, Issue{
+			Kind: IssueMissingHyp,
+			Message: fmt.Sprintf(
+				"%d of %d reference files and channels are missing from hypothesis", missing, f.Coverage.NumRef,
+			),
+		})
+	}
+
+	if f.Coverage.NumExtra > 0 {
+		f.Issues = append(f.Issues, Issue{
+			Kind: IssueExtraHyp,
+			Message: fmt.Sprintf(
+				"%d hypothesis files and channels have no reference and will be ignored", f.Coverage.NumExtra,
+			),
+		})
+	}
+
+	r.Files = append(r.Files, f)
+}
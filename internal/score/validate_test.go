@@ -0,0 +1,203 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name  string
+		ref   string
+		hyp   string
+		cfg   NormalizeConfig
+		want  [][]string
+		cover Coverage
+	}{
+		{
+			name:  "good1",
+			ref:   "testdata/validate/good1_ref.csv",
+			hyp:   "testdata/validate/good1_hyp.csv",
+			want:  [][]string{{}, {}},
+			cover: Coverage{NumRef: 2, NumMatched: 2, Ratio: 1},
+		},
+		{
+			name: "bad1",
+			ref:  "testdata/validate/bad1_ref.csv",
+			hyp:  "testdata/validate/bad1_hyp.csv",
+			want: [][]string{
+				{IssueBOM, IssueEmptyTranscript, IssueIDCollision, IssueDuplicateID, IssueNotNFC},
+				{IssueMissingHyp, IssueExtraHyp},
+			},
+			cover: Coverage{NumRef: 4, NumMatched: 0, NumExtra: 2, Ratio: 0},
+		},
+		{
+			name: "bad1_normalize_unicode",
+			ref:  "testdata/validate/bad1_ref.csv",
+			hyp:  "testdata/validate/bad1_hyp.csv",
+			cfg:  NormalizeConfig{NormalizeUnicode: true},
+			want: [][]string{
+				{IssueBOM, IssueEmptyTranscript, IssueIDCollision, IssueDuplicateID},
+				{IssueMissingHyp, IssueExtraHyp},
+			},
+			cover: Coverage{NumRef: 4, NumMatched: 0, NumExtra: 2, Ratio: 0},
+		},
+		{
+			name:  "good1_stm",
+			ref:   "testdata/validate/good1_ref.stm",
+			hyp:   "testdata/validate/good1_hyp.ctm",
+			want:  [][]string{{}, {}},
+			cover: Coverage{NumRef: 2, NumMatched: 2, Ratio: 1},
+		},
+		{
+			name: "bad1_stm",
+			ref:  "testdata/validate/bad1_ref.stm",
+			hyp:  "testdata/validate/bad1_hyp.ctm",
+			want: [][]string{
+				{IssueInvalidTime, IssueBOM},
+				{IssueInvalidTime, IssueMissingHyp, IssueExtraHyp},
+			},
+			cover: Coverage{NumRef: 2, NumMatched: 1, NumExtra: 1, Ratio: 0.5},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			report, err := Validate(
+				context.Background(), FileFormat{Delimiter: ',', ColTrn: 1}, tc.cfg,
+				tc.ref, []sctk.Hypothesis{{SystemName: "hyp1", FilePath: tc.hyp}},
+			)
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got := make([][]string, 0, len(report.Files))
+			for _, f := range report.Files {
+				kinds := make([]string, 0, len(f.Issues))
+				for _, issue := range f.Issues {
+					kinds = append(kinds, issue.Kind)
+				}
+
+				got = append(got, kinds)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected issues, (-want, +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.cover, *report.Files[1].Coverage); diff != "" {
+				subT.Errorf("unexpected coverage, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateTimed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		ref     string
+		hyp     string
+		strict  bool
+		want    [][]string
+		cover   Coverage
+		wantErr bool
+	}{
+		{
+			name:  "good1",
+			ref:   "testdata/validate/good1_ref.stm",
+			hyp:   "testdata/validate/good1_hyp.ctm",
+			want:  [][]string{{}, {}},
+			cover: Coverage{NumRef: 2, NumMatched: 2, Ratio: 1},
+		},
+		{
+			name: "bad1",
+			ref:  "testdata/validate/bad1_ref.stm",
+			hyp:  "testdata/validate/bad1_hyp.ctm",
+			want: [][]string{
+				{IssueInvalidTime, IssueBOM},
+				{IssueInvalidTime, IssueMissingHyp, IssueExtraHyp},
+			},
+			cover: Coverage{NumRef: 2, NumMatched: 1, NumExtra: 1, Ratio: 0.5},
+		},
+		{
+			name:   "bad1_strict",
+			ref:    "testdata/validate/bad1_ref.stm",
+			hyp:    "testdata/validate/bad1_hyp.ctm",
+			strict: true,
+			want: [][]string{
+				{IssueInvalidTime, IssueBOM},
+				{IssueInvalidTime, IssueMissingHyp, IssueExtraHyp},
+			},
+			cover:   Coverage{NumRef: 2, NumMatched: 1, NumExtra: 1, Ratio: 0.5},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			_, _, report, err := normalizeTimedFiles(
+				context.Background(), FileFormat{Strict: tc.strict}, NormalizeConfig{}, outDir,
+				tc.ref, []sctk.Hypothesis{{SystemName: "hyp1", FilePath: tc.hyp}},
+			)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				subT.Fatalf("unexpected error, want error=%v, got=%v", tc.wantErr, err)
+			}
+
+			if _, err := os.Stat(path.Join(outDir, "validation.json")); err != nil {
+				subT.Errorf("expected validation report to be written: %v", err)
+			}
+
+			got := make([][]string, 0, len(report.Files))
+			for _, f := range report.Files {
+				kinds := make([]string, 0, len(f.Issues))
+				for _, issue := range f.Issues {
+					kinds = append(kinds, issue.Kind)
+				}
+
+				got = append(got, kinds)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected issues, (-want, +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.cover, *report.Files[1].Coverage); diff != "" {
+				subT.Errorf("unexpected coverage, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
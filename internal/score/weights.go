@@ -0,0 +1,271 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// WeightedFile is the name of the file in the output directory of a scoring
+// run to which weighted error rates are written.
+const WeightedFile = "weighted.json"
+
+// WordCost is the cost of substituting, deleting and inserting a word.
+type WordCost struct {
+	Sub float64 `json:"sub"`
+	Del float64 `json:"del"`
+	Ins float64 `json:"ins"`
+}
+
+// unitCost is the cost of words not listed in a weight table, with which the
+// weighted error rate equals the error rate.
+var unitCost = WordCost{Sub: 1, Del: 1, Ins: 1}
+
+// A WeightTable assigns costs to words, either listed exactly or matched by
+// regular expressions.
+type WeightTable struct {
+	words    map[string]WordCost
+	patterns []weightPattern
+}
+
+// A weightPattern assigns a cost to the words matching a regular expression.
+type weightPattern struct {
+	re   *regexp.Regexp
+	cost WordCost
+}
+
+// ReadWeightTable reads a weight table, with one word or regular expression per
+// line followed by its costs, separated by whitespace. Regular expressions are
+// enclosed in slashes, such as /^[0-9]+$/. A single cost applies to
+// substitutions, deletions and insertions alike; otherwise three costs are
+// given in that order. Blank lines and lines starting with # are ignored.
+// Listed words are normalized like transcripts.
+func ReadWeightTable(filePath string, normCfg NormalizeConfig) (*WeightTable, error) {
+	f, err := fileutils.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read weight table: %w", err)
+	}
+
+	defer fileutils.CloseOrLog(f, filePath)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxScanLineLen)
+
+	t := &WeightTable{words: make(map[string]WordCost)}
+	ldx := 0
+
+	for scanner.Scan() {
+		ldx++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		cost, err := parseWordCost(fields[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid costs on line %d of weight table: %w", ldx, err)
+		}
+
+		key := fields[0]
+
+		if len(key) > 2 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") {
+			re, err := regexp.Compile(key[1 : len(key)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern on line %d of weight table: %w", ldx, err)
+			}
+
+			t.patterns = append(t.patterns, weightPattern{re: re, cost: cost})
+
+			continue
+		}
+
+		word := normalizeText(key, normCfg)
+		if _, ok := t.words[word]; ok {
+			return nil, fmt.Errorf("duplicate word %q on line %d of weight table", key, ldx)
+		}
+
+		t.words[word] = cost
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read weight table: %w", err)
+	}
+
+	return t, nil
+}
+
+// parseWordCost parses either a single cost, or the costs of substitutions,
+// deletions and insertions.
+func parseWordCost(fields []string) (WordCost, error) {
+	costs := make([]float64, len(fields))
+
+	for i, f := range fields {
+		c, err := strconv.ParseFloat(f, 64) //nolint: gomnd // bit size.
+		if err != nil {
+			return WordCost{}, err
+		}
+
+		if c < 0 {
+			return WordCost{}, fmt.Errorf("costs must be >= 0, got %g", c)
+		}
+
+		costs[i] = c
+	}
+
+	switch len(costs) {
+	case 1:
+		return WordCost{Sub: costs[0], Del: costs[0], Ins: costs[0]}, nil
+	case 3: //nolint: gomnd // sub, del and ins.
+		return WordCost{Sub: costs[0], Del: costs[1], Ins: costs[2]}, nil
+	default:
+		return WordCost{}, fmt.Errorf("expected 1 or 3 costs, got %d", len(costs))
+	}
+}
+
+// Cost returns the cost of the given word: the costs of the word if listed,
+// otherwise those of the first pattern matching it, otherwise a cost of 1 for
+// all errors.
+func (t *WeightTable) Cost(word string) WordCost {
+	if c, ok := t.words[word]; ok {
+		return c
+	}
+
+	for _, p := range t.patterns {
+		if p.re.MatchString(word) {
+			return p.cost
+		}
+	}
+
+	return unitCost
+}
+
+// WeightedMetrics are the error rate of a system alongside its weighted error
+// rate. Each substitution and deletion costs as much as the respective cost of
+// the reference word, and each insertion the insertion cost of the hypothesis
+// word. The weighted error rate is the total cost as a percentage of the
+// deletion costs of all reference words, so that it is 100% when all words are
+// deleted, and equal to the error rate when all costs are 1.
+type WeightedMetrics struct {
+	System    string  `json:"system"`
+	RefWords  int     `json:"ref_words"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+
+	RefWeight         float64 `json:"ref_weight"`
+	SubCost           float64 `json:"sub_cost"`
+	DelCost           float64 `json:"del_cost"`
+	InsCost           float64 `json:"ins_cost"`
+	WeightedErrors    float64 `json:"weighted_errors"`
+	WeightedErrorRate float64 `json:"weighted_error_rate"`
+}
+
+// WeightedErrorRate computes the weighted error rate of the aligned sentences
+// of a system, with the costs of words given by the weight table.
+func WeightedErrorRate(aligned *sctk.AlignedHypothesis, t *WeightTable) WeightedMetrics {
+	m := WeightedMetrics{System: aligned.SystemName}
+
+	for _, sents := range aligned.Speakers {
+		for _, s := range sents {
+			st := s.Stats()
+			m.RefWords += st.RefWords
+			m.Errors += st.Errors()
+
+			for _, w := range s.Words {
+				switch w.Label {
+				case "S":
+					m.SubCost += t.Cost(w.Ref).Sub
+				case "D":
+					m.DelCost += t.Cost(w.Ref).Del
+				case "I":
+					m.InsCost += t.Cost(w.Hyp).Ins
+					continue
+				}
+
+				m.RefWeight += t.Cost(w.Ref).Del
+			}
+		}
+	}
+
+	m.ErrorRate = sctk.ErrorRate(m.Errors, m.RefWords)
+	m.WeightedErrors = m.SubCost + m.DelCost + m.InsCost
+
+	if m.RefWeight > 0 {
+		m.WeightedErrorRate = 100 * m.WeightedErrors / m.RefWeight //nolint: gomnd // percentage.
+	}
+
+	return m
+}
+
+// ScoreWeighted computes the weighted error rates of the systems scored in a
+// run, from the alignments written to its output directory, with the costs of
+// words given by the weight table at the given path. The error rates are
+// written to weighted.json in the output directory. The normalization must
+// match that of the run.
+func ScoreWeighted(weightTable string, normCfg NormalizeConfig, outDir string) ([]WeightedMetrics, error) {
+	t, err := ReadWeightTable(weightTable, normCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	systems, err := compare.LoadRun(outDir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := textutils.SortedKeys(systems)
+
+	metrics := make([]WeightedMetrics, 0, len(names))
+
+	for _, name := range names {
+		m := WeightedErrorRate(systems[name], t)
+
+		logrus.WithFields(logrus.Fields{
+			"system":              m.System,
+			"error_rate":          fmt.Sprintf("%.2f%%", m.ErrorRate),
+			"weighted_error_rate": fmt.Sprintf("%.2f%%", m.WeightedErrorRate),
+		}).Info("computed weighted error rate")
+
+		metrics = append(metrics, m)
+	}
+
+	data, err := json.MarshalIndent(metrics, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode weighted error rates: %w", err)
+	}
+
+	if err := os.WriteFile(path.Join(outDir, WeightedFile), data, 0644); err != nil { //nolint: gomnd // file permissions.
+		return nil, fmt.Errorf("failed to write weighted error rates: %w", err)
+	}
+
+	return metrics, nil
+}
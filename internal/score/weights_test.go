@@ -0,0 +1,102 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package score
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+func TestWeightedErrorRate(t *testing.T) {
+	t.Parallel()
+
+	aligned := &sctk.AlignedHypothesis{
+		SystemName: "hyp1",
+		Speakers: map[string]sctk.SpeakerSentences{
+			"spk1": {
+				"(spk1-u1)": {
+					Words: []sctk.AlignedWord{
+						{Label: "C", Ref: "my", Hyp: "my"},
+						{Label: "S", Ref: "42", Hyp: "47"},
+						{Label: "D", Ref: "the"},
+						{Label: "I", Hyp: "uh"},
+						{Label: "C", Ref: "account", Hyp: "account"},
+					},
+				},
+				"(spk1-u2)": {
+					Words: []sctk.AlignedWord{
+						{Label: "S", Ref: "a", Hyp: "the"},
+						{Label: "I", Hyp: "7"},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		weightTable string
+		want        WeightedMetrics
+		wantErr     bool
+	}{
+		{
+			name:        "table",
+			weightTable: "testdata/weights/table.txt",
+			want: WeightedMetrics{
+				System: "hyp1", RefWords: 5, Errors: 5, ErrorRate: 100,
+				// my, 42, the, account and a.
+				RefWeight: 1 + 5 + 0.5 + 1 + 1,
+				SubCost:   5 + 1, DelCost: 0.5, InsCost: 0.1 + 5,
+				WeightedErrors:    11.6,
+				WeightedErrorRate: 100 * 11.6 / 8.5,
+			},
+		},
+		{name: "badCosts", weightTable: "testdata/weights/bad_costs.txt", wantErr: true},
+		{name: "badPattern", weightTable: "testdata/weights/bad_pattern.txt", wantErr: true},
+		{name: "duplicate", weightTable: "testdata/weights/duplicate.txt", wantErr: true},
+		{name: "missing", weightTable: "testdata/weights/missing.txt", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			table, err := ReadWeightTable(tc.weightTable, NormalizeConfig{})
+			if tc.wantErr {
+				if err == nil {
+					subT.Errorf("did not get expected error, want=non-nil, got=nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got := WeightedErrorRate(aligned, table)
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+				subT.Errorf("unexpected weighted error rate, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// AlignmentSchemaVersion is the version of the JSON format alignments are
+// written in, as the "schema_version" field of .pra.json files. It is
+// incremented whenever fields are renamed or removed, or their meaning changes.
+// The format is described by docs/alignment.schema.json.
+//
+// Files without a schema version were written before the format was versioned.
+// They are read the same as version 1, except that the system name and
+// speaker ID of sentences are filled in from the enclosing objects.
+const AlignmentSchemaVersion = 1
+
+// alignedHypothesisFields has the fields of AlignedHypothesis without its JSON
+// methods.
+type alignedHypothesisFields AlignedHypothesis
+
+// alignedHypothesisJSON is an AlignedHypothesis as written in JSON.
+type alignedHypothesisJSON struct {
+	SchemaVersion int `json:"schema_version"`
+	alignedHypothesisFields
+}
+
+// alignedSentenceFields has the fields of AlignedSentence without its JSON
+// methods.
+type alignedSentenceFields AlignedSentence
+
+// alignedSentenceJSON is an AlignedSentence as written in JSON, with its
+// statistics. These are derived from the words, so they are not read back.
+type alignedSentenceJSON struct {
+	alignedSentenceFields
+	Stats SentenceStats `json:"stats"`
+}
+
+// MarshalJSON encodes the sentence along with its statistics.
+func (s AlignedSentence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(alignedSentenceJSON{
+		alignedSentenceFields: alignedSentenceFields(s),
+		Stats:                 s.Stats(),
+	})
+}
+
+// MarshalJSON encodes the alignments in the current JSON schema version.
+func (a AlignedHypothesis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(alignedHypothesisJSON{
+		SchemaVersion:           AlignmentSchemaVersion,
+		alignedHypothesisFields: alignedHypothesisFields(a),
+	})
+}
+
+// UnmarshalJSON decodes alignments written in the current or an older JSON
+// schema version.
+func (a *AlignedHypothesis) UnmarshalJSON(data []byte) error {
+	var v alignedHypothesisJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	if v.SchemaVersion > AlignmentSchemaVersion {
+		return fmt.Errorf(
+			"unsupported alignment schema version %d, supported <= %d",
+			v.SchemaVersion, AlignmentSchemaVersion,
+		)
+	}
+
+	*a = AlignedHypothesis(v.alignedHypothesisFields)
+
+	// Sentences are indexed by speaker and sentence ID, so these are only
+	// missing from files written before the schema was versioned, or by hand.
+	for spk, sents := range a.Speakers {
+		for id, s := range sents {
+			if s == nil {
+				return fmt.Errorf("missing alignment of sentence %q of speaker %q", id, spk)
+			}
+
+			if s.SystemName == "" {
+				s.SystemName = a.SystemName
+			}
+
+			if s.SpeakerID == "" {
+				s.SpeakerID = spk
+			}
+
+			if s.SentenceID == "" {
+				s.SentenceID = id
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReadAlignmentJSON reads the alignments in the given .pra.json file, written
+// by WriteAlignments or an earlier version of this package.
+func ReadAlignmentJSON(jsonPath string) (*AlignedHypothesis, error) {
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alignment file: %w", err)
+	}
+
+	var aligned AlignedHypothesis
+	if err := json.Unmarshal(data, &aligned); err != nil {
+		return nil, fmt.Errorf("failed to decode alignment file %q: %w", jsonPath, err)
+	}
+
+	return &aligned, nil
+}
@@ -0,0 +1,151 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAlignmentJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		sgmlPath string
+	}{
+		{name: "wer", sgmlPath: "testdata/sgml/good1.bangla.trn.sgml"},
+		{name: "multipleSpeakers", sgmlPath: "testdata/sgml/good2.bangla.trn.sgml"},
+		{name: "stmCtm", sgmlPath: "testdata/sgml/good3.stm.ctm.sgml"},
+		{name: "cer", sgmlPath: "testdata/sclite/cer/good1_hyp1.trn.sgml"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			want, err := ReadAlignmentSgml(tc.sgmlPath)
+			if err != nil {
+				subT.Fatalf("unexpected error while reading sgml file, want=nil, got=%v", err)
+			}
+
+			outPrefix := path.Join(subT.TempDir(), "hyp")
+			if err := WriteAlignments(outPrefix, want, []string{AlignmentFormatJSON}); err != nil {
+				subT.Fatalf("unexpected error while writing alignments, want=nil, got=%v", err)
+			}
+
+			got, err := ReadAlignmentJSON(outPrefix + ".pra.json")
+			if err != nil {
+				subT.Fatalf("unexpected error while reading alignments, want=nil, got=%v", err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				subT.Errorf("alignments changed in round trip, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadAlignmentJSON(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		jsonPath string
+		want     *AlignedHypothesis
+		wantErr  bool
+	}{
+		{
+			name:     "unversioned",
+			jsonPath: "testdata/json/legacy.pra.json",
+			want: &AlignedHypothesis{
+				SystemName: "hyp1",
+				Speakers: map[string]SpeakerSentences{
+					"spk1": {
+						"(spk1-u1)": &AlignedSentence{
+							SystemName: "hyp1",
+							SpeakerID:  "spk1",
+							SentenceID: "(spk1-u1)",
+							WordCount:  2,
+							Words: []AlignedWord{
+								{Label: "C", Ref: "hello", Hyp: "hello"},
+								{Label: "S", Ref: "world", Hyp: "word"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:     "unsupportedVersion",
+			jsonPath: "testdata/json/future.pra.json",
+			wantErr:  true,
+		},
+		{
+			name:     "missingFile",
+			jsonPath: "testdata/json/missing.pra.json",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got, err := ReadAlignmentJSON(tc.jsonPath)
+			if (err != nil) != tc.wantErr {
+				subT.Fatalf("unexpected error, wantErr=%v, got=%v", tc.wantErr, err)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected alignments, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAlignmentSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("../../docs/alignment.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+
+	var schema struct {
+		Properties struct {
+			SchemaVersion struct {
+				Const int `json:"const"`
+			} `json:"schema_version"`
+		} `json:"properties"`
+	}
+
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("failed to decode schema: %v", err)
+	}
+
+	if got := schema.Properties.SchemaVersion.Const; got != AlignmentSchemaVersion {
+		t.Errorf("schema documents version %d, want=%d", got, AlignmentSchemaVersion)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+// runAsclite aligns each of the given ctm hypothesis files against the stm
+// reference with asclite, which considers overlapping reference segments of
+// several speakers at once. Like sclite, asclite writes the alignments to
+// <hyp>.sgml in the output directory, which is then piped into sclite to
+// generate the configured reports.
+func runAsclite(
+	ctx context.Context, cfg ScliteCfg, outDir, refFile string, hypFiles []Hypothesis,
+) error {
+	ascliteBin, err := embedded.Asclite()
+	if err != nil {
+		return err
+	}
+
+	scliteBin, err := embedded.Sclite()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, hyp := range hypFiles {
+		args := []string{
+			"-r", refFile, FormatStm,
+			"-h", hyp.FilePath, FormatCtm, hyp.SystemName,
+			"-O", outDir,
+			"-o", "sgml",
+			// Normalization step before running asclite adjusts for sensitivity;
+			// so asclite is set to be always case sensitive.
+			"-s",
+		}
+
+		if cfg.OverlapLimit > 0 {
+			args = append(args, "-overlap-limit", strconv.Itoa(cfg.OverlapLimit))
+		}
+
+		cmd := exec.CommandContext(ctx, ascliteBin, args...)
+
+		if stderr, err := cmd.CombinedOutput(); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"args":   strings.Join(args, " "),
+				"stderr": string(stderr),
+			}).Error("asclite encountered errors")
+
+			return fmt.Errorf("failed to run asclite: %w", err)
+		}
+
+		sgmlFile := path.Join(outDir, path.Base(hyp.FilePath)+".sgml")
+		if _, err := os.Stat(sgmlFile); err != nil {
+			return fmt.Errorf("asclite did not write alignments: %w", err)
+		}
+
+		if err := runSclitePiped(ctx, cfg, scliteBin, outDir, sgmlFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
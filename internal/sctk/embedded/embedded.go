@@ -18,10 +18,20 @@
 package embedded
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
 )
 
 //go:embed bin
@@ -30,12 +40,50 @@ var sctk embed.FS
 const (
 	scliteBin  = "sclite"
 	scStatsBin = "sc_stats"
+	roverBin   = "rover"
+	ascliteBin = "asclite"
 )
 
 const (
-	executablePerm = 0777
+	executablePerm   = 0777
+	privateDirPerm   = 0700
+	writableByOthers = 0022
+)
+
+// EnvBinDir is the environment variable which can be set to a directory
+// containing system installed SCTK tools, which will then be used instead of
+// the embedded ones.
+const EnvBinDir = "SCTK_BIN_DIR"
+
+// ErrNotEmbedded is returned when a SCTK tool is not embedded with this tool.
+// sclite and sc_stats are always embedded; rover and asclite are only embedded
+// when built from the SCTK sources with make.
+var ErrNotEmbedded = errors.New("not embedded with this tool")
+
+var (
+	// binDirOverride is the directory set with SetBinDir.
+	binDirOverride string
+
+	checksumOnce sync.Once
+	checksum     string
+	checksumErr  error
 )
 
+// SetBinDir sets the directory containing system installed SCTK tools, which
+// will be used instead of the embedded ones. It takes precedence over the
+// directory set with the SCTK_BIN_DIR environment variable. An empty string
+// unsets the override.
+func SetBinDir(dir string) {
+	binDirOverride = dir
+}
+
+// BinDir returns the directory containing system installed SCTK tools that are
+// used instead of the embedded ones, or an empty string if the embedded tools
+// are used.
+func BinDir() string {
+	return getBinDirOverride()
+}
+
 // Sclite returns the path to the sclite executable. If the executable is not
 // embedded or cannot be written to the user cache directory, this function will
 // written an error.
@@ -50,43 +98,214 @@ func ScStats() (string, error) {
 	return getBinPath(scStatsBin)
 }
 
-// getBinPath returns the executable path of the given binary. If the executable
-// is not embedded or cannot be written to the user cache directory, this
-// function will written an error.
+// Rover returns the path to the rover executable. If the executable is not
+// embedded or cannot be written to the user cache directory, this function will
+// written an error.
+func Rover() (string, error) {
+	return getBinPath(roverBin)
+}
+
+// Asclite returns the path to the asclite executable. If the executable is not
+// embedded or cannot be written to the user cache directory, this function will
+// written an error.
+func Asclite() (string, error) {
+	return getBinPath(ascliteBin)
+}
+
+// Checksum returns the hex encoded SHA-256 checksum of all the SCTK tools
+// embedded with this tool. Embedded tools are cached on disk in a directory
+// keyed by this checksum, so that different versions never share binaries.
+func Checksum() (string, error) {
+	checksumOnce.Do(func() {
+		names, err := fs.Glob(sctk, "bin/*")
+		if err != nil {
+			checksumErr = fmt.Errorf("failed to list embedded binaries: %w", err)
+			return
+		}
+
+		sort.Strings(names)
+
+		h := sha256.New()
+
+		for _, name := range names {
+			if strings.HasSuffix(name, ".go") {
+				continue
+			}
+
+			data, err := sctk.ReadFile(name)
+			if err != nil {
+				checksumErr = fmt.Errorf("failed to read embedded binary %q: %w", name, err)
+				return
+			}
+
+			// Including the name so that renaming a binary changes the checksum.
+			h.Write([]byte(name))
+			h.Write([]byte{0})
+			h.Write(data)
+		}
+
+		checksum = hex.EncodeToString(h.Sum(nil))
+	})
+
+	return checksum, checksumErr
+}
+
+// getBinPath returns the executable path of the given binary. If a directory
+// with system installed SCTK tools has been configured, the binary is looked up
+// there. Otherwise the embedded binary is written to the user cache directory,
+// or a temporary directory if the cache is not writable. If the executable is
+// not embedded or cannot be written, this function will return an error.
 func getBinPath(binName string) (string, error) {
-	cacheDir, err := os.UserCacheDir()
+	if binDir := getBinDirOverride(); binDir != "" {
+		binPath := path.Join(binDir, binName)
+		if _, err := os.Stat(binPath); err != nil {
+			return "", fmt.Errorf("failed to find %q in sctk binary directory: %w", binName, err)
+		}
+
+		return binPath, nil
+	}
+
+	data, err := sctk.ReadFile(path.Join("bin", binName))
 	if err != nil {
-		return "", fmt.Errorf("failed to get binary cache directory: %w", err)
+		return "", fmt.Errorf(
+			"%q %w; build it from SCTK with make, or use system installed SCTK tools with --sctk-bin-dir",
+			binName, ErrNotEmbedded,
+		)
 	}
 
-	binDir := path.Join(cacheDir, "sctk")
-	if err := os.MkdirAll(binDir, executablePerm); err != nil {
-		return "", fmt.Errorf("failed to create sctk binary directory: %w", err)
+	sum, err := Checksum()
+	if err != nil {
+		return "", err
+	}
+
+	// Using a prefix of the checksum keeps paths short while still being unique
+	// for all practical purposes.
+	version := sum[:16]
+
+	binDirs := make([]string, 0, 2)
+
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		binDirs = append(binDirs, path.Join(cacheDir, "sctk", version))
 	}
 
-	binPath := path.Join(binDir, binName)
-	if _, err := os.Stat(binPath); err == nil {
+	var errs []string
+
+	// Since other users can create files in the temporary directory, it is only
+	// used if the directory for the current user there is safe to use.
+	tmpDir := path.Join(os.TempDir(), fmt.Sprintf("sctk-%d", os.Getuid()))
+	if err := makePrivateDir(tmpDir); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		binDirs = append(binDirs, path.Join(tmpDir, version))
+	}
+
+	for _, binDir := range binDirs {
+		binPath := path.Join(binDir, binName)
+
+		if err := writeBin(binPath, data); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"path":  binPath,
+				"error": err,
+			}).Debug("failed to write sctk binary, trying next location")
+
+			errs = append(errs, err.Error())
+
+			continue
+		}
+
 		return binPath, nil
 	}
 
-	if err := writeBinToUserCache(binName, binPath); err != nil {
-		return "", err
+	return "", fmt.Errorf("failed to write %q to any binary directory: %s", binName, strings.Join(errs, "; "))
+}
+
+// makePrivateDir creates the given directory with permissions for the current
+// user only. If it already exists, it must be a directory, not a symbolic link,
+// owned by the current user and not writable by others, since another user
+// could otherwise replace the binaries written to it.
+func makePrivateDir(dir string) error {
+	if err := os.Mkdir(dir, privateDirPerm); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to create private sctk directory: %w", err)
 	}
 
-	return binPath, nil
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check private sctk directory: %w", err)
+	}
+
+	if !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+
+	if uid, ok := fileOwner(fi); ok && uid != os.Getuid() {
+		return fmt.Errorf("%q is owned by another user, uid %d", dir, uid)
+	}
+
+	if fi.Mode().Perm()&writableByOthers != 0 {
+		return fmt.Errorf("%q is writable by other users, mode %s", dir, fi.Mode().Perm())
+	}
+
+	return nil
 }
 
-// writeBinToUserCache writes the SCTK binary with the given name from the
-// embedded FS to the provided path and sets executable permissions.
-func writeBinToUserCache(binName, binPath string) error {
-	const (
-		executablePerm = 0777
-	)
+// getBinDirOverride returns the directory containing system installed SCTK
+// tools, if one has been configured.
+func getBinDirOverride() string {
+	if binDirOverride != "" {
+		return binDirOverride
+	}
 
-	data, err := sctk.ReadFile(path.Join("bin", binName))
+	return os.Getenv(EnvBinDir)
+}
+
+// writeBin writes the given binary data to the provided path and sets
+// executable permissions, unless a file with identical contents already exists
+// there. The file is first written to a temporary file in the same directory
+// and then renamed, so that concurrent processes never see a partially written
+// binary.
+func writeBin(binPath string, data []byte) error {
+	if existing, err := os.ReadFile(binPath); err == nil {
+		if bytes.Equal(existing, data) {
+			return nil
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"path": binPath,
+		}).Warn("cached sctk binary does not match embedded binary, replacing it")
+	}
+
+	binDir := path.Dir(binPath)
+	if err := os.MkdirAll(binDir, executablePerm); err != nil {
+		return fmt.Errorf("failed to create sctk binary directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(binDir, "."+path.Base(binPath)+"-*")
 	if err != nil {
-		return fmt.Errorf("%q not embedded with this tool", binName)
+		return fmt.Errorf("failed to create temporary binary file: %w", err)
+	}
+
+	tmpPath := f.Name()
+
+	// Cleaning up the temporary file if anything fails before the rename.
+	defer os.Remove(tmpPath)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write binary: %w", err)
+	}
+
+	if err := f.Chmod(executablePerm); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to set executable permissions: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close binary file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, binPath); err != nil {
+		return fmt.Errorf("failed to move binary into place: %w", err)
 	}
 
-	return os.WriteFile(binPath, data, executablePerm)
+	return nil
 }
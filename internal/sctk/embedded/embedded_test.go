@@ -0,0 +1,200 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package embedded
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestWriteBin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		existing []byte
+	}{
+		{
+			name:     "missing",
+			existing: nil,
+		},
+		{
+			name:     "truncated",
+			existing: []byte("#!/bin/s"),
+		},
+		{
+			name:     "up_to_date",
+			existing: []byte("#!/bin/sh\necho sclite\n"),
+		},
+	}
+
+	data := []byte("#!/bin/sh\necho sclite\n")
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			binDir := subT.TempDir()
+			binPath := path.Join(binDir, scliteBin)
+
+			if tc.existing != nil {
+				if err := os.WriteFile(binPath, tc.existing, executablePerm); err != nil {
+					subT.Fatalf("failed to write existing binary: %v", err)
+				}
+			}
+
+			if err := writeBin(binPath, data); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got, err := os.ReadFile(binPath)
+			if err != nil {
+				subT.Fatalf("failed to read written binary: %v", err)
+			}
+
+			if !bytes.Equal(data, got) {
+				subT.Errorf("unexpected binary contents, want=%q, got=%q", data, got)
+			}
+
+			entries, err := os.ReadDir(binDir)
+			if err != nil {
+				subT.Fatalf("failed to list binary directory: %v", err)
+			}
+
+			if len(entries) != 1 {
+				subT.Errorf("unexpected number of files in binary directory, want=1, got=%d", len(entries))
+			}
+		})
+	}
+}
+
+func TestGetBinPathOverride(t *testing.T) {
+	binDir := t.TempDir()
+
+	SetBinDir(binDir)
+	defer SetBinDir("")
+
+	if _, err := Sclite(); err == nil {
+		t.Errorf("did not get expected error for missing binary, want=non-nil, got=nil")
+	}
+
+	want := path.Join(binDir, scliteBin)
+	if err := os.WriteFile(want, []byte("#!/bin/sh\n"), executablePerm); err != nil {
+		t.Fatalf("failed to write binary: %v", err)
+	}
+
+	got, err := Sclite()
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	if got != want {
+		t.Errorf("unexpected binary path, want=%q, got=%q", want, got)
+	}
+}
+
+func TestMakePrivateDir(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		setup   func(dir string) error
+		wantErr bool
+	}{
+		{
+			name:  "missing",
+			setup: func(dir string) error { return nil },
+		},
+		{
+			name:  "existing",
+			setup: func(dir string) error { return os.Mkdir(dir, privateDirPerm) },
+		},
+		{
+			name: "writableByOthers",
+			setup: func(dir string) error {
+				if err := os.Mkdir(dir, privateDirPerm); err != nil {
+					return err
+				}
+
+				return os.Chmod(dir, 0777)
+			},
+			wantErr: true,
+		},
+		{
+			name: "symlink",
+			setup: func(dir string) error {
+				target := dir + "-target"
+				if err := os.Mkdir(target, privateDirPerm); err != nil {
+					return err
+				}
+
+				return os.Symlink(target, dir)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "file",
+			setup:   func(dir string) error { return os.WriteFile(dir, nil, 0600) },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			dir := path.Join(subT.TempDir(), "sctk")
+			if err := tc.setup(dir); err != nil {
+				subT.Fatalf("failed to set up directory: %v", err)
+			}
+
+			err := makePrivateDir(dir)
+			if tc.wantErr {
+				if err == nil {
+					subT.Errorf("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			fi, err := os.Lstat(dir)
+			if err != nil {
+				subT.Fatalf("failed to stat directory: %v", err)
+			}
+
+			if perm := fi.Mode().Perm(); perm != privateDirPerm {
+				subT.Errorf("unexpected directory permissions, want=%v, got=%v", os.FileMode(privateDirPerm), perm)
+			}
+		})
+	}
+}
+
+func TestGetBinPathNotEmbedded(t *testing.T) {
+	if _, err := getBinPath("missing"); !errors.Is(err, ErrNotEmbedded) {
+		t.Errorf("unexpected error for binary that is not embedded, want=%v, got=%v", ErrNotEmbedded, err)
+	}
+}
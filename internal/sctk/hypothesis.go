@@ -18,10 +18,34 @@
 // reference transcripts.
 package sctk
 
+// Input file formats supported by SCTK tools.
+const (
+	// FormatTrn is the transcript format - "<transcript> (<uttID>)".
+	FormatTrn = "trn"
+	// FormatCtm is the time marked conversation format, with one word per line -
+	// "<file> <channel> <start> <duration> <word> [<confidence>]".
+	FormatCtm = "ctm"
+	// FormatStm is the segment time mark format, with one segment per line -
+	// "<file> <channel> <speaker> <start> <end> [<label>] <transcript>".
+	FormatStm = "stm"
+)
+
 // A Hypothesis points to a file containing transcripts from an ASR system,
 // along with the name of the system. The name will be used in generated
 // reports.
 type Hypothesis struct {
 	SystemName string
 	FilePath   string
+
+	// Format of the hypothesis file; FormatTrn if empty.
+	Format string
+}
+
+// format returns the format of the hypothesis file.
+func (h *Hypothesis) format() string {
+	if h.Format == "" {
+		return FormatTrn
+	}
+
+	return h.Format
 }
@@ -0,0 +1,131 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+// GenerateReports pipes the alignments in the given sgml file, generated by
+// sclite earlier, back into sclite to generate the configured reports in
+// outDir. The reports are named after the sgml file. The sgml report itself is
+// not written again.
+func GenerateReports(ctx context.Context, cfg ScliteCfg, outDir, sgmlFile string) error {
+	scliteBin, err := embedded.Sclite()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return runSclitePiped(ctx, cfg, scliteBin, outDir, sgmlFile)
+}
+
+// FilterSgml writes the alignments in the given sgml file to outFile, keeping
+// only the sentences for which keep returns true. Speakers left without any
+// sentences are dropped. The number of sentences kept is returned.
+func FilterSgml(
+	sgmlFile, outFile string, keep func(speakerID, sentenceID string) bool,
+) (int, error) {
+	data, err := os.ReadFile(sgmlFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sgml file: %w", err)
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create filtered sgml file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	var (
+		idAttr     = regexp.MustCompile(`\bid="([^"]*)"`)
+		w          = bufio.NewWriter(f)
+		speaker    string
+		speakerID  string
+		speakerOut bool
+		inPath     bool
+		keepPath   bool
+		numKept    int
+	)
+
+	attrValue := func(line string) string {
+		if m := idAttr.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+
+		return ""
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		switch {
+		case inPath:
+			if keepPath {
+				w.WriteString(line + "\n")
+			}
+
+			if strings.HasPrefix(line, "</PATH>") {
+				inPath = false
+			}
+
+		case strings.HasPrefix(line, "<SPEAKER"):
+			// Speakers are only written once one of their sentences is kept.
+			speaker, speakerID, speakerOut = line, attrValue(line), false
+
+		case strings.HasPrefix(line, "<PATH"):
+			inPath = true
+			keepPath = keep(speakerID, attrValue(line))
+
+			if !keepPath {
+				continue
+			}
+
+			if !speakerOut {
+				w.WriteString(speaker + "\n")
+				speakerOut = true
+			}
+
+			w.WriteString(line + "\n")
+			numKept++
+
+		case strings.HasPrefix(line, "</SPEAKER>"):
+			if speakerOut {
+				w.WriteString(line + "\n")
+			}
+
+			speaker, speakerID, speakerOut = "", "", false
+
+		case strings.HasPrefix(line, "<SYSTEM"):
+			w.WriteString(sanitizeSystemTag(line) + "\n")
+
+		case line != "":
+			w.WriteString(line + "\n")
+		}
+	}
+
+	return numKept, w.Flush()
+}
@@ -0,0 +1,141 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+// Voting schemes supported by rover for combining hypotheses.
+const (
+	// RoverFrequency picks the word that occurs most frequently among the
+	// hypotheses at each position.
+	RoverFrequency = "frequency"
+	// RoverAvgConf weighs the frequency of each word with its average
+	// confidence score.
+	RoverAvgConf = "avgconf"
+	// RoverMaxConf weighs the frequency of each word with its maximum
+	// confidence score.
+	RoverMaxConf = "maxconf"
+)
+
+// RoverCfg configures how rover combines multiple hypotheses.
+type RoverCfg struct {
+	// Method is the voting scheme; one of RoverFrequency, RoverAvgConf or
+	// RoverMaxConf.
+	Method string
+
+	// Alpha is the trade-off between word frequency and confidence scores used
+	// by the confidence based voting schemes. An alpha of 1 only uses word
+	// frequency.
+	Alpha float64
+
+	// NullConf is the confidence score assigned to null words (deletions) by the
+	// confidence based voting schemes.
+	NullConf float64
+}
+
+// Validate checks whether all configured options are valid and supported by
+// rover.
+func (c *RoverCfg) Validate() error {
+	switch c.Method {
+	case RoverFrequency, RoverAvgConf, RoverMaxConf:
+	default:
+		return fmt.Errorf(
+			"unsupported rover method %q, supported %s|%s|%s",
+			c.Method, RoverFrequency, RoverAvgConf, RoverMaxConf,
+		)
+	}
+
+	if c.Alpha < 0 || c.Alpha > 1 {
+		return fmt.Errorf("rover alpha must be in the range [0, 1]")
+	}
+
+	if c.NullConf < 0 || c.NullConf > 1 {
+		return fmt.Errorf("rover null confidence must be in the range [0, 1]")
+	}
+
+	return nil
+}
+
+// RunRover executes the rover tool on the given hypothesis files, which must be
+// in the CTM format, and writes the combined hypothesis to outFile in the CTM
+// format.
+func RunRover(
+	ctx context.Context, cfg RoverCfg, outFile string, hypFiles []Hypothesis,
+) error {
+	if len(hypFiles) < 2 {
+		return fmt.Errorf("at least two hypothesis files are needed for combination")
+	}
+
+	args := roverArgs(cfg, outFile, hypFiles)
+
+	roverBin, err := embedded.Rover()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(path.Dir(outFile), filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, roverBin, args...)
+
+	if stderr, err := cmd.CombinedOutput(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"args":   strings.Join(args, " "),
+			"stderr": string(stderr),
+		}).Error("rover encountered errors")
+
+		return fmt.Errorf("failed to run rover: %w", err)
+	}
+
+	return nil
+}
+
+// roverArgs returns the rover arguments combining the given ctm hypothesis
+// files into outFile with the configured voting scheme.
+func roverArgs(cfg RoverCfg, outFile string, hypFiles []Hypothesis) []string {
+	args := make([]string, 0, 3*len(hypFiles)+8)
+
+	for _, hyp := range hypFiles {
+		args = append(args, "-h", hyp.FilePath, "ctm")
+	}
+
+	args = append(args, "-o", outFile)
+
+	switch cfg.Method {
+	case RoverFrequency:
+		args = append(args, "-m", "meth1")
+	default:
+		args = append(args,
+			"-m", cfg.Method,
+			"-a", fmt.Sprintf("%g", cfg.Alpha),
+			"-c", fmt.Sprintf("%g", cfg.NullConf),
+		)
+	}
+
+	return args
+}
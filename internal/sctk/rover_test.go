@@ -0,0 +1,79 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRoverArgs(t *testing.T) {
+	t.Parallel()
+
+	hypFiles := []Hypothesis{
+		{SystemName: "sys1", FilePath: "out/sys1.ctm"},
+		{SystemName: "sys2", FilePath: "out/sys2.ctm"},
+	}
+
+	testCases := []struct {
+		name string
+		cfg  RoverCfg
+		want []string
+	}{
+		{
+			name: "frequency",
+			cfg:  RoverCfg{Method: RoverFrequency, Alpha: 0.5, NullConf: 0.7},
+			want: []string{
+				"-h", "out/sys1.ctm", "ctm", "-h", "out/sys2.ctm", "ctm",
+				"-o", "out/combined.ctm", "-m", "meth1",
+			},
+		},
+		{
+			name: "avgconf",
+			cfg:  RoverCfg{Method: RoverAvgConf, Alpha: 0.5, NullConf: 0.7},
+			want: []string{
+				"-h", "out/sys1.ctm", "ctm", "-h", "out/sys2.ctm", "ctm",
+				"-o", "out/combined.ctm", "-m", "avgconf", "-a", "0.5", "-c", "0.7",
+			},
+		},
+		{
+			name: "maxconf",
+			cfg:  RoverCfg{Method: RoverMaxConf, Alpha: 1, NullConf: 0},
+			want: []string{
+				"-h", "out/sys1.ctm", "ctm", "-h", "out/sys2.ctm", "ctm",
+				"-o", "out/combined.ctm", "-m", "maxconf", "-a", "1", "-c", "0",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			if err := tc.cfg.Validate(); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got := roverArgs(tc.cfg, "out/combined.ctm", hypFiles)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected rover arguments, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -38,14 +38,84 @@ const (
 	filePerm = 0777
 )
 
+// Formats in which alignments are written to the output directory, rendered
+// from the sgml report of sclite.
+const (
+	AlignmentFormatMarkdown = "md"
+	AlignmentFormatHTML     = "html"
+	AlignmentFormatCSV      = "csv"
+	AlignmentFormatTxt      = "txt"
+	AlignmentFormatJSON     = "json"
+	// AlignmentFormatNone disables writing alignments.
+	AlignmentFormatNone = "none"
+)
+
+// Formats in which the per-utterance metrics of all hypotheses are written to
+// the output directory, as utterances.<format>.
+const (
+	UtteranceFormatCSV = "csv"
+	UtteranceFormatTSV = "tsv"
+	// UtteranceFormatNone disables writing per-utterance metrics.
+	UtteranceFormatNone = "none"
+)
+
+// alignmentTableFormats maps the alignment formats rendered as tables to their
+// table format.
+var alignmentTableFormats = map[string]TableFormat{
+	AlignmentFormatMarkdown: TableFormatMarkdown,
+	AlignmentFormatHTML:     TableFormatHTML,
+	AlignmentFormatCSV:      TableFormatCSV,
+	AlignmentFormatTxt:      TableFormatTxt,
+}
+
 // ScliteCfg configures report generation options for sclite.
 type ScliteCfg struct {
-	LineWidth int
-	Encoding  string
-	Reports   []string
-	CER       bool
+	LineWidth int      `json:"line_width"`
+	Encoding  string   `json:"encoding"`
+	Reports   []string `json:"reports"`
+	CER       bool     `json:"cer"`
+
+	// RefFormat is the format of the reference file; FormatTrn if empty. Trn
+	// references must be scored against trn hypotheses, and stm references
+	// against ctm hypotheses.
+	RefFormat string `json:"ref_format"`
+
+	// Jobs is the number of sclite processes to run in parallel. If greater than
+	// one, the hypotheses are split into shards of utterances which are aligned
+	// separately, and the alignments are merged back before generating reports.
+	Jobs int `json:"jobs"`
+
+	// AlignmentFormats are the formats in which alignments are written, as
+	// <hyp>.pra.<format> files; the default set if empty, or none if only
+	// AlignmentFormatNone. Writing alignments requires the sgml report, which
+	// is added to Reports if missing.
+	AlignmentFormats []string `json:"alignment_formats,omitempty"`
+
+	// UtteranceFormats are the formats in which the per-utterance metrics of
+	// all hypotheses are written, as utterances.<format> files; csv if empty, or
+	// none if only UtteranceFormatNone. Like alignments, they are read from the
+	// sgml report.
+	UtteranceFormats []string `json:"utterance_formats,omitempty"`
+
+	// WordWeights is the path to a word weight list (WWL) file with which sclite
+	// performs word weight mediated alignments, or WordWeightsUnity to weigh all
+	// words equally. The weights of aligned words are then available in the
+	// alignments. If empty, alignments are not weighted.
+	WordWeights string `json:"word_weights,omitempty"`
+
+	// Overlap aligns stm references against ctm hypotheses with asclite instead
+	// of sclite, which aligns the hypothesis against overlapping reference
+	// segments of several speakers at once. OverlapLimit is the maximum number
+	// of overlapping speakers considered; asclite's default if 0. Reports are
+	// generated by sclite from the alignments of asclite.
+	Overlap      bool `json:"overlap,omitempty"`
+	OverlapLimit int  `json:"overlap_limit,omitempty"`
 }
 
+// WordWeightsUnity makes sclite weigh all words equally in word weight
+// mediated alignments.
+const WordWeightsUnity = "unity"
+
 // Validate checks whether all configured options are valid and supported by
 // sclite.
 func (c *ScliteCfg) Validate() error {
@@ -62,6 +132,18 @@ func (c *ScliteCfg) Validate() error {
 		return fmt.Errorf("line width must be >= %d", minLineWidth)
 	}
 
+	if c.Jobs < 0 {
+		return fmt.Errorf("number of jobs must be >= 0")
+	}
+
+	switch c.RefFormat {
+	case "", FormatTrn, FormatStm:
+	default:
+		return fmt.Errorf(
+			"unsupported reference format %q, supported %s|%s", c.RefFormat, FormatTrn, FormatStm,
+		)
+	}
+
 	if !encodingCheck.MatchString(c.Encoding) {
 		return fmt.Errorf(
 			"unsupported encoding option %q, supported %s", c.Encoding, allowedEncoding,
@@ -76,12 +158,55 @@ func (c *ScliteCfg) Validate() error {
 		}
 	}
 
+	for _, f := range c.AlignmentFormats {
+		if _, ok := alignmentTableFormats[f]; ok || f == AlignmentFormatJSON {
+			continue
+		}
+
+		if f == AlignmentFormatNone && len(c.AlignmentFormats) == 1 {
+			continue
+		}
+
+		return fmt.Errorf(
+			"unsupported alignment format %q, supported %s|%s|%s|%s|%s, or %s alone", f,
+			AlignmentFormatMarkdown, AlignmentFormatHTML, AlignmentFormatCSV, AlignmentFormatTxt,
+			AlignmentFormatJSON, AlignmentFormatNone,
+		)
+	}
+
+	for _, f := range c.UtteranceFormats {
+		switch {
+		case f == UtteranceFormatCSV, f == UtteranceFormatTSV:
+		case f == UtteranceFormatNone && len(c.UtteranceFormats) == 1:
+		default:
+			return fmt.Errorf(
+				"unsupported utterance format %q, supported %s|%s, or %s alone", f,
+				UtteranceFormatCSV, UtteranceFormatTSV, UtteranceFormatNone,
+			)
+		}
+	}
+
+	if c.OverlapLimit < 0 {
+		return fmt.Errorf("overlap limit must be >= 0")
+	}
+
+	if c.Overlap && (c.CER || c.WordWeights != "") {
+		return fmt.Errorf("character error rates and word weights are not supported with overlapping speech")
+	}
+
+	if c.WordWeights != "" && c.WordWeights != WordWeightsUnity {
+		if _, err := os.Stat(c.WordWeights); err != nil {
+			return fmt.Errorf("invalid word weight list: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // RunSclite executes the sclite tool on the given reference and hypothesis
 // files, and evaluates word error rates. It also generates alignments between
 // the reference and hypotheses, and optionally character error rate as well.
+// If cfg.Overlap is set, the alignments are made by asclite instead.
 func RunSclite(
 	ctx context.Context, cfg ScliteCfg, outDir, refFile string, hypFiles []Hypothesis,
 ) error {
@@ -89,37 +214,69 @@ func RunSclite(
 		return fmt.Errorf("no hypothesis files provided")
 	}
 
+	refFormat, hypFormat := cfg.refFormat(), FormatTrn
+	if refFormat == FormatStm {
+		hypFormat = FormatCtm
+	}
+
+	for _, hyp := range hypFiles {
+		if hyp.format() != hypFormat {
+			return fmt.Errorf(
+				"%s references can only be scored against %s hypotheses, got %s for %q",
+				refFormat, hypFormat, hyp.format(), hyp.SystemName,
+			)
+		}
+	}
+
+	if cfg.Overlap {
+		if refFormat != FormatStm {
+			return fmt.Errorf("overlapping speech can only be aligned with %s references, got %s", FormatStm, refFormat)
+		}
+
+		if err := runAsclite(ctx, cfg, outDir, refFile, hypFiles); err != nil {
+			return err
+		}
+
+		return genOutputsFromSgml(outDir, cfg)
+	}
+
+	if cfg.Jobs > 1 && refFormat != FormatTrn {
+		logrus.WithFields(log.Fields{
+			"jobs":       cfg.Jobs,
+			"ref_format": refFormat,
+		}).Warn("sharded scoring only supports trn files, running a single sclite process")
+	}
+
+	if cfg.Jobs > 1 && refFormat == FormatTrn {
+		if err := runScliteSharded(ctx, cfg, outDir, refFile, hypFiles); err != nil {
+			return err
+		}
+
+		return genOutputsFromSgml(outDir, cfg)
+	}
+
 	args := []string{
-		"-i", "swb", // UttID format utt ID (swb = switchboard).
-		"-r", refFile, "trn", // Reference file and format.
+		"-r", refFile, refFormat, // Reference file and format.
 		"-O", outDir,
 		"-l", fmt.Sprintf("%d", cfg.LineWidth),
 		"-e", cfg.Encoding,
 	}
 
-	// Use default reports if unspecified.
-	if len(cfg.Reports) == 0 {
-		// We leave out the pra file here, because for non-english alphabets, the
-		// spacing between words in the pra file added to align reference and
-		// hypotheses doesn't always work properly (due to diacritics and font
-		// ligatures). We instead parse the sgml file and generate our own pra file,
-		// with aligned ref and hyp shown in markdown tables.
-		cfg.Reports = []string{"sum", "rsum", "dtl", "sgml"}
+	if refFormat == FormatTrn {
+		args = append(args, "-i", "swb") // UttID format utt ID (swb = switchboard).
 	}
 
 	args = append(args, "-o")
-	args = append(args, cfg.Reports...)
+	args = append(args, cfg.reports()...)
 
 	// Normalization step before running sclite adjusts for sensitivity; so sclite
 	// is set to be always case sensitive.
 	args = append(args, "-s")
 
-	if cfg.CER {
-		args = append(args, "-c")
-	}
+	args = append(args, cfg.alignmentArgs()...)
 
 	for _, hyp := range hypFiles {
-		args = append(args, "-h", hyp.FilePath, "trn", hyp.SystemName)
+		args = append(args, "-h", hyp.FilePath, hyp.format(), hyp.SystemName)
 	}
 
 	scliteBin, err := embedded.Sclite()
@@ -139,10 +296,125 @@ func RunSclite(
 		}).Error("sclite encountered errors")
 	}
 
-	return genAlignmentFileFromSgml(outDir)
+	return genOutputsFromSgml(outDir, cfg)
 }
 
-func genAlignmentFileFromSgml(outDir string) error {
+// alignmentArgs returns the sclite arguments changing how words are aligned.
+func (c *ScliteCfg) alignmentArgs() []string {
+	args := make([]string, 0)
+
+	if c.CER {
+		args = append(args, "-c")
+	}
+
+	if c.WordWeights != "" {
+		args = append(args, "-w", c.WordWeights)
+	}
+
+	return args
+}
+
+// refFormat returns the format of the reference file.
+func (c *ScliteCfg) refFormat() string {
+	if c.RefFormat == "" {
+		return FormatTrn
+	}
+
+	return c.RefFormat
+}
+
+// reports returns the configured sclite reports, or the default set of reports
+// if none were specified. The sgml report is added if alignments or
+// per-utterance metrics are written.
+func (c *ScliteCfg) reports() []string {
+	// We leave out the pra file here, because for non-english alphabets, the
+	// spacing between words in the pra file added to align reference and
+	// hypotheses doesn't always work properly (due to diacritics and font
+	// ligatures). We instead parse the sgml file and generate our own pra file,
+	// with aligned ref and hyp shown in markdown tables.
+	reports := []string{"sum", "rsum", "dtl", "sgml"}
+	if len(c.Reports) != 0 {
+		reports = c.Reports
+	}
+
+	if len(c.alignmentFormats()) == 0 && len(c.utteranceFormats()) == 0 {
+		return reports
+	}
+
+	for _, r := range reports {
+		if r == "sgml" {
+			return reports
+		}
+	}
+
+	if len(reports) == 1 && reports[0] == "none" {
+		return []string{"sgml"}
+	}
+
+	return append(append([]string{}, reports...), "sgml")
+}
+
+// WritesReport returns true if the given sclite report is generated, either
+// because it was requested, or because it is needed to write alignments.
+func (c *ScliteCfg) WritesReport(report string) bool {
+	for _, r := range c.reports() {
+		if r == report || r == "all" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// alignmentFormats returns the configured alignment formats, or the default set
+// of formats if none were specified.
+func (c *ScliteCfg) alignmentFormats() []string {
+	switch {
+	case len(c.AlignmentFormats) == 0:
+		return []string{
+			AlignmentFormatMarkdown, AlignmentFormatHTML, AlignmentFormatCSV, AlignmentFormatJSON,
+		}
+	case len(c.AlignmentFormats) == 1 && c.AlignmentFormats[0] == AlignmentFormatNone:
+		return nil
+	default:
+		return c.AlignmentFormats
+	}
+}
+
+// utteranceFormats returns the configured per-utterance metrics formats, or
+// csv if none were specified.
+func (c *ScliteCfg) utteranceFormats() []string {
+	switch {
+	case len(c.UtteranceFormats) == 0:
+		return []string{UtteranceFormatCSV}
+	case len(c.UtteranceFormats) == 1 && c.UtteranceFormats[0] == UtteranceFormatNone:
+		return nil
+	default:
+		return c.UtteranceFormats
+	}
+}
+
+// WritesAlignmentFormat returns true if alignments are written in the given
+// format.
+func (c *ScliteCfg) WritesAlignmentFormat(format string) bool {
+	for _, f := range c.alignmentFormats() {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// genOutputsFromSgml writes the alignments in each sgml file in the output
+// directory in the configured formats, next to the sgml file, and the
+// per-utterance metrics of all of them.
+func genOutputsFromSgml(outDir string, cfg ScliteCfg) error {
+	alignmentFormats, utteranceFormats := cfg.alignmentFormats(), cfg.utteranceFormats()
+	if len(alignmentFormats) == 0 && len(utteranceFormats) == 0 {
+		return nil
+	}
+
 	sgmlFiles, err := filepath.Glob(path.Join(outDir, "*.sgml"))
 	if err != nil {
 		logrus.WithFields(log.Fields{
@@ -150,8 +422,7 @@ func genAlignmentFileFromSgml(outDir string) error {
 		}).Error("no sgml files were produced, cannot generate alignment file")
 	}
 
-	// Fixed for now.
-	formats := []TableFormat{TableFormatMarkdown, TableFormatHTML, TableFormatCSV}
+	hyps := make([]*AlignedHypothesis, 0, len(sgmlFiles))
 
 	for _, sgmlFile := range sgmlFiles {
 		aligned, err := ReadAlignmentSgml(sgmlFile)
@@ -159,36 +430,50 @@ func genAlignmentFileFromSgml(outDir string) error {
 			return err
 		}
 
-		for _, format := range formats {
-			var ext string
-			switch {
-			case format == TableFormatMarkdown:
-				ext = ".pra.md"
-			case format == TableFormatHTML:
-				ext = ".pra.html"
-			case format == TableFormatCSV:
-				ext = ".pra.csv"
-			default:
-				ext = ".pra"
+		if err := WriteAlignments(strings.TrimSuffix(sgmlFile, ".sgml"), aligned, alignmentFormats); err != nil {
+			return err
+		}
+
+		hyps = append(hyps, aligned)
+	}
+
+	for _, format := range utteranceFormats {
+		outFile := path.Join(outDir, UtteranceFile+"."+format)
+		if err := WriteUtterances(outFile, hyps, cfg.CER, format); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteAlignments writes the given alignments in each of the given formats, to
+// files named <outPrefix>.pra.<format>.
+func WriteAlignments(outPrefix string, aligned *AlignedHypothesis, formats []string) error {
+	for _, format := range formats {
+		outFile := outPrefix + ".pra." + format
+
+		if format == AlignmentFormatJSON {
+			// The alignments as JSON can easily be parsed back later for further
+			// processing if required.
+			jsonData, err := json.MarshalIndent(aligned, "", " ")
+			if err != nil {
+				return err
 			}
 
-			outFile := strings.ReplaceAll(sgmlFile, ".sgml", ext)
-			if err := WriteAlignment(outFile, aligned, format); err != nil {
+			if err := os.WriteFile(outFile, jsonData, filePerm); err != nil {
 				return err
 			}
-		}
 
-		// Also dump the alignments as JSON to easily parse back later for further
-		// processing if required.
-		jsonData, err := json.MarshalIndent(aligned, "", " ")
-		if err != nil {
-			return err
+			continue
 		}
 
-		ext := ".pra.json"
-		outFile := strings.ReplaceAll(sgmlFile, ".sgml", ext)
+		tableFormat, ok := alignmentTableFormats[format]
+		if !ok {
+			return fmt.Errorf("unsupported alignment format %q", format)
+		}
 
-		if err := os.WriteFile(outFile, jsonData, filePerm); err != nil {
+		if err := WriteAlignment(outFile, aligned, tableFormat); err != nil {
 			return err
 		}
 	}
@@ -17,12 +17,19 @@ package sctk
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
 )
 
 //nolint: funlen // table tests can be long.
@@ -34,6 +41,7 @@ func TestRunSclite(t *testing.T) {
 		ref     string
 		hyp     []Hypothesis
 		cfg     ScliteCfg
+		wantDir string
 		wantErr bool
 	}{
 		{
@@ -64,6 +72,62 @@ func TestRunSclite(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "good1_wer_sharded",
+			ref:  "testdata/sclite/good1_ref.trn",
+			hyp: []Hypothesis{
+				{SystemName: "good1_hyp1", FilePath: "testdata/sclite/good1_hyp1.trn"},
+				{SystemName: "good1_hyp2", FilePath: "testdata/sclite/good1_hyp2.trn"},
+			},
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				CER:       false,
+				Jobs:      3,
+			},
+			wantErr: false,
+		},
+		{
+			name: "good1_cer_sharded",
+			ref:  "testdata/sclite/good1_ref.trn",
+			hyp: []Hypothesis{
+				{SystemName: "good1_hyp1", FilePath: "testdata/sclite/good1_hyp1.trn"},
+				{SystemName: "good1_hyp2", FilePath: "testdata/sclite/good1_hyp2.trn"},
+			},
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				CER:       true,
+				Jobs:      4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "good2_stm_ctm",
+			ref:  "testdata/sclite/good2_ref.stm",
+			hyp: []Hypothesis{
+				{SystemName: "good2_hyp1", FilePath: "testdata/sclite/good2_hyp1.ctm", Format: FormatCtm},
+			},
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				RefFormat: FormatStm,
+			},
+			wantDir: "stm",
+			wantErr: false,
+		},
+		{
+			name: "mismatched_formats",
+			ref:  "testdata/sclite/good1_ref.trn",
+			hyp: []Hypothesis{
+				{SystemName: "good2_hyp1", FilePath: "testdata/sclite/good2_hyp1.ctm", Format: FormatCtm},
+			},
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+			},
+			wantErr: true,
+		},
 		{
 			name: "bad_config1",
 			cfg: ScliteCfg{
@@ -82,6 +146,77 @@ func TestRunSclite(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "bad_config3",
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				Jobs:      -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_config4",
+			cfg: ScliteCfg{
+				LineWidth:        120,
+				Encoding:         "utf-8",
+				AlignmentFormats: []string{"pdf"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_config5",
+			cfg: ScliteCfg{
+				LineWidth:        120,
+				Encoding:         "utf-8",
+				AlignmentFormats: []string{AlignmentFormatNone, AlignmentFormatJSON},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_config6",
+			cfg: ScliteCfg{
+				LineWidth:        120,
+				Encoding:         "utf-8",
+				UtteranceFormats: []string{"parquet"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_config7",
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				RefFormat: FormatStm,
+				CER:       true,
+				Overlap:   true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad_config8",
+			cfg: ScliteCfg{
+				LineWidth:    120,
+				Encoding:     "utf-8",
+				RefFormat:    FormatStm,
+				Overlap:      true,
+				OverlapLimit: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "overlap_trn_ref",
+			ref:  "testdata/sclite/good1_ref.trn",
+			hyp: []Hypothesis{
+				{SystemName: "good1_hyp1", FilePath: "testdata/sclite/good1_hyp1.trn"},
+			},
+			cfg: ScliteCfg{
+				LineWidth: 120,
+				Encoding:  "utf-8",
+				Overlap:   true,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -90,35 +225,37 @@ func TestRunSclite(t *testing.T) {
 		t.Run(tc.name, func(subT *testing.T) {
 			subT.Parallel()
 
-			gotErr := tc.cfg.Validate()
-
-			checkError(subT, tc.wantErr, gotErr)
-			if gotErr != nil && tc.wantErr {
-				return
-			}
-
 			ctx := context.Background()
 			outDir := subT.TempDir()
 
-			gotErr = RunSclite(ctx, tc.cfg, outDir, tc.ref, tc.hyp)
+			gotErr := tc.cfg.Validate()
+			if gotErr == nil {
+				gotErr = RunSclite(ctx, tc.cfg, outDir, tc.ref, tc.hyp)
+			}
 
 			checkError(subT, tc.wantErr, gotErr)
-			if gotErr != nil && tc.wantErr {
+			if gotErr != nil {
 				return
 			}
 
 			for _, hyp := range tc.hyp {
+				hypFile := path.Base(hyp.FilePath)
 				filesToCompare := []string{
-					hyp.SystemName + ".trn.sys",
-					hyp.SystemName + ".trn.sgml",
-					hyp.SystemName + ".trn.dtl",
+					hypFile + ".sys",
+					hypFile + ".sgml",
+					hypFile + ".dtl",
 				}
 
-				for _, name := range filesToCompare {
-					wantPath := path.Join("testdata", "sclite", "wer", name)
+				wantDir := tc.wantDir
+				if wantDir == "" {
+					wantDir = "wer"
 					if tc.cfg.CER {
-						wantPath = path.Join("testdata", "sclite", "cer", name)
+						wantDir = "cer"
 					}
+				}
+
+				for _, name := range filesToCompare {
+					wantPath := path.Join("testdata", "sclite", wantDir, name)
 
 					gotPath := path.Join(outDir, name)
 
@@ -129,6 +266,282 @@ func TestRunSclite(t *testing.T) {
 	}
 }
 
+func TestRunScliteOutputs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		cfg       ScliteCfg
+		wantFiles []string
+	}{
+		{
+			name:      "default",
+			cfg:       ScliteCfg{LineWidth: 120, Encoding: "utf-8"},
+			wantFiles: []string{"dtl", "pra.csv", "pra.html", "pra.json", "pra.md", "raw", "sgml", "sys", "utterances.csv"},
+		},
+		{
+			name: "selected",
+			cfg: ScliteCfg{
+				LineWidth: 120, Encoding: "utf-8",
+				Reports:          []string{"sum"},
+				AlignmentFormats: []string{AlignmentFormatTxt, AlignmentFormatJSON},
+				UtteranceFormats: []string{UtteranceFormatTSV},
+			},
+			wantFiles: []string{"pra.json", "pra.txt", "sgml", "sys", "utterances.tsv"},
+		},
+		{
+			name: "none",
+			cfg: ScliteCfg{
+				LineWidth: 120, Encoding: "utf-8",
+				Reports:          []string{"none"},
+				AlignmentFormats: []string{AlignmentFormatNone},
+				UtteranceFormats: []string{UtteranceFormatNone},
+			},
+			wantFiles: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			hyp := []Hypothesis{{SystemName: "good1_hyp1", FilePath: "testdata/sclite/good1_hyp1.trn"}}
+
+			if err := RunSclite(context.Background(), tc.cfg, outDir, "testdata/sclite/good1_ref.trn", hyp); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			gotFiles := make([]string, 0)
+
+			entries, err := os.ReadDir(outDir)
+			if err != nil {
+				subT.Fatalf("failed to list output directory: %v", err)
+			}
+
+			for _, e := range entries {
+				gotFiles = append(gotFiles, strings.TrimPrefix(e.Name(), "good1_hyp1.trn."))
+			}
+
+			if diff := cmp.Diff(tc.wantFiles, gotFiles); diff != "" {
+				subT.Errorf("unexpected output files, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRunScliteWordWeights(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		jobs int
+	}{
+		{name: "single", jobs: 1},
+		{name: "sharded", jobs: 2},
+	}
+
+	// Words not listed in the word weight list have a weight of 0.
+	wantWords := []AlignedWord{
+		{Label: "S", Ref: "তার", Hyp: "তাঁর", RefWeight: floatPtr(2), HypWeight: floatPtr(0)},
+		{Label: "C", Ref: "পিতার", Hyp: "পিতার", RefWeight: floatPtr(3), HypWeight: floatPtr(3)},
+		{Label: "C", Ref: "নাম", Hyp: "নাম", RefWeight: floatPtr(0), HypWeight: floatPtr(0)},
+		{Label: "C", Ref: "কালীপ্রসন্ন", Hyp: "কালীপ্রসন্ন", RefWeight: floatPtr(0), HypWeight: floatPtr(0)},
+		{Label: "C", Ref: "ভট্টাচার্য।", Hyp: "ভট্টাচার্য।", RefWeight: floatPtr(0), HypWeight: floatPtr(0)},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outDir := subT.TempDir()
+			hyp := []Hypothesis{{SystemName: "good1_hyp1", FilePath: "testdata/sclite/good1_hyp1.trn"}}
+			cfg := ScliteCfg{
+				LineWidth: 120, Encoding: "utf-8", Jobs: tc.jobs,
+				Reports:     []string{"sum", "wws"},
+				WordWeights: "testdata/sclite/good1.wwl",
+			}
+
+			if err := cfg.Validate(); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			if err := RunSclite(context.Background(), cfg, outDir, "testdata/sclite/good1_ref.trn", hyp); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			// The wws report is only written if sclite could read the weighted
+			// alignments back when run on shards.
+			if _, err := os.Stat(path.Join(outDir, "good1_hyp1.trn.wws")); err != nil {
+				subT.Errorf("expected wws report to be written: %v", err)
+			}
+
+			aligned, err := ReadAlignmentJSON(path.Join(outDir, "good1_hyp1.trn.pra.json"))
+			if err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got := aligned.Speakers["common"]["(common_voice_bn_30620258.mp3)"]
+			if got == nil {
+				subT.Fatalf("expected sentence not found in alignments")
+			}
+
+			if diff := cmp.Diff(wantWords, got.Words); diff != "" {
+				subT.Errorf("unexpected weighted alignment, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestRunScliteOverlap checks the alignments made by asclite of a ctm
+// hypothesis against an stm reference where two speakers talk over each other.
+// The hypothesis words of both speakers are interleaved in time, which only an
+// overlap aware alignment attributes to the right speaker.
+func TestRunScliteOverlap(t *testing.T) {
+	t.Parallel()
+
+	if _, err := embedded.Asclite(); err != nil {
+		t.Skipf("asclite is not available: %v", err)
+	}
+
+	outDir := t.TempDir()
+	cfg := ScliteCfg{LineWidth: 120, Encoding: "utf-8", RefFormat: FormatStm, Overlap: true}
+
+	runScliteOverlap(t, cfg, outDir)
+}
+
+// TestRunScliteOverlapGolden runs the overlap scoring mode with a stand-in for
+// asclite that writes the alignments asclite outputs for the overlap test data,
+// so that the rest of the mode is tested even when asclite is not embedded.
+// Not run in parallel since it changes the directory SCTK tools are found in.
+func TestRunScliteOverlapGolden(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the asclite stand-in is a shell script")
+	}
+
+	scliteBin, err := embedded.Sclite()
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	golden, err := filepath.Abs("testdata/sclite/overlap/overlap_hyp1.ctm.sgml")
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	binDir := t.TempDir()
+	argsFile := path.Join(binDir, "asclite.args")
+
+	// The stand-in records its arguments, and copies the golden alignments to
+	// <out dir>/<hyp base name>.sgml like asclite.
+	script := fmt.Sprintf(`#!/bin/sh
+echo "$@" > %q
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -h) hyp="$2" ;;
+    -O) out="$2" ;;
+  esac
+  shift
+done
+cp %q "$out/$(basename "$hyp").sgml"
+`, argsFile, golden)
+
+	if err := os.WriteFile(path.Join(binDir, "asclite"), []byte(script), 0755); err != nil { //nolint: gomnd // file permissions.
+		t.Fatalf("failed to write asclite stand-in: %v", err)
+	}
+
+	if err := os.Symlink(scliteBin, path.Join(binDir, "sclite")); err != nil {
+		t.Fatalf("failed to link sclite: %v", err)
+	}
+
+	embedded.SetBinDir(binDir)
+	defer embedded.SetBinDir("")
+
+	outDir := t.TempDir()
+	cfg := ScliteCfg{LineWidth: 120, Encoding: "utf-8", RefFormat: FormatStm, Overlap: true, OverlapLimit: 2}
+
+	runScliteOverlap(t, cfg, outDir)
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("asclite stand-in was not run: %v", err)
+	}
+
+	wantArgs := fmt.Sprintf(
+		"-r testdata/sclite/overlap_ref.stm stm -h testdata/sclite/overlap_hyp1.ctm ctm overlap_hyp1 "+
+			"-O %s -o sgml -s -overlap-limit 2\n", outDir,
+	)
+	if diff := cmp.Diff(wantArgs, string(args)); diff != "" {
+		t.Errorf("unexpected asclite arguments, (-want, +got):\n%s", diff)
+	}
+}
+
+// runScliteOverlap scores the overlap test data with the given config, and
+// checks the reports and alignments written to outDir.
+func runScliteOverlap(t *testing.T, cfg ScliteCfg, outDir string) {
+	t.Helper()
+
+	hyp := []Hypothesis{
+		{SystemName: "overlap_hyp1", FilePath: "testdata/sclite/overlap_hyp1.ctm", Format: FormatCtm},
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	if err := RunSclite(context.Background(), cfg, outDir, "testdata/sclite/overlap_ref.stm", hyp); err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	for _, ext := range []string{"sgml", "sys", "pra.json"} {
+		if _, err := os.Stat(path.Join(outDir, "overlap_hyp1.ctm."+ext)); err != nil {
+			t.Errorf("expected %s output to be written: %v", ext, err)
+		}
+	}
+
+	aligned, err := ReadAlignmentJSON(path.Join(outDir, "overlap_hyp1.ctm.pra.json"))
+	if err != nil {
+		t.Fatalf("got unexpected error, want=nil, got=%v", err)
+	}
+
+	gotWords := make(map[string][]string)
+	var got SentenceStats
+
+	for _, sentences := range aligned.Speakers {
+		for _, s := range sentences {
+			st := s.Stats()
+			got.RefWords += st.RefWords
+			got.HypWords += st.HypWords
+			got.Cor += st.Cor
+			got.Sub += st.Sub
+			got.Del += st.Del
+			got.Ins += st.Ins
+
+			for _, w := range s.Words {
+				gotWords[s.SpeakerID] = append(gotWords[s.SpeakerID], w.Hyp)
+			}
+		}
+	}
+
+	// Only the last word of the second speaker is misrecognized.
+	want := SentenceStats{RefWords: 8, HypWords: 8, Cor: 7, Sub: 1}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(SentenceStats{}, "WER", "SentenceError")); diff != "" {
+		t.Errorf("unexpected alignment counts, (-want, +got):\n%s", diff)
+	}
+
+	wantWords := map[string][]string{
+		"spk1": {"hello", "how", "are", "you"},
+		"spk2": {"i", "am", "fine", "thank"},
+	}
+	if diff := cmp.Diff(wantWords, gotWords); diff != "" {
+		t.Errorf("unexpected hypothesis words per speaker, (-want, +got):\n%s", diff)
+	}
+}
+
 // compareFiles compares the contents of the files at the given paths by
 // generating the diff between them. Some normalization steps are applied before
 // doing so, such as converting timestamps to a fixed string.
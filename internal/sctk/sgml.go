@@ -42,6 +42,18 @@ const (
 	attrWordCount     = "word_cnt"   // <PATH word_cnt="10" ...>
 	attrSequence      = "sequence"   // <PATH sequence="0" ...>
 	attrCaseSensitive = "case_sense" // <PATH case_sense="1" ...>
+	attrFile          = "file"       // <PATH file="rec1" ...>
+	attrChannel       = "channel"    // <PATH channel="A" ...>
+	attrLabels        = "labels"     // <PATH labels="<o,f0,male>" ...>
+	attrRefStart      = "r_t1"       // <PATH R_T1="0.000" ...>
+	attrRefEnd        = "r_t2"       // <PATH R_T2="2.000" ...>
+	attrWordAux       = "word_aux"   // <PATH word_aux="h_t1+t2,h_conf" ...>
+	auxRefTimes       = "r_t1+t2"    // Start and end time of reference word.
+	auxHypTimes       = "h_t1+t2"    // Start and end time of hypothesis word.
+	auxRefConf        = "r_conf"     // Confidence score of reference word.
+	auxHypConf        = "h_conf"     // Confidence score of hypothesis word.
+	auxRefWeight      = "r_weight"   // Weight of reference word in weighted alignments.
+	auxHypWeight      = "h_weight"   // Weight of hypothesis word in weighted alignments.
 	wordListDelimiter = ':'          // Delimiter between tuples of (label, ref word, hyp word)
 	wordDelimiter     = ','          // Delimiter in tuples of (label, ref word, hyp word)
 )
@@ -59,12 +71,19 @@ type SpeakerSentences map[string]*AlignedSentence
 // An AlignedSentence contains the aligned words between reference and
 // hypothesis for a given sentence.
 type AlignedSentence struct {
-	SystemName string
-	SpeakerID  string
+	SystemName string        `json:"system_name"`
+	SpeakerID  string        `json:"speaker_id"`
 	SentenceID string        `json:"sentence_id"`
 	Sequence   int           `json:"sequence"`
 	WordCount  int           `json:"word_count"`
 	Words      []AlignedWord `json:"words"`
+
+	// File, Channel, Labels and Times are only available when scoring segment
+	// time marked (stm) references.
+	File    string    `json:"file,omitempty"`
+	Channel string    `json:"channel,omitempty"`
+	Labels  string    `json:"labels,omitempty"`
+	Times   *TimeSpan `json:"times,omitempty"`
 }
 
 // AlignedWord contains the reference word and corresponding word in the
@@ -74,6 +93,24 @@ type AlignedWord struct {
 	Label string `json:"eval_label"`
 	Ref   string `json:"ref"`
 	Hyp   string `json:"hyp"`
+
+	// Times and confidence scores of words are only available when scoring
+	// time marked (ctm) hypotheses.
+	RefTimes *TimeSpan `json:"ref_times,omitempty"`
+	HypTimes *TimeSpan `json:"hyp_times,omitempty"`
+	RefConf  *float64  `json:"ref_conf,omitempty"`
+	HypConf  *float64  `json:"hyp_conf,omitempty"`
+
+	// Weights of words are only available when sclite performs word weight
+	// mediated alignments; see ScliteCfg.WordWeights.
+	RefWeight *float64 `json:"ref_weight,omitempty"`
+	HypWeight *float64 `json:"hyp_weight,omitempty"`
+}
+
+// A TimeSpan marks the start and end time of a word or segment in seconds.
+type TimeSpan struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
 }
 
 func WriteAlignment(outPath string, aligned *AlignedHypothesis, format TableFormat) error {
@@ -178,8 +215,10 @@ func ReadAlignmentSgml(sgmlPath string) (*AlignedHypothesis, error) {
 // generated by sclite, containing aligned reference and hypothesis words.
 func parsePathTag(tokenizer *html.Tokenizer, t html.Token, speakerID string) (*AlignedSentence, error) {
 	var (
-		err  error
-		sent AlignedSentence
+		err      error
+		sent     AlignedSentence
+		wordAux  []string
+		segTimes [2]string
 	)
 
 	sent.SpeakerID = speakerID
@@ -190,6 +229,24 @@ func parsePathTag(tokenizer *html.Tokenizer, t html.Token, speakerID string) (*A
 		case a.Key == attrID:
 			sent.SentenceID = a.Val
 
+		case a.Key == attrFile:
+			sent.File = a.Val
+
+		case a.Key == attrChannel:
+			sent.Channel = a.Val
+
+		case a.Key == attrLabels:
+			sent.Labels = a.Val
+
+		case a.Key == attrRefStart:
+			segTimes[0] = a.Val
+
+		case a.Key == attrRefEnd:
+			segTimes[1] = a.Val
+
+		case a.Key == attrWordAux:
+			wordAux = strings.Split(a.Val, ",")
+
 		case a.Key == attrWordCount:
 			sent.WordCount, err = strconv.Atoi(a.Val)
 			if err != nil {
@@ -218,6 +275,19 @@ func parsePathTag(tokenizer *html.Tokenizer, t html.Token, speakerID string) (*A
 		}
 	}
 
+	if segTimes[0] != "" && segTimes[1] != "" {
+		sent.Times, err = parseTimeSpan(segTimes[0] + "+" + segTimes[1])
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"speaker":  speakerID,
+				"sentence": sent.SentenceID,
+				"error":    err,
+			}).Error("failed to parse segment times")
+
+			return nil, fmt.Errorf("failed to parse sgml file, check logs")
+		}
+	}
+
 	if sent.SentenceID == "" {
 		logrus.WithFields(logrus.Fields{
 			"speaker":    speakerID,
@@ -271,14 +341,14 @@ func parsePathTag(tokenizer *html.Tokenizer, t html.Token, speakerID string) (*A
 	for i, w := range wordList {
 		parts := textutils.FieldsWithQuoted(w, wordDelimiter)
 
-		if len(parts) != 3 {
+		if len(parts) != 3+len(wordAux) {
 			logrus.WithFields(logrus.Fields{
 				"speaker":       speakerID,
 				"sentence":      sent.SentenceID,
 				"aligned_index": i,
 				"aligned_word":  w,
 				"got_parts":     len(parts),
-				"want_parts":    3,
+				"want_parts":    3 + len(wordAux),
 			}).Errorf("unexpected number of fields in aligned word")
 
 			return nil, fmt.Errorf("failed to parse sgml file, check logs")
@@ -290,12 +360,90 @@ func parsePathTag(tokenizer *html.Tokenizer, t html.Token, speakerID string) (*A
 			Hyp:   parts[2],
 		}
 
+		if err := aw.parseAux(wordAux, parts[3:]); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"speaker":       speakerID,
+				"sentence":      sent.SentenceID,
+				"aligned_index": i,
+				"aligned_word":  w,
+				"error":         err,
+			}).Errorf("failed to parse auxiliary fields of aligned word")
+
+			return nil, fmt.Errorf("failed to parse sgml file, check logs")
+		}
+
 		sent.Words = append(sent.Words, aw)
 	}
 
 	return &sent, nil
 }
 
+// parseAux parses the auxiliary fields of an aligned word, such as word times,
+// confidence scores and weights, given their names from the word_aux attribute of the
+// <PATH> tag. Empty fields, such as the hypothesis times of deleted words, and
+// unknown fields are skipped.
+func (w *AlignedWord) parseAux(names, values []string) error {
+	for i, name := range names {
+		val := strings.TrimSpace(values[i])
+		if val == "" {
+			continue
+		}
+
+		var err error
+
+		switch name {
+		case auxRefTimes:
+			w.RefTimes, err = parseTimeSpan(val)
+		case auxHypTimes:
+			w.HypTimes, err = parseTimeSpan(val)
+		case auxRefConf:
+			w.RefConf, err = parseFloat(val)
+		case auxHypConf:
+			w.HypConf, err = parseFloat(val)
+		case auxRefWeight:
+			w.RefWeight, err = parseFloat(val)
+		case auxHypWeight:
+			w.HypWeight, err = parseFloat(val)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseTimeSpan parses start and end times in the form "<start>+<end>".
+func parseTimeSpan(s string) (*TimeSpan, error) {
+	parts := strings.Split(s, "+")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected times in the form <start>+<end>, got %q", s)
+	}
+
+	start, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TimeSpan{Start: start, End: end}, nil
+}
+
+// parseFloat parses the given string as a float and returns a pointer to it.
+func parseFloat(s string) (*float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
 // splitAlignedTuples splits the tuples containing (label, ref word, hyp word)
 // in the sgml file. We don't use strings.Fields or textutils.FieldsWithQuoted
 // here because sgml doesn't quote the entire tuple; this causes issues when the
@@ -132,11 +132,11 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   0,
 							WordCount:  5,
 							Words: []AlignedWord{
-								{"C", "তার", "তার"},
-								{"C", "পিতার", "পিতার"},
-								{"C", "নাম", "নাম"},
-								{"C", "কালীপ্রসন্ন", "কালীপ্রসন্ন"},
-								{"S", "ভট্টাচার্য।", "ভট্টাচার্য"},
+								{Label: "C", Ref: "তার", Hyp: "তার"},
+								{Label: "C", Ref: "পিতার", Hyp: "পিতার"},
+								{Label: "C", Ref: "নাম", Hyp: "নাম"},
+								{Label: "C", Ref: "কালীপ্রসন্ন", Hyp: "কালীপ্রসন্ন"},
+								{Label: "S", Ref: "ভট্টাচার্য।", Hyp: "ভট্টাচার্য"},
 							},
 						},
 						"common_voice_bn_30620259.mp3": &AlignedSentence{
@@ -146,14 +146,14 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   1,
 							WordCount:  8,
 							Words: []AlignedWord{
-								{"C", "ভৌগোলিক", "ভৌগোলিক"},
-								{"C", "অবস্থান", "অবস্থান"},
-								{"C", "অনুযায়ী", "অনুযায়ী"},
-								{"D", "শহরটির", ""},
-								{"D", "পূর্ব", ""},
-								{"D", "দিকে", ""},
-								{"S", "কাশ্মীর", "চাহরটিরপূর্বদিকেকাশ্মির"},
-								{"S", "অবস্থিত।", "অবস্থিত"},
+								{Label: "C", Ref: "ভৌগোলিক", Hyp: "ভৌগোলিক"},
+								{Label: "C", Ref: "অবস্থান", Hyp: "অবস্থান"},
+								{Label: "C", Ref: "অনুযায়ী", Hyp: "অনুযায়ী"},
+								{Label: "D", Ref: "শহরটির", Hyp: ""},
+								{Label: "D", Ref: "পূর্ব", Hyp: ""},
+								{Label: "D", Ref: "দিকে", Hyp: ""},
+								{Label: "S", Ref: "কাশ্মীর", Hyp: "চাহরটিরপূর্বদিকেকাশ্মির"},
+								{Label: "S", Ref: "অবস্থিত।", Hyp: "অবস্থিত"},
 							},
 						},
 						"common_voice_bn_30620260.mp3": &AlignedSentence{
@@ -163,11 +163,11 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   2,
 							WordCount:  5,
 							Words: []AlignedWord{
-								{"C", "এটি", "এটি"},
-								{"S", "বিশ্বব্যাপি", "বিশ্বব্যাপী"},
-								{"I", "", "একই"},
-								{"C", "হয়ে", "হয়ে"},
-								{"S", "থাকে।", "থাকে"},
+								{Label: "C", Ref: "এটি", Hyp: "এটি"},
+								{Label: "S", Ref: "বিশ্বব্যাপি", Hyp: "বিশ্বব্যাপী"},
+								{Label: "I", Ref: "", Hyp: "একই"},
+								{Label: "C", Ref: "হয়ে", Hyp: "হয়ে"},
+								{Label: "S", Ref: "থাকে।", Hyp: "থাকে"},
 							},
 						},
 					},
@@ -189,40 +189,40 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   0,
 							WordCount:  34,
 							Words: []AlignedWord{
-								{"C", "ত", "ত"},
-								{"C", "া", "া"},
-								{"I", "", "ঁ"},
-								{"C", "র", "র"},
-								{"C", "প", "প"},
-								{"C", "ি", "ি"},
-								{"C", "ত", "ত"},
-								{"C", "া", "া"},
-								{"C", "র", "র"},
-								{"C", "ন", "ন"},
-								{"C", "া", "া"},
-								{"C", "ম", "ম"},
-								{"C", "ক", "ক"},
-								{"C", "া", "া"},
-								{"C", "ল", "ল"},
-								{"C", "ী", "ী"},
-								{"C", "প", "প"},
-								{"C", "্", "্"},
-								{"C", "র", "র"},
-								{"C", "স", "স"},
-								{"C", "ন", "ন"},
-								{"C", "্", "্"},
-								{"C", "ন", "ন"},
-								{"C", "ভ", "ভ"},
-								{"C", "ট", "ট"},
-								{"C", "্", "্"},
-								{"C", "ট", "ট"},
-								{"C", "া", "া"},
-								{"C", "চ", "চ"},
-								{"C", "া", "া"},
-								{"C", "র", "র"},
-								{"C", "্", "্"},
-								{"C", "য", "য"},
-								{"C", "।", "।"},
+								{Label: "C", Ref: "ত", Hyp: "ত"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "I", Ref: "", Hyp: "ঁ"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ত", Hyp: "ত"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "ম", Hyp: "ম"},
+								{Label: "C", Ref: "ক", Hyp: "ক"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "ল", Hyp: "ল"},
+								{Label: "C", Ref: "ী", Hyp: "ী"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "স", Hyp: "স"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "ভ", Hyp: "ভ"},
+								{Label: "C", Ref: "ট", Hyp: "ট"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "ট", Hyp: "ট"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "চ", Hyp: "চ"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "য", Hyp: "য"},
+								{Label: "C", Ref: "।", Hyp: "।"},
 							},
 						},
 						"common_voice_bn_30620259.mp3": &AlignedSentence{
@@ -232,58 +232,58 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   1,
 							WordCount:  52,
 							Words: []AlignedWord{
-								{"C", "ভ", "ভ"},
-								{"C", "ৌ", "ৌ"},
-								{"C", "গ", "গ"},
-								{"C", "ো", "ো"},
-								{"C", "ল", "ল"},
-								{"C", "ি", "ি"},
-								{"C", "ক", "ক"},
-								{"C", "অ", "অ"},
-								{"C", "ব", "ব"},
-								{"C", "স", "স"},
-								{"C", "্", "্"},
-								{"C", "থ", "থ"},
-								{"C", "া", "া"},
-								{"C", "ন", "ন"},
-								{"C", "অ", "অ"},
-								{"C", "ন", "ন"},
-								{"C", "ু", "ু"},
-								{"C", "য", "য"},
-								{"C", "া", "া"},
-								{"C", "য", "য"},
-								{"C", "়", "়"},
-								{"C", "ী", "ী"},
-								{"C", "শ", "শ"},
-								{"C", "হ", "হ"},
-								{"C", "র", "র"},
-								{"C", "ট", "ট"},
-								{"C", "ি", "ি"},
-								{"C", "র", "র"},
-								{"C", "প", "প"},
-								{"C", "ূ", "ূ"},
-								{"C", "র", "র"},
-								{"C", "্", "্"},
-								{"C", "ব", "ব"},
-								{"C", "দ", "দ"},
-								{"C", "ি", "ি"},
-								{"C", "ক", "ক"},
-								{"C", "ে", "ে"},
-								{"C", "ক", "ক"},
-								{"C", "া", "া"},
-								{"C", "শ", "শ"},
-								{"C", "্", "্"},
-								{"C", "ম", "ম"},
-								{"C", "ী", "ী"},
-								{"C", "র", "র"},
-								{"C", "অ", "অ"},
-								{"C", "ব", "ব"},
-								{"C", "স", "স"},
-								{"C", "্", "্"},
-								{"C", "থ", "থ"},
-								{"C", "ি", "ি"},
-								{"C", "ত", "ত"},
-								{"C", "।", "।"},
+								{Label: "C", Ref: "ভ", Hyp: "ভ"},
+								{Label: "C", Ref: "ৌ", Hyp: "ৌ"},
+								{Label: "C", Ref: "গ", Hyp: "গ"},
+								{Label: "C", Ref: "ো", Hyp: "ো"},
+								{Label: "C", Ref: "ল", Hyp: "ল"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ক", Hyp: "ক"},
+								{Label: "C", Ref: "অ", Hyp: "অ"},
+								{Label: "C", Ref: "ব", Hyp: "ব"},
+								{Label: "C", Ref: "স", Hyp: "স"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "থ", Hyp: "থ"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "অ", Hyp: "অ"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "ু", Hyp: "ু"},
+								{Label: "C", Ref: "য", Hyp: "য"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "য", Hyp: "য"},
+								{Label: "C", Ref: "়", Hyp: "়"},
+								{Label: "C", Ref: "ী", Hyp: "ী"},
+								{Label: "C", Ref: "শ", Hyp: "শ"},
+								{Label: "C", Ref: "হ", Hyp: "হ"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "ট", Hyp: "ট"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "C", Ref: "ূ", Hyp: "ূ"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "ব", Hyp: "ব"},
+								{Label: "C", Ref: "দ", Hyp: "দ"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ক", Hyp: "ক"},
+								{Label: "C", Ref: "ে", Hyp: "ে"},
+								{Label: "C", Ref: "ক", Hyp: "ক"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "শ", Hyp: "শ"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "ম", Hyp: "ম"},
+								{Label: "C", Ref: "ী", Hyp: "ী"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "অ", Hyp: "অ"},
+								{Label: "C", Ref: "ব", Hyp: "ব"},
+								{Label: "C", Ref: "স", Hyp: "স"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "থ", Hyp: "থ"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ত", Hyp: "ত"},
+								{Label: "C", Ref: "।", Hyp: "।"},
 							},
 						},
 						"common_voice_bn_30620260.mp3": &AlignedSentence{
@@ -293,61 +293,125 @@ func TestReadAlignmentSgml(t *testing.T) {
 							Sequence:   2,
 							WordCount:  55,
 							Words: []AlignedWord{
-								{"D", "ব", ""},
-								{"S", "ই", "প"},
-								{"S", "ট", "প"},
-								{"C", "ি", "ি"},
-								{"D", "র", ""},
-								{"C", "ম", "ম"},
-								{"D", "ূ", ""},
-								{"C", "ল", "ল"},
-								{"I", "", "উ"},
-								{"I", "", "প"},
-								{"I", "", "দ"},
-								{"I", "", "্"},
-								{"I", "", "ব"},
-								{"I", "", "ী"},
-								{"I", "", "প"},
-								{"C", "উ", "উ"},
-								{"C", "প", "প"},
-								{"C", "জ", "জ"},
-								{"C", "ী", "ী"},
-								{"C", "ব", "ব"},
-								{"C", "্", "্"},
-								{"C", "য", "য"},
-								{"C", "ম", "ম"},
-								{"C", "ধ", "ধ"},
-								{"C", "্", "্"},
-								{"C", "য", "য"},
-								{"C", "ব", "ব"},
-								{"C", "ি", "ি"},
-								{"C", "ত", "ত"},
-								{"C", "্", "্"},
-								{"C", "ত", "ত"},
-								{"C", "স", "স"},
-								{"C", "ম", "ম"},
-								{"C", "া", "া"},
-								{"C", "জ", "জ"},
-								{"C", "ে", "ে"},
-								{"C", "প", "প"},
-								{"C", "ি", "ি"},
-								{"C", "ছ", "ছ"},
-								{"D", "ি", ""},
-								{"D", "য", ""},
-								{"D", "়", ""},
-								{"C", "ে", "ে"},
-								{"C", "প", "প"},
-								{"D", "ড", ""},
-								{"S", "়", "র"},
-								{"C", "া", "া"},
-								{"I", "", "ণ"},
-								{"C", "ন", "ন"},
-								{"C", "া", "া"},
-								{"C", "র", "র"},
-								{"C", "ী", "ী"},
-								{"C", "র", "র"},
-								{"C", "া", "া"},
-								{"C", "।", "।"},
+								{Label: "D", Ref: "ব", Hyp: ""},
+								{Label: "S", Ref: "ই", Hyp: "প"},
+								{Label: "S", Ref: "ট", Hyp: "প"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "D", Ref: "র", Hyp: ""},
+								{Label: "C", Ref: "ম", Hyp: "ম"},
+								{Label: "D", Ref: "ূ", Hyp: ""},
+								{Label: "C", Ref: "ল", Hyp: "ল"},
+								{Label: "I", Ref: "", Hyp: "উ"},
+								{Label: "I", Ref: "", Hyp: "প"},
+								{Label: "I", Ref: "", Hyp: "দ"},
+								{Label: "I", Ref: "", Hyp: "্"},
+								{Label: "I", Ref: "", Hyp: "ব"},
+								{Label: "I", Ref: "", Hyp: "ী"},
+								{Label: "I", Ref: "", Hyp: "প"},
+								{Label: "C", Ref: "উ", Hyp: "উ"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "C", Ref: "জ", Hyp: "জ"},
+								{Label: "C", Ref: "ী", Hyp: "ী"},
+								{Label: "C", Ref: "ব", Hyp: "ব"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "য", Hyp: "য"},
+								{Label: "C", Ref: "ম", Hyp: "ম"},
+								{Label: "C", Ref: "ধ", Hyp: "ধ"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "য", Hyp: "য"},
+								{Label: "C", Ref: "ব", Hyp: "ব"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ত", Hyp: "ত"},
+								{Label: "C", Ref: "্", Hyp: "্"},
+								{Label: "C", Ref: "ত", Hyp: "ত"},
+								{Label: "C", Ref: "স", Hyp: "স"},
+								{Label: "C", Ref: "ম", Hyp: "ম"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "জ", Hyp: "জ"},
+								{Label: "C", Ref: "ে", Hyp: "ে"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "C", Ref: "ি", Hyp: "ি"},
+								{Label: "C", Ref: "ছ", Hyp: "ছ"},
+								{Label: "D", Ref: "ি", Hyp: ""},
+								{Label: "D", Ref: "য", Hyp: ""},
+								{Label: "D", Ref: "়", Hyp: ""},
+								{Label: "C", Ref: "ে", Hyp: "ে"},
+								{Label: "C", Ref: "প", Hyp: "প"},
+								{Label: "D", Ref: "ড", Hyp: ""},
+								{Label: "S", Ref: "়", Hyp: "র"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "I", Ref: "", Hyp: "ণ"},
+								{Label: "C", Ref: "ন", Hyp: "ন"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "ী", Hyp: "ী"},
+								{Label: "C", Ref: "র", Hyp: "র"},
+								{Label: "C", Ref: "া", Hyp: "া"},
+								{Label: "C", Ref: "।", Hyp: "।"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			sgmlPath: "testdata/sgml/good3.stm.ctm.sgml",
+			wantErr:  false,
+			name:     "good3_stm_ctm",
+			want: &AlignedHypothesis{
+				SystemName: "good2_hyp1",
+				Speakers: map[string]SpeakerSentences{
+					"spk1": {
+						"(spk1-000)": &AlignedSentence{
+							SystemName: "good2_hyp1",
+							SpeakerID:  "spk1",
+							SentenceID: "(spk1-000)",
+							Sequence:   0,
+							WordCount:  5,
+							File:       "rec1",
+							Channel:    "A",
+							Labels:     "<o,f0,male>",
+							Times:      &TimeSpan{Start: 0, End: 2},
+							Words: []AlignedWord{
+								{Label: "C", Ref: "hello", Hyp: "hello", HypTimes: &TimeSpan{0.1, 0.4}, HypConf: floatPtr(0.9)},
+								{Label: "S", Ref: "world", Hyp: "word", HypTimes: &TimeSpan{0.5, 0.8}, HypConf: floatPtr(0.4)},
+								{Label: "C", Ref: "how", Hyp: "how", HypTimes: &TimeSpan{1.0, 1.2}, HypConf: floatPtr(0.8)},
+								{Label: "C", Ref: "are", Hyp: "are", HypTimes: &TimeSpan{1.3, 1.6}, HypConf: floatPtr(0.9)},
+								{Label: "D", Ref: "you", Hyp: ""},
+							},
+						},
+						"(spk1-001)": &AlignedSentence{
+							SystemName: "good2_hyp1",
+							SpeakerID:  "spk1",
+							SentenceID: "(spk1-001)",
+							Sequence:   2,
+							WordCount:  3,
+							File:       "rec2",
+							Channel:    "A",
+							Labels:     "<o,f0,male>",
+							Times:      &TimeSpan{Start: 0, End: 3},
+							Words: []AlignedWord{
+								{Label: "C", Ref: "good", Hyp: "good", HypTimes: &TimeSpan{0.3, 0.8}, HypConf: floatPtr(0.9)},
+								{Label: "C", Ref: "morning", Hyp: "morning", HypTimes: &TimeSpan{1.0, 1.5}, HypConf: floatPtr(0.9)},
+								{Label: "I", Ref: "", Hyp: "everyone", HypTimes: &TimeSpan{1.6, 2.1}, HypConf: floatPtr(0.3)},
+							},
+						},
+					},
+					"spk2": {
+						"(spk2-000)": &AlignedSentence{
+							SystemName: "good2_hyp1",
+							SpeakerID:  "spk2",
+							SentenceID: "(spk2-000)",
+							Sequence:   1,
+							WordCount:  3,
+							File:       "rec1",
+							Channel:    "A",
+							Labels:     "<o,f0,female>",
+							Times:      &TimeSpan{Start: 2, End: 4},
+							Words: []AlignedWord{
+								{Label: "C", Ref: "i", Hyp: "i", HypTimes: &TimeSpan{2.1, 2.3}, HypConf: floatPtr(0.9)},
+								{Label: "C", Ref: "am", Hyp: "am", HypTimes: &TimeSpan{2.4, 2.6}, HypConf: floatPtr(0.9)},
+								{Label: "C", Ref: "fine", Hyp: "fine", HypTimes: &TimeSpan{2.7, 3.2}, HypConf: floatPtr(0.9)},
 							},
 						},
 					},
@@ -496,3 +560,7 @@ func compareAlignedHypotheses(t *testing.T, want, got *AlignedHypothesis) {
 		}
 	}
 }
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
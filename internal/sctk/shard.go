@@ -0,0 +1,506 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+// A shardTask is a single sclite run over a contiguous shard of utterances
+// from one hypothesis file.
+type shardTask struct {
+	dir     string
+	refFile string
+	hyp     Hypothesis
+}
+
+// sgmlFile returns the path to the sgml file produced by sclite for the shard.
+func (t *shardTask) sgmlFile() string {
+	return path.Join(t.dir, path.Base(t.hyp.FilePath)+".sgml")
+}
+
+// runScliteSharded splits each hypothesis file into cfg.Jobs shards of
+// utterances, aligns every shard with a separate sclite process, and merges the
+// resulting sgml files into one sgml file per hypothesis. The merged sgml file
+// is then piped back into sclite to generate the configured reports. The
+// generated files are identical to those produced by a single sclite process
+// over all utterances.
+func runScliteSharded(
+	ctx context.Context, cfg ScliteCfg, outDir, refFile string, hypFiles []Hypothesis,
+) error {
+	scliteBin, err := embedded.Sclite()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outDir, filePerm); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	shardDir, err := os.MkdirTemp(outDir, ".shards-")
+	if err != nil {
+		return fmt.Errorf("failed to create shard directory: %w", err)
+	}
+
+	defer os.RemoveAll(shardDir)
+
+	refLines, err := readTrnLines(refFile)
+	if err != nil {
+		return fmt.Errorf("failed to read reference file: %w", err)
+	}
+
+	refByID := make(map[string]string, len(refLines))
+	for _, l := range refLines {
+		refByID[l.id] = l.text
+	}
+
+	shards := make([][]shardTask, len(hypFiles))
+	tasks := make([]shardTask, 0, len(hypFiles)*cfg.Jobs)
+
+	for i, hyp := range hypFiles {
+		shards[i], err = writeShards(
+			path.Join(shardDir, strconv.Itoa(i)), hyp, refByID, cfg.Jobs,
+		)
+		if err != nil {
+			return err
+		}
+
+		tasks = append(tasks, shards[i]...)
+	}
+
+	if err := runShardTasks(ctx, cfg, scliteBin, tasks); err != nil {
+		return err
+	}
+
+	// Sentence sequence numbers in the sgml files follow on from one hypothesis
+	// to the next in a single sclite run.
+	seqOffset := 0
+
+	for i, hyp := range hypFiles {
+		sgmlFile := path.Join(outDir, path.Base(hyp.FilePath)+".sgml")
+
+		n, err := mergeShardSgml(shards[i], sgmlFile, refFile, hyp.FilePath, seqOffset)
+		if err != nil {
+			return err
+		}
+
+		seqOffset += n
+
+		if err := runSclitePiped(ctx, cfg, scliteBin, outDir, sgmlFile); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeShards splits the utterances in the given hypothesis file into at most n
+// contiguous shards, preserving their order. Each shard is written to its own
+// sub-directory under dir, along with the matching reference utterances.
+func writeShards(
+	dir string, hyp Hypothesis, refByID map[string]string, n int,
+) ([]shardTask, error) {
+	hypLines, err := readTrnLines(hyp.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hypothesis file: %w", err)
+	}
+
+	if n > len(hypLines) {
+		n = len(hypLines)
+	}
+
+	tasks := make([]shardTask, 0, n)
+
+	for k := 0; k < n; k++ {
+		lines := hypLines[k*len(hypLines)/n : (k+1)*len(hypLines)/n]
+
+		task := shardTask{
+			dir:     path.Join(dir, strconv.Itoa(k)),
+			refFile: path.Join(dir, strconv.Itoa(k), "ref.trn"),
+			hyp: Hypothesis{
+				SystemName: hyp.SystemName,
+				FilePath:   path.Join(dir, strconv.Itoa(k), path.Base(hyp.FilePath)),
+			},
+		}
+
+		if err := os.MkdirAll(task.dir, filePerm); err != nil {
+			return nil, fmt.Errorf("failed to create shard directory: %w", err)
+		}
+
+		refShard := make([]trnLine, 0, len(lines))
+		for _, l := range lines {
+			// Hypothesis utterances without a reference are left to sclite to
+			// complain about, as it would in a single run.
+			if text, ok := refByID[l.id]; ok {
+				refShard = append(refShard, trnLine{id: l.id, text: text})
+			}
+		}
+
+		if err := writeTrnLines(task.refFile, refShard); err != nil {
+			return nil, err
+		}
+
+		if err := writeTrnLines(task.hyp.FilePath, lines); err != nil {
+			return nil, err
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// runShardTasks runs sclite on each of the given shard tasks, using at most
+// cfg.Jobs concurrent processes. All running processes are stopped as soon as
+// one of them fails or the context is cancelled.
+func runShardTasks(
+	ctx context.Context, cfg ScliteCfg, scliteBin string, tasks []shardTask,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		queue = make(chan shardTask)
+		errs  = make(chan error, len(tasks))
+	)
+
+	for i := 0; i < cfg.Jobs; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for t := range queue {
+				if err := runShardTask(ctx, cfg, scliteBin, t); err != nil {
+					errs <- err
+					cancel()
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, t := range tasks {
+		select {
+		case queue <- t:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return err
+	}
+
+	return ctx.Err()
+}
+
+// runShardTask runs sclite on a single shard, producing only the sgml
+// alignments.
+func runShardTask(ctx context.Context, cfg ScliteCfg, scliteBin string, t shardTask) error {
+	args := []string{
+		"-i", "swb",
+		"-r", t.refFile, "trn",
+		"-O", t.dir,
+		"-l", fmt.Sprintf("%d", cfg.LineWidth),
+		"-e", cfg.Encoding,
+		"-o", "sgml",
+		"-s",
+	}
+
+	args = append(args, cfg.alignmentArgs()...)
+	args = append(args, "-h", t.hyp.FilePath, "trn", t.hyp.SystemName)
+
+	cmd := exec.CommandContext(ctx, scliteBin, args...)
+
+	if stderr, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"shard":  t.dir,
+			"stderr": string(stderr),
+		}).Error("sclite encountered errors")
+
+		return fmt.Errorf("failed to run sclite on shard %q: %w", t.dir, err)
+	}
+
+	return nil
+}
+
+// runSclitePiped pipes the given sgml file into sclite to generate the
+// configured reports from the alignments in it.
+func runSclitePiped(
+	ctx context.Context, cfg ScliteCfg, scliteBin, outDir, sgmlFile string,
+) error {
+	reports := make([]string, 0, len(cfg.reports()))
+	for _, r := range cfg.reports() {
+		// The sgml file itself has already been written.
+		if r != "sgml" {
+			reports = append(reports, r)
+		}
+	}
+
+	if len(reports) == 0 {
+		return nil
+	}
+
+	args := []string{
+		"-P",
+		"-O", outDir,
+		"-l", fmt.Sprintf("%d", cfg.LineWidth),
+		"-e", cfg.Encoding,
+		"-n", strings.TrimSuffix(path.Base(sgmlFile), ".sgml"),
+		"-o",
+	}
+
+	args = append(args, reports...)
+
+	// The wws report of weighted alignments needs the word weights again.
+	if cfg.WordWeights != "" {
+		args = append(args, "-w", cfg.WordWeights)
+	}
+
+	f, err := os.Open(sgmlFile)
+	if err != nil {
+		return fmt.Errorf("failed to open merged sgml file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	cmd := exec.CommandContext(ctx, scliteBin, args...)
+	cmd.Stdin = f
+
+	if stderr, err := cmd.CombinedOutput(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"stderr": string(stderr),
+		}).Error("sclite encountered errors")
+	}
+
+	return ctx.Err()
+}
+
+// mergeShardSgml concatenates the alignments in the sgml files of the given
+// shards into a single sgml file. Speakers are listed in the order they first
+// appear and sentence sequence numbers are offset to follow on from the
+// previous shards, starting at seqOffset, the same way sclite orders them in a
+// single run. The number of merged sentences is returned.
+func mergeShardSgml(
+	shards []shardTask, outFile, refFile, hypFile string, seqOffset int,
+) (int, error) {
+	var (
+		header   string
+		preamble []string
+		speakers []string
+		paths    = make(map[string][]string)
+		seqStart = seqOffset
+	)
+
+	seqAttr := regexp.MustCompile(`sequence="(\d+)"`)
+
+	for k, t := range shards {
+		data, err := os.ReadFile(t.sgmlFile())
+		if err != nil {
+			return 0, fmt.Errorf("failed to read shard sgml file: %w", err)
+		}
+
+		var (
+			currentSpk string
+			inPath     bool
+			numPaths   int
+			block      strings.Builder
+		)
+
+		for _, line := range strings.Split(string(data), "\n") {
+			switch {
+			case inPath:
+				block.WriteString(line + "\n")
+
+				if strings.HasPrefix(line, "</PATH>") {
+					paths[currentSpk] = append(paths[currentSpk], block.String())
+					inPath = false
+				}
+
+			case strings.HasPrefix(line, "<SYSTEM"):
+				if k == 0 {
+					header = sanitizeSystemTag(line)
+				}
+
+			case strings.HasPrefix(line, "<SPEAKER"):
+				currentSpk = line
+				if _, ok := paths[currentSpk]; !ok {
+					speakers = append(speakers, currentSpk)
+					paths[currentSpk] = nil
+				}
+
+			case strings.HasPrefix(line, "<PATH"):
+				var seq int
+				if m := seqAttr.FindStringSubmatch(line); m != nil {
+					seq, _ = strconv.Atoi(m[1])
+				}
+
+				line = seqAttr.ReplaceAllLiteralString(
+					line, fmt.Sprintf(`sequence="%d"`, seq+seqOffset),
+				)
+
+				block.Reset()
+				block.WriteString(line + "\n")
+				inPath = true
+				numPaths++
+
+			case strings.HasPrefix(line, "</SPEAKER>"), strings.HasPrefix(line, "</SYSTEM>"):
+				currentSpk = ""
+
+			case k == 0 && len(speakers) == 0 && line != "":
+				preamble = append(preamble, line)
+			}
+		}
+
+		seqOffset += numPaths
+	}
+
+	if header == "" {
+		return 0, fmt.Errorf("no <SYSTEM> header found in shard sgml files for %q", hypFile)
+	}
+
+	header = regexp.MustCompile(`ref_fname="[^"]*"`).
+		ReplaceAllLiteralString(header, fmt.Sprintf(`ref_fname="%s"`, refFile))
+	header = regexp.MustCompile(`hyp_fname="[^"]*"`).
+		ReplaceAllLiteralString(header, fmt.Sprintf(`hyp_fname="%s"`, hypFile))
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create merged sgml file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	w := bufio.NewWriter(f)
+
+	w.WriteString(header + "\n")
+	for _, l := range preamble {
+		w.WriteString(l + "\n")
+	}
+
+	for _, spk := range speakers {
+		w.WriteString(spk + "\n")
+		for _, p := range paths[spk] {
+			w.WriteString(p)
+		}
+		w.WriteString("</SPEAKER>\n")
+	}
+
+	w.WriteString("</SYSTEM>\n")
+
+	return seqOffset - seqStart, w.Flush()
+}
+
+// weightFileAttr matches the weight_filename attribute of the <SYSTEM> tag of
+// weighted alignments, which sclite writes with unescaped quotes inside, such
+// as weight_filename="w.wwl Column "w.wwl Col 1"".
+var weightFileAttr = regexp.MustCompile(`weight_filename="(.*)">$`)
+
+// sanitizeSystemTag removes the quotes sclite writes inside the weight_filename
+// attribute of the given <SYSTEM> tag, which it cannot parse when the sgml file
+// is piped back into it.
+func sanitizeSystemTag(line string) string {
+	m := weightFileAttr.FindStringSubmatchIndex(line)
+	if m == nil {
+		return line
+	}
+
+	return line[:m[2]] + strings.ReplaceAll(line[m[2]:m[3]], `"`, "") + line[m[3]:]
+}
+
+// A trnLine is a single line of a transcript file in the trn format expected by
+// SCTK tools - "<transcript> (<uttID>)".
+type trnLine struct {
+	id   string
+	text string
+}
+
+// readTrnLines reads all non-empty lines of the given trn file.
+func readTrnLines(filePath string) ([]trnLine, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	lines := make([]trnLine, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<24) //nolint: gomnd // long utterances are common in CER mode.
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		start := strings.LastIndex(line, "(")
+		if start < 0 || !strings.HasSuffix(line, ")") {
+			return nil, fmt.Errorf("missing utterance ID in trn line %q", line)
+		}
+
+		lines = append(lines, trnLine{
+			id:   line[start+1 : len(line)-1],
+			text: strings.TrimSpace(line[:start]),
+		})
+	}
+
+	return lines, scanner.Err()
+}
+
+// writeTrnLines writes the given lines to a trn file.
+func writeTrnLines(filePath string, lines []trnLine) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create trn file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	w := bufio.NewWriter(f)
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(w, "%s (%s)\n", l.text, l.id); err != nil {
+			return fmt.Errorf("failed to write line to trn file: %w", err)
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,98 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import "fmt"
+
+// SentenceStats are the counts of the aligned words of a sentence, or
+// characters when evaluating CER, following the definitions of sclite.
+type SentenceStats struct {
+	// RefWords is the number of reference words, i.e. correct, substituted and
+	// deleted words. HypWords is the number of hypothesis words, i.e. correct,
+	// substituted and inserted words.
+	RefWords int `json:"ref_words"`
+	HypWords int `json:"hyp_words"`
+
+	Cor int `json:"cor"`
+	Sub int `json:"sub"`
+	Del int `json:"del"`
+	Ins int `json:"ins"`
+
+	// WER is the number of substitutions, deletions and insertions as a
+	// percentage of reference words; the CER when evaluating CER. Like sclite,
+	// it is 0 for sentences without reference words.
+	WER float64 `json:"wer"`
+
+	// SentenceError is true if the sentence has any error.
+	SentenceError bool `json:"sentence_error"`
+}
+
+// Stats returns the counts of the aligned words of the sentence.
+func (s *AlignedSentence) Stats() SentenceStats {
+	var st SentenceStats
+
+	for _, w := range s.Words {
+		switch w.Label {
+		case "S":
+			st.Sub++
+		case "D":
+			st.Del++
+		case "I":
+			st.Ins++
+		default:
+			st.Cor++
+		}
+	}
+
+	st.RefWords = st.Cor + st.Sub + st.Del
+	st.HypWords = st.Cor + st.Sub + st.Ins
+	st.WER = ErrorRate(st.Errors(), st.RefWords)
+	st.SentenceError = st.Errors() > 0
+
+	return st
+}
+
+// Errors returns the number of substitutions, deletions and insertions.
+func (st SentenceStats) Errors() int {
+	return st.Sub + st.Del + st.Ins
+}
+
+// String returns the proportions of reference words that were correct,
+// substituted, deleted and inserted, and the error rate, as percentages.
+func (st SentenceStats) String() string {
+	return fmt.Sprintf(
+		"Cor=%3.1f%%\tSub=%3.1f%%\tDel=%3.1f%%\tIns=%3.1f%%\tErr=%3.1f%%\n",
+		pct(st.Cor, st.RefWords), pct(st.Sub, st.RefWords), pct(st.Del, st.RefWords),
+		pct(st.Ins, st.RefWords), st.WER,
+	)
+}
+
+// ErrorRate returns the given number of errors as a percentage of reference
+// words. Like sclite, it is 0 if there are no reference words, even if there
+// are insertions. Error rates of sentences and systems are computed with it
+// throughout, so that they agree between reports.
+func ErrorRate(errors, refWords int) float64 {
+	return pct(errors, refWords)
+}
+
+// pct returns num as a percentage of den, or 0 if den is 0, as sclite does.
+func pct(num, den int) float64 {
+	if den == 0 {
+		return 0
+	}
+
+	return 100 * float64(num) / float64(den) //nolint: gomnd // percentage.
+}
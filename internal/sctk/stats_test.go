@@ -0,0 +1,137 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestSentenceStats checks that the statistics of sentences, summed per
+// speaker, match the summary generated by sclite in the .sys report.
+func TestSentenceStats(t *testing.T) {
+	t.Parallel()
+
+	testCases := []string{
+		"testdata/sclite/wer/good1_hyp1.trn",
+		"testdata/sclite/wer/good1_hyp2.trn",
+		"testdata/sclite/cer/good1_hyp1.trn",
+		"testdata/sclite/cer/good1_hyp2.trn",
+		"testdata/sclite/stm/good2_hyp1.ctm",
+	}
+
+	for _, prefix := range testCases {
+		prefix := prefix
+
+		t.Run(prefix, func(subT *testing.T) {
+			subT.Parallel()
+
+			aligned, err := ReadAlignmentSgml(prefix + ".sgml")
+			if err != nil {
+				subT.Fatalf("unexpected error while reading sgml file, want=nil, got=%v", err)
+			}
+
+			want := readSysSummary(subT, prefix+".sys")
+
+			got := make(map[string]string)
+			var total speakerSummary
+
+			for spk, sents := range aligned.Speakers {
+				var sum speakerSummary
+				for _, s := range sents {
+					sum.add(s.Stats())
+					total.add(s.Stats())
+				}
+
+				got[spk] = sum.String()
+			}
+
+			got["Sum/Avg"] = total.String()
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				subT.Errorf("sentence stats do not match sclite summary, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// speakerSummary sums the statistics of sentences like a row of the summary
+// in the .sys report.
+type speakerSummary struct {
+	sents, sentErrs int
+	SentenceStats
+}
+
+func (s *speakerSummary) add(st SentenceStats) {
+	s.sents++
+	if st.SentenceError {
+		s.sentErrs++
+	}
+
+	s.RefWords += st.RefWords
+	s.Cor += st.Cor
+	s.Sub += st.Sub
+	s.Del += st.Del
+	s.Ins += st.Ins
+}
+
+// String formats the summary like the columns of the .sys report.
+func (s *speakerSummary) String() string {
+	return fmt.Sprintf(
+		"%d %d | %.1f %.1f %.1f %.1f %.1f %.1f",
+		s.sents, s.RefWords,
+		pct(s.Cor, s.RefWords), pct(s.Sub, s.RefWords), pct(s.Del, s.RefWords),
+		pct(s.Ins, s.RefWords), pct(s.Errors(), s.RefWords), pct(s.sentErrs, s.sents),
+	)
+}
+
+// readSysSummary reads the rows of each speaker and the total from the
+// summary in the given .sys report, normalized to the format of
+// speakerSummary.String.
+func readSysSummary(t *testing.T, sysPath string) map[string]string {
+	t.Helper()
+
+	data, err := os.ReadFile(sysPath)
+	if err != nil {
+		t.Fatalf("failed to read sys file: %v", err)
+	}
+
+	row := regexp.MustCompile(
+		`^\|\s*(\S+)\s*\|\s*(\d+)\s+(\d+)\s*\|\s*([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)\s+([\d.]+)\s*\|`,
+	)
+
+	rows := make(map[string]string)
+
+	for _, line := range strings.Split(string(data), "\n") {
+		m := row.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		rows[m[1]] = fmt.Sprintf("%s %s | %s", m[2], m[3], strings.Join(m[4:], " "))
+	}
+
+	if len(rows) == 0 {
+		t.Fatalf("no speaker summaries found in %q", sysPath)
+	}
+
+	return rows
+}
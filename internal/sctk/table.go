@@ -89,7 +89,7 @@ func (s SpeakerSentences) ToTable(f TableFormat) string {
 
 	for _, sent := range sortedSents {
 		w.WriteString(getSectionHeader(sent, f, 4))
-		w.WriteString(getBodyText(s[sent].Stats(), f))
+		w.WriteString(getBodyText(s[sent].Stats().String(), f))
 		w.WriteString(s[sent].ToTable(f))
 		w.WriteString(getSectionFooter(f))
 	}
@@ -97,31 +97,6 @@ func (s SpeakerSentences) ToTable(f TableFormat) string {
 	return w.String()
 }
 
-// Stats returns a string containing the proportion of words that the ASR system
-// got right, substituted, deleted and inserted.
-func (s *AlignedSentence) Stats() string {
-	var cor, sub, del, ins float32
-	for _, w := range s.Words {
-		switch {
-		case w.Label == "S":
-			sub++
-		case w.Label == "D":
-			del++
-		case w.Label == "I":
-			ins++
-		default:
-			cor++
-		}
-	}
-
-	total := float32(s.WordCount) + 1e-10
-
-	return fmt.Sprintf(
-		"Cor=%3.1f%%\tSub=%3.1f%%\tDel=%3.1f%%\tIns=%3.1f%%\n",
-		100*cor/total, 100*sub/total, 100*del/total, 100*ins/total,
-	)
-}
-
 // ToTable generates a table with three rows containing the reference and
 // hypothesis sentence, along with the alignment label for each token.
 func (s *AlignedSentence) ToTable(f TableFormat) string {
@@ -0,0 +1,227 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/fileutils"
+	"github.com/shahruk10/go-sctk/internal/textutils"
+)
+
+// UtteranceFile is the name of the files holding the per-utterance metrics of
+// all hypotheses in the output directory, without the extension.
+const UtteranceFile = "utterances"
+
+// utteranceColumns are the header of per-utterance metrics files.
+var utteranceColumns = []string{
+	"system", "id", "speaker",
+	"ref_words", "hyp_words", "ref_chars", "hyp_chars",
+	"cor", "sub", "del", "ins", "wer", "cer",
+	"ref", "hyp",
+	"file", "channel", "labels", "start", "end",
+}
+
+// An Utterance holds the metrics of one sentence of a hypothesis, flattened
+// for analysis in tools such as pandas or duckdb.
+type Utterance struct {
+	System  string
+	ID      string
+	Speaker string
+
+	// CharLevel is true if the sentence was aligned by character, in which case
+	// the alignment counts are of characters, and word counts and WER are not
+	// known.
+	CharLevel bool
+
+	RefWords, HypWords int
+	RefChars, HypChars int
+
+	// Cor, Sub, Del and Ins count the correct, substituted, deleted and
+	// inserted words, or characters if CharLevel is true.
+	Cor, Sub, Del, Ins int
+
+	// WER and CER are error rates in percent, as defined by SentenceStats. If
+	// the sentence was aligned by word, CER is the edit distance between the
+	// reference and hypothesis characters, ignoring spaces, as sclite does when
+	// evaluating CER.
+	WER, CER float64
+
+	// Ref and Hyp are the normalized reference and hypothesis text, as scored.
+	Ref, Hyp string
+
+	// File, Channel, Labels and Times are only available when scoring segment
+	// time marked (stm) references.
+	File    string
+	Channel string
+	Labels  string
+	Times   *TimeSpan
+}
+
+// Utterances returns the metrics of each sentence of the given hypothesis, in
+// the order they were scored. If cer is true, sentences are taken to be
+// aligned by character.
+func Utterances(a *AlignedHypothesis, cer bool) []Utterance {
+	utts := make([]Utterance, 0)
+	seqs := make([]int, 0)
+
+	for spk, sents := range a.Speakers {
+		for id, s := range sents {
+			u := Utterance{
+				System:    a.SystemName,
+				ID:        strings.TrimSuffix(strings.TrimPrefix(id, "("), ")"),
+				Speaker:   spk,
+				CharLevel: cer,
+				File:      s.File,
+				Channel:   s.Channel,
+				Labels:    s.Labels,
+				Times:     s.Times,
+			}
+
+			st := s.Stats()
+			u.Cor, u.Sub, u.Del, u.Ins = st.Cor, st.Sub, st.Del, st.Ins
+
+			refWords := make([]string, 0, len(s.Words))
+			hypWords := make([]string, 0, len(s.Words))
+
+			for _, w := range s.Words {
+				if w.Ref != "" {
+					refWords = append(refWords, w.Ref)
+				}
+
+				if w.Hyp != "" {
+					hypWords = append(hypWords, w.Hyp)
+				}
+			}
+
+			if cer {
+				u.Ref, u.Hyp = strings.Join(refWords, ""), strings.Join(hypWords, "")
+				u.RefChars, u.HypChars = st.RefWords, st.HypWords
+				u.CER = st.WER
+			} else {
+				u.Ref, u.Hyp = strings.Join(refWords, " "), strings.Join(hypWords, " ")
+				u.RefWords, u.HypWords = st.RefWords, st.HypWords
+				u.WER = st.WER
+
+				refChars := []rune(strings.Join(refWords, ""))
+				hypChars := []rune(strings.Join(hypWords, ""))
+				u.RefChars, u.HypChars = len(refChars), len(hypChars)
+				u.CER = pct(textutils.EditDistance(refChars, hypChars), len(refChars))
+			}
+
+			utts = append(utts, u)
+			seqs = append(seqs, s.Sequence)
+		}
+	}
+
+	sort.Sort(bySequence{utts, seqs})
+
+	return utts
+}
+
+// WriteUtterances writes the per-utterance metrics of the given hypotheses to
+// outFile, one row per utterance per hypothesis, as comma or tab separated
+// values with a header row.
+func WriteUtterances(outFile string, hyps []*AlignedHypothesis, cer bool, format string) error {
+	var delimiter rune
+
+	switch format {
+	case UtteranceFormatCSV:
+		delimiter = ','
+	case UtteranceFormatTSV:
+		delimiter = '\t'
+	default:
+		return fmt.Errorf("unsupported utterance format %q", format)
+	}
+
+	f, err := os.Create(outFile)
+	if err != nil {
+		return fmt.Errorf("failed to create utterances file: %w", err)
+	}
+
+	defer fileutils.CloseFileOrLog(f)
+
+	w := csv.NewWriter(f)
+	w.Comma = delimiter
+
+	if err := w.Write(utteranceColumns); err != nil {
+		return fmt.Errorf("failed to write utterances file: %w", err)
+	}
+
+	for _, a := range hyps {
+		for _, u := range Utterances(a, cer) {
+			if err := w.Write(u.record()); err != nil {
+				return fmt.Errorf("failed to write utterances file: %w", err)
+			}
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+// record returns the utterance as a row of utteranceColumns. Values that are
+// not known are left empty.
+func (u *Utterance) record() []string {
+	itoa := strconv.Itoa
+	ftoa := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) } //nolint: gomnd // precision.
+
+	refWords, hypWords, wer := itoa(u.RefWords), itoa(u.HypWords), ftoa(u.WER)
+	if u.CharLevel {
+		refWords, hypWords, wer = "", "", ""
+	}
+
+	start, end := "", ""
+	if u.Times != nil {
+		start = strconv.FormatFloat(u.Times.Start, 'f', -1, 64) //nolint: gomnd // bit size.
+		end = strconv.FormatFloat(u.Times.End, 'f', -1, 64)     //nolint: gomnd // bit size.
+	}
+
+	return []string{
+		u.System, u.ID, u.Speaker,
+		refWords, hypWords, itoa(u.RefChars), itoa(u.HypChars),
+		itoa(u.Cor), itoa(u.Sub), itoa(u.Del), itoa(u.Ins), wer, ftoa(u.CER),
+		u.Ref, u.Hyp,
+		u.File, u.Channel, u.Labels, start, end,
+	}
+}
+
+// bySequence sorts utterances by the sequence numbers of their sentences.
+type bySequence struct {
+	utts []Utterance
+	seqs []int
+}
+
+func (b bySequence) Len() int { return len(b.utts) }
+
+func (b bySequence) Less(i, j int) bool {
+	if b.seqs[i] != b.seqs[j] {
+		return b.seqs[i] < b.seqs[j]
+	}
+
+	return b.utts[i].ID < b.utts[j].ID
+}
+
+func (b bySequence) Swap(i, j int) {
+	b.utts[i], b.utts[j] = b.utts[j], b.utts[i]
+	b.seqs[i], b.seqs[j] = b.seqs[j], b.seqs[i]
+}
@@ -0,0 +1,103 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package sctk
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteUtterances(t *testing.T) {
+	t.Parallel()
+
+	aligned := &AlignedHypothesis{
+		SystemName: "hyp1",
+		Speakers: map[string]SpeakerSentences{
+			"spk1": {
+				"(spk1-u2)": &AlignedSentence{
+					SentenceID: "(spk1-u2)",
+					Sequence:   1,
+					Words: []AlignedWord{
+						{Label: "C", Ref: "the", Hyp: "the"},
+						{Label: "S", Ref: "cat", Hyp: "bat"},
+						{Label: "D", Ref: "sat"},
+						{Label: "I", Hyp: "down"},
+					},
+				},
+				"(spk1-u1)": &AlignedSentence{
+					SentenceID: "(spk1-u1)",
+					Sequence:   0,
+					File:       "rec1",
+					Channel:    "A",
+					Times:      &TimeSpan{Start: 0.5, End: 2},
+					Words: []AlignedWord{
+						{Label: "C", Ref: "hello", Hyp: "hello"},
+						{Label: "C", Ref: "world", Hyp: "world"},
+					},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		format string
+		cer    bool
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: UtteranceFormatCSV,
+			want: `system,id,speaker,ref_words,hyp_words,ref_chars,hyp_chars,cor,sub,del,ins,wer,cer,ref,hyp,file,channel,labels,start,end
+hyp1,spk1-u1,spk1,2,2,10,10,2,0,0,0,0.00,0.00,hello world,hello world,rec1,A,,0.5,2
+hyp1,spk1-u2,spk1,3,3,9,10,1,1,1,1,100.00,55.56,the cat sat,the bat down,,,,,
+`,
+		},
+		{
+			name:   "tsvCharLevel",
+			format: UtteranceFormatTSV,
+			cer:    true,
+			want: "system\tid\tspeaker\tref_words\thyp_words\tref_chars\thyp_chars\tcor\tsub\tdel\tins\twer\tcer\tref\thyp\tfile\tchannel\tlabels\tstart\tend\n" +
+				"hyp1\tspk1-u1\tspk1\t\t\t2\t2\t2\t0\t0\t0\t\t0.00\thelloworld\thelloworld\trec1\tA\t\t0.5\t2\n" +
+				"hyp1\tspk1-u2\tspk1\t\t\t3\t3\t1\t1\t1\t1\t\t100.00\tthecatsat\tthebatdown\t\t\t\t\t\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			outFile := path.Join(subT.TempDir(), "utterances."+tc.format)
+			if err := WriteUtterances(outFile, []*AlignedHypothesis{aligned}, tc.cer, tc.format); err != nil {
+				subT.Fatalf("got unexpected error, want=nil, got=%v", err)
+			}
+
+			got, err := os.ReadFile(outFile)
+			if err != nil {
+				subT.Fatalf("failed to read utterances file: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.want, string(got)); diff != "" {
+				subT.Errorf("unexpected utterances file, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
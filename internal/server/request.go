@@ -0,0 +1,310 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shahruk10/go-sctk/internal/score"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+)
+
+// Formats of reference and hypothesis payloads.
+const (
+	// PayloadCSV is delimited text, laid out as described by the CSV options of
+	// the request, passed as a JSON string.
+	PayloadCSV = "csv"
+	// PayloadJSONL is one JSON object per line, each with an "id" and "text",
+	// passed as a JSON string.
+	PayloadJSONL = "jsonl"
+	// PayloadJSON is a JSON array of objects, each with an "id" and "text".
+	PayloadJSON = "json"
+)
+
+// ScoreRequest is the body of scoring requests.
+type ScoreRequest struct {
+	Ref  Payload   `json:"ref"`
+	Hyps []Payload `json:"hyps"`
+
+	// CSV describes the layout of csv payloads. JSON and JSONL payloads are
+	// converted to the same layout before scoring.
+	CSV CSVOptions `json:"csv"`
+
+	NormalizeConfig score.NormalizeConfig `json:"normalize_config"`
+
+	// CER evaluates the character error rate instead of word error rate.
+	CER bool `json:"cer"`
+
+	// OmitAlignments leaves alignments out of the response, returning only the
+	// metrics of each system.
+	OmitAlignments bool `json:"omit_alignments"`
+}
+
+// A Payload is a reference or hypothesis transcript file sent in a request.
+type Payload struct {
+	// Name identifies the system that generated a hypothesis; set automatically
+	// if empty. It is lower cased and its spaces replaced with underscores, as
+	// when scoring files, and must be unique after that. It is ignored for the
+	// reference.
+	Name   string          `json:"name"`
+	Format string          `json:"format"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// CSVOptions describes the layout of csv payloads, as the delimiter and
+// column options of the score subcommand.
+type CSVOptions struct {
+	Delimiter      string `json:"delimiter"`
+	ColID          int    `json:"col_id"`
+	ColTrn         int    `json:"col_trn"`
+	IgnoreFirstRow bool   `json:"ignore_first_row"`
+}
+
+// utterance is an utterance in JSON and JSONL payloads.
+type utterance struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// decodeScoreRequest decodes and validates a scoring request. Options not set
+// in the request keep the defaults of the score subcommand.
+func decodeScoreRequest(body []byte) (*ScoreRequest, error) {
+	req := &ScoreRequest{CSV: CSVOptions{Delimiter: ",", ColID: 0, ColTrn: 1}}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+
+	if len(req.Hyps) == 0 {
+		return nil, fmt.Errorf("no hypotheses provided")
+	}
+
+	if _, err := req.fileFormat(); err != nil {
+		return nil, err
+	}
+
+	if err := req.NormalizeConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{})
+
+	// Names are sanitized as when scoring, so that names which only differ in
+	// case or spaces are caught as duplicates, and results are returned under
+	// the names of their alignments.
+	for i := range req.Hyps {
+		hyp := &req.Hyps[i]
+		if hyp.Name == "" {
+			hyp.Name = fmt.Sprintf("hyp%d", i+1)
+		}
+
+		if err := score.ValidateSystemName(hyp.Name); err != nil {
+			return nil, fmt.Errorf("invalid hypothesis name: %w", err)
+		}
+
+		hyp.Name = score.SanitizeSystemName(hyp.Name)
+
+		if _, ok := names[hyp.Name]; ok {
+			return nil, fmt.Errorf("hypothesis name %q used more than once", hyp.Name)
+		}
+
+		names[hyp.Name] = struct{}{}
+	}
+
+	return req, nil
+}
+
+// fileFormat returns the format of the files written for the payloads.
+func (req *ScoreRequest) fileFormat() (score.FileFormat, error) {
+	delimiter := []rune(req.CSV.Delimiter)
+	if len(delimiter) != 1 {
+		return score.FileFormat{}, fmt.Errorf("csv delimiter must be a single rune")
+	}
+
+	f := score.FileFormat{
+		Delimiter:      delimiter[0],
+		ColID:          req.CSV.ColID,
+		ColTrn:         req.CSV.ColTrn,
+		IgnoreFirstRow: req.CSV.IgnoreFirstRow,
+		RefFormat:      score.FormatDelimited,
+		HypFormat:      score.FormatDelimited,
+	}
+
+	if err := f.Validate(); err != nil {
+		return f, err
+	}
+
+	return f, nil
+}
+
+// score writes the payloads to files in the given directory, scores them, and
+// returns the metrics and alignments of each hypothesis.
+func (req *ScoreRequest) score(ctx context.Context, dir string) (ScoreResponse, error) {
+	fileFormat, err := req.fileFormat()
+	if err != nil {
+		return ScoreResponse{}, err
+	}
+
+	refFile := filepath.Join(dir, "ref.csv")
+	if err := req.Ref.write(refFile, fileFormat); err != nil {
+		return ScoreResponse{}, fmt.Errorf("invalid reference: %w", err)
+	}
+
+	hypFiles := make([]sctk.Hypothesis, 0, len(req.Hyps))
+	names := make([]string, 0, len(req.Hyps))
+
+	for i, hyp := range req.Hyps {
+		hypFile := filepath.Join(dir, fmt.Sprintf("hyp%d.csv", i+1))
+		if err := hyp.write(hypFile, fileFormat); err != nil {
+			return ScoreResponse{}, fmt.Errorf("invalid hypothesis %q: %w", hyp.Name, err)
+		}
+
+		hypFiles = append(hypFiles, sctk.Hypothesis{SystemName: hyp.Name, FilePath: hypFile})
+		names = append(names, hyp.Name)
+	}
+
+	scliteCfg := sctk.ScliteCfg{LineWidth: scliteLineWidth, Encoding: "utf-8", CER: req.CER}
+	outDir := filepath.Join(dir, "out")
+
+	if err := score.Score(ctx, fileFormat, req.NormalizeConfig, scliteCfg, outDir, refFile, hypFiles); err != nil {
+		return ScoreResponse{}, err
+	}
+
+	return newScoreResponse(outDir, names, !req.OmitAlignments)
+}
+
+// write writes the payload to the given path, in the layout described by the
+// given file format.
+func (p *Payload) write(filePath string, fileFormat score.FileFormat) error {
+	var data []byte
+
+	switch p.Format {
+	case PayloadCSV:
+		var s string
+		if err := json.Unmarshal(p.Data, &s); err != nil {
+			return fmt.Errorf("csv data must be a JSON string: %w", err)
+		}
+
+		data = []byte(s)
+	case PayloadJSONL, PayloadJSON:
+		utts, err := p.utterances()
+		if err != nil {
+			return err
+		}
+
+		if data, err = delimitedUtterances(utts, fileFormat); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf(
+			"unsupported payload format %q, supported %s|%s|%s", p.Format, PayloadCSV, PayloadJSONL, PayloadJSON,
+		)
+	}
+
+	if err := os.WriteFile(filePath, data, 0600); err != nil { //nolint: gomnd // file permissions.
+		return fmt.Errorf("failed to write payload: %w", err)
+	}
+
+	return nil
+}
+
+// utterances decodes the utterances of JSON and JSONL payloads.
+func (p *Payload) utterances() ([]utterance, error) {
+	var utts []utterance
+
+	if p.Format == PayloadJSON {
+		if err := json.Unmarshal(p.Data, &utts); err != nil {
+			return nil, fmt.Errorf("json data must be an array of objects with an id and text: %w", err)
+		}
+
+		return utts, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(p.Data, &s); err != nil {
+		return nil, fmt.Errorf("jsonl data must be a JSON string: %w", err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	scanner.Buffer(nil, len(s)+1)
+
+	for ldx := 1; scanner.Scan(); ldx++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var utt utterance
+		if err := json.Unmarshal([]byte(line), &utt); err != nil {
+			return nil, fmt.Errorf("failed to decode jsonl line %d: %w", ldx, err)
+		}
+
+		utts = append(utts, utt)
+	}
+
+	return utts, nil
+}
+
+// delimitedUtterances writes the given utterances as delimited text, with the
+// ID and transcript in the columns of the given file format.
+func delimitedUtterances(utts []utterance, fileFormat score.FileFormat) ([]byte, error) {
+	numCols := fileFormat.ColID + 1
+	if fileFormat.ColTrn >= numCols {
+		numCols = fileFormat.ColTrn + 1
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+	w.Comma = fileFormat.Delimiter
+
+	row := make([]string, numCols)
+
+	if fileFormat.IgnoreFirstRow {
+		row[fileFormat.ColID], row[fileFormat.ColTrn] = "id", "text"
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, utt := range utts {
+		if utt.ID == "" {
+			return nil, fmt.Errorf("utterance %d has no id", i+1)
+		}
+
+		// Line breaks would split the utterance over several lines.
+		row[fileFormat.ColID] = strings.Join(strings.Fields(utt.ID), " ")
+		row[fileFormat.ColTrn] = strings.Join(strings.Fields(utt.Text), " ")
+
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,341 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package server exposes scoring over an HTTP API, so that transcripts can be
+// scored from notebooks and services without installing the sctk binary.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/sctk"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+	"github.com/shahruk10/go-sctk/internal/version"
+)
+
+// Paths of the API endpoints.
+const (
+	PathHealth = "/healthz"
+	PathScore  = "/v1/score"
+)
+
+const (
+	// readHeaderTimeout is the maximum time allowed to read request headers.
+	readHeaderTimeout = 10 * time.Second
+	// shutdownTimeout is the maximum time to wait for requests in flight to
+	// complete when shutting down.
+	shutdownTimeout = 30 * time.Second
+	// scliteLineWidth is the line width passed to sclite. Alignments are
+	// returned as JSON, so it only affects reports that are not returned.
+	scliteLineWidth = 1000
+)
+
+// Config configures the HTTP server.
+type Config struct {
+	// Addr is the TCP address to listen on, e.g. ":8080".
+	Addr string
+
+	// MaxRequestBytes is the maximum size of request bodies. Larger requests are
+	// rejected with status 413.
+	MaxRequestBytes int64
+
+	// MaxConcurrent is the maximum number of requests scored at the same time.
+	// Requests beyond that are rejected with status 503.
+	MaxConcurrent int
+
+	// Timeout is the maximum time spent scoring a request; no limit if zero.
+	Timeout time.Duration
+}
+
+// Validate checks whether the configured options are valid.
+func (c *Config) Validate() error {
+	if c.MaxRequestBytes <= 0 {
+		return fmt.Errorf("maximum request size must be > 0")
+	}
+
+	if c.MaxConcurrent <= 0 {
+		return fmt.Errorf("maximum number of concurrent requests must be > 0")
+	}
+
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must be >= 0")
+	}
+
+	return nil
+}
+
+// Server serves the scoring API.
+type Server struct {
+	cfg Config
+	sem chan struct{}
+}
+
+// New creates a server with the given configuration.
+func New(cfg Config) *Server {
+	return &Server{cfg: cfg, sem: make(chan struct{}, cfg.MaxConcurrent)}
+}
+
+// Handler returns the handler serving all API endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathHealth, s.handleHealth)
+	mux.HandleFunc(PathScore, s.handleScore)
+
+	return mux
+}
+
+// ListenAndServe serves the API on the configured address until the given
+// context is cancelled, after which requests in flight are given some time to
+// complete.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.cfg.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		logrus.WithFields(logrus.Fields{"addr": s.cfg.Addr}).Info("serving scoring API")
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	logrus.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+
+	return nil
+}
+
+// healthResponse is the body of responses to health checks.
+type healthResponse struct {
+	Status   string `json:"status"`
+	Version  string `json:"version"`
+	InFlight int    `json:"in_flight"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleHealth reports whether the server is able to score requests, by
+// checking that sclite is available.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	resp := healthResponse{Status: "ok", Version: version.Version(), InFlight: len(s.sem)}
+	status := http.StatusOK
+
+	if _, err := embedded.Sclite(); err != nil {
+		resp.Status, resp.Error = "unavailable", err.Error()
+		status = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, status, resp)
+}
+
+// ScoreResponse is the body of responses to scoring requests.
+type ScoreResponse struct {
+	Systems []SystemResult `json:"systems"`
+}
+
+// A SystemResult contains the metrics and alignments of one hypothesis.
+type SystemResult struct {
+	Name      string                  `json:"name"`
+	Metrics   compare.Metrics         `json:"metrics"`
+	Alignment *sctk.AlignedHypothesis `json:"alignment,omitempty"`
+}
+
+// handleScore scores the reference and hypotheses in the request, in a
+// temporary directory removed afterwards. Scoring is cancelled if the client
+// disconnects.
+func (s *Server) handleScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	// Reading one byte more than allowed to tell whether the body is too large.
+	body, err := io.ReadAll(io.LimitReader(r.Body, s.cfg.MaxRequestBytes+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err))
+		return
+	}
+
+	if int64(len(body)) > s.cfg.MaxRequestBytes {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf(
+			"request body larger than %d bytes", s.cfg.MaxRequestBytes,
+		))
+
+		return
+	}
+
+	req, err := decodeScoreRequest(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Taking a slot only once the request is read and valid, so that slow or
+	// invalid uploads do not hold up requests being scored.
+	select {
+	case s.sem <- struct{}{}:
+		defer func() { <-s.sem }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("too many requests in flight, try again later"))
+
+		return
+	}
+
+	ctx := r.Context()
+
+	if s.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Timeout)
+
+		defer cancel()
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sctk-serve-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to create temporary directory: %w", err))
+		return
+	}
+
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			logrus.WithFields(logrus.Fields{"path": tmpDir, "error": err}).Warn("failed to remove temporary directory")
+		}
+	}()
+
+	start := time.Now()
+
+	resp, err := req.score(ctx, tmpDir)
+
+	switch {
+	case r.Context().Err() != nil:
+		logrus.WithFields(logrus.Fields{"remote": r.RemoteAddr}).Warn("client disconnected, scoring cancelled")
+		return
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("scoring took longer than %s", s.cfg.Timeout))
+		return
+	case err != nil && isInternal(err):
+		logrus.WithFields(logrus.Fields{"remote": r.RemoteAddr, "error": err}).Error("failed to score request")
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	case err != nil:
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"remote":   r.RemoteAddr,
+		"systems":  len(resp.Systems),
+		"duration": time.Since(start).Round(time.Millisecond),
+	}).Info("scored request")
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// isInternal returns true if the given scoring error was not caused by the
+// request, but by failing to run SCTK tools or to read and write files. The
+// files scored are all written by the server, so failing to access them is
+// never the fault of the request.
+func isInternal(err error) bool {
+	var (
+		exitErr *exec.ExitError
+		execErr *exec.Error
+		pathErr *fs.PathError
+	)
+
+	return errors.As(err, &exitErr) || errors.As(err, &execErr) || errors.As(err, &pathErr) ||
+		errors.Is(err, embedded.ErrNotEmbedded)
+}
+
+// newScoreResponse returns the metrics and alignments of all systems scored in
+// the given output directory, in the given order of system names.
+func newScoreResponse(outDir string, names []string, withAlignments bool) (ScoreResponse, error) {
+	systems, err := compare.LoadRun(outDir)
+	if err != nil {
+		return ScoreResponse{}, err
+	}
+
+	order := make(map[string]int, len(names))
+	for i, name := range names {
+		order[name] = i
+	}
+
+	resp := ScoreResponse{Systems: make([]SystemResult, 0, len(systems))}
+
+	for name, aligned := range systems {
+		result := SystemResult{Name: name, Metrics: compare.SystemMetrics(aligned, nil)}
+		if withAlignments {
+			result.Alignment = aligned
+		}
+
+		resp.Systems = append(resp.Systems, result)
+	}
+
+	sort.Slice(resp.Systems, func(i, j int) bool {
+		return order[resp.Systems[i].Name] < order[resp.Systems[j].Name]
+	})
+
+	return resp, nil
+}
+
+// errorResponse is the body of responses to failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.WithFields(logrus.Fields{"error": err}).Warn("failed to write response")
+	}
+}
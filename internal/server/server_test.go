@@ -0,0 +1,190 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/shahruk10/go-sctk/internal/compare"
+	"github.com/shahruk10/go-sctk/internal/sctk/embedded"
+)
+
+func TestHandleScore(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		body       string
+		busy       bool
+		wantStatus int
+		wantResp   ScoreResponse
+	}{
+		{
+			name: "Formats",
+			body: `{
+				"ref": {"format": "csv", "data": "id,text\nspk1-u1,hello world\nspk1-u2,the cat sat"},
+				"hyps": [
+					{"name": "a", "format": "json", "data": [
+						{"id": "spk1-u1", "text": "hello word"}, {"id": "spk1-u2", "text": "the cat sat"}
+					]},
+					{"format": "jsonl", "data": "{\"id\": \"spk1-u1\", \"text\": \"Hello, world\"}\n{\"id\": \"spk1-u2\", \"text\": \"cat sat\"}"}
+				],
+				"csv": {"ignore_first_row": true},
+				"normalize_config": {"case_sensitive": false},
+				"omit_alignments": true
+			}`,
+			wantStatus: http.StatusOK,
+			wantResp: ScoreResponse{Systems: []SystemResult{
+				{Name: "a", Metrics: compare.Metrics{NumSentences: 2, RefWords: 5, Sub: 1, Errors: 1, ErrorRate: 20}},
+				{Name: "hyp2", Metrics: compare.Metrics{NumSentences: 2, RefWords: 5, Sub: 1, Del: 1, Errors: 2, ErrorRate: 40}},
+			}},
+		},
+		{
+			name: "SanitizedNames",
+			body: `{
+				"ref": {"format": "csv", "data": "spk1-u1,hello world"},
+				"hyps": [{"name": "My System", "format": "csv", "data": "spk1-u1,hello world"}],
+				"omit_alignments": true
+			}`,
+			wantStatus: http.StatusOK,
+			wantResp: ScoreResponse{Systems: []SystemResult{
+				{Name: "my_system", Metrics: compare.Metrics{NumSentences: 1, RefWords: 2}},
+			}},
+		},
+		{
+			name: "DuplicateSanitizedNames",
+			body: `{
+				"ref": {"format": "csv", "data": "spk1-u1,hello"},
+				"hyps": [
+					{"name": "My System", "format": "csv", "data": "spk1-u1,hello"},
+					{"name": "my  system", "format": "csv", "data": "spk1-u1,hello"}
+				]
+			}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "PathInName",
+			body: `{
+				"ref": {"format": "csv", "data": "spk1-u1,hello"},
+				"hyps": [{"name": "../out", "format": "csv", "data": "spk1-u1,hello"}]
+			}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "ReservedName",
+			body: `{
+				"ref": {"format": "csv", "data": "spk1-u1,hello"},
+				"hyps": [{"name": "REF", "format": "csv", "data": "spk1-u1,hello"}]
+			}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "NoHypotheses",
+			body:       `{"ref": {"format": "csv", "data": "spk1-u1,hello"}}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "UnsupportedFormat",
+			body:       `{"ref": {"format": "xml", "data": ""}, "hyps": [{"format": "csv", "data": ""}]}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "TooLarge",
+			body:       `{"ref": {"format": "csv", "data": "` + strings.Repeat("a", 1024) + `"}}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "Busy",
+			body:       `{"ref": {"format": "csv", "data": "spk1-u1,hello"}, "hyps": [{"format": "csv", "data": "spk1-u1,hello"}]}`,
+			busy:       true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			// Invalid requests are rejected without waiting for a slot.
+			name:       "BusyInvalid",
+			body:       `{"ref": {"format": "csv", "data": "spk1-u1,hello"}}`,
+			busy:       true,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name: "NoCommonUtterances",
+			body: `{
+				"ref": {"format": "csv", "data": "spk1-u1,hello"},
+				"hyps": [{"format": "csv", "data": "spk1-u2,hello"}]
+			}`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			s := New(Config{MaxRequestBytes: 1024, MaxConcurrent: 1})
+			if tc.busy {
+				s.sem <- struct{}{}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, PathScore, strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				subT.Fatalf("unexpected status, want=%d, got=%d, body=%s", tc.wantStatus, rec.Code, rec.Body)
+			}
+
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+
+			var got ScoreResponse
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				subT.Fatalf("failed to decode response: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.wantResp, got); diff != "" {
+				subT.Errorf("unexpected response, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestHandleScoreInternalError checks that failures to run SCTK tools are not
+// blamed on the request. Not run in parallel since it changes the directory
+// SCTK tools are found in.
+func TestHandleScoreInternalError(t *testing.T) {
+	embedded.SetBinDir(t.TempDir())
+	defer embedded.SetBinDir("")
+
+	body := `{"ref": {"format": "csv", "data": "spk1-u1,hello"}, "hyps": [{"format": "csv", "data": "spk1-u1,hello"}]}`
+
+	s := New(Config{MaxRequestBytes: 1024, MaxConcurrent: 1})
+	req := httptest.NewRequest(http.MethodPost, PathScore, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("unexpected status, want=%d, got=%d, body=%s", http.StatusInternalServerError, rec.Code, rec.Body)
+	}
+}
@@ -19,6 +19,7 @@ package textutils
 
 import (
 	"encoding/csv"
+	"sort"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -43,3 +44,54 @@ func FieldsWithQuoted(s string, delimiter rune) []string {
 
 	return parts
 }
+
+// EditDistance returns the Levenshtein distance between the given sequences,
+// i.e. the minimum number of substitutions, deletions and insertions needed to
+// turn ref into hyp.
+func EditDistance[T comparable](ref, hyp []T) int {
+	prev := make([]int, len(hyp)+1)
+	curr := make([]int, len(hyp)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ref); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(hyp); j++ {
+			cost := 1
+			if ref[i-1] == hyp[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(hyp)]
+}
+
+// SortedKeys returns the keys of the given map in sorted order.
+func SortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func minInt(a int, others ...int) int {
+	for _, b := range others {
+		if b < a {
+			a = b
+		}
+	}
+
+	return a
+}
@@ -16,6 +16,7 @@
 package textutils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -50,3 +51,58 @@ func TestFieldsWithQuoted(t *testing.T) {
 		})
 	}
 }
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		ref  string
+		hyp  string
+		want int
+	}{
+		{name: "same", ref: "the cat sat", hyp: "the cat sat", want: 0},
+		{name: "substitution", ref: "the cat sat", hyp: "the bat sat", want: 1},
+		{name: "deletionAndInsertion", ref: "the cat sat", hyp: "cat sat down", want: 2},
+		{name: "emptyRef", ref: "", hyp: "hello world", want: 2},
+		{name: "emptyHyp", ref: "hello world", hyp: "", want: 2},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			got := EditDistance(strings.Fields(tc.ref), strings.Fields(tc.hyp))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				subT.Errorf("unexpected edit distance, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		m    map[string]int
+		want []string
+	}{
+		{name: "empty", m: map[string]int{}, want: []string{}},
+		{name: "unsorted", m: map[string]int{"b": 1, "c": 2, "a": 3}, want: []string{"a", "b", "c"}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(subT *testing.T) {
+			subT.Parallel()
+
+			if diff := cmp.Diff(tc.want, SortedKeys(tc.m)); diff != "" {
+				subT.Errorf("unexpected keys, (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (2022 -- present) Shahruk Hossain <shahruk10@gmail.com>
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//		 http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// ==============================================================================
+
+// Package version provides the version of the sctk tool.
+package version
+
+import "runtime/debug"
+
+// version is set at build time with:
+//
+//	-ldflags "-X github.com/shahruk10/go-sctk/internal/version.version=<version>"
+var version = ""
+
+// Version returns the version of the sctk tool. If it was not set at build
+// time, the module version recorded in the binary is returned, which is
+// "(devel)" for binaries built from a local checkout.
+func Version() string {
+	if version != "" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "unknown"
+}